@@ -0,0 +1,273 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package anomaly detects anomalies in metric time series, either against a seasonal baseline
+// built from the same time in previous seasons or with an EWMA control chart.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	defaultSeasonalThreshold = 3.5
+	defaultEWMAThreshold     = 3
+	defaultSeasonLength      = 7 * 24 * time.Hour
+	defaultSeasons           = 4
+	defaultSmoothing         = 0.3
+	defaultWarmupPoints      = 24
+	// minSeasonalValues is the number of previous seasons a point needs to be evaluated,
+	// fewer if less seasons are configured.
+	minSeasonalValues = 3
+)
+
+// Normalize fills the defaults of the options and returns an error if they are invalid.
+func Normalize(options sdktypes.AnomalyOptions) (sdktypes.AnomalyOptions, error) {
+	if options.Method == "" {
+		options.Method = sdktypes.AnomalyMethodSeasonalMAD
+	}
+	if options.Direction == "" {
+		options.Direction = sdktypes.AnomalyDirectionUp
+	}
+	if options.Threshold == 0 {
+		options.Threshold = defaultSeasonalThreshold
+		if options.Method == sdktypes.AnomalyMethodEWMA {
+			options.Threshold = defaultEWMAThreshold
+		}
+	}
+	if options.SeasonLength == 0 {
+		options.SeasonLength = defaultSeasonLength
+	}
+	if options.Seasons == 0 {
+		options.Seasons = defaultSeasons
+	}
+	if options.Smoothing == 0 {
+		options.Smoothing = defaultSmoothing
+	}
+	if options.WarmupPoints == 0 {
+		options.WarmupPoints = defaultWarmupPoints
+	}
+	switch {
+	case options.Method != sdktypes.AnomalyMethodSeasonalMAD && options.Method != sdktypes.AnomalyMethodEWMA:
+		return options, fmt.Errorf("unknown anomaly method %q", options.Method)
+	case options.Direction != sdktypes.AnomalyDirectionUp && options.Direction != sdktypes.AnomalyDirectionDown &&
+		options.Direction != sdktypes.AnomalyDirectionBoth:
+		return options, fmt.Errorf("unknown anomaly direction %q", options.Direction)
+	case options.Threshold < 0 || options.MinDeviation < 0:
+		return options, fmt.Errorf("threshold and min deviation must not be negative")
+	case options.SeasonLength < 0 || options.Seasons < 0 || options.WarmupPoints < 0:
+		return options, fmt.Errorf("season length, seasons and warmup points must not be negative")
+	case options.Smoothing < 0 || options.Smoothing > 1:
+		return options, fmt.Errorf("smoothing must be between 0 and 1, got %v", options.Smoothing)
+	}
+	return options, nil
+}
+
+// HistoryRequirement describes the history a point needs to be evaluated with normalized options.
+func HistoryRequirement(options sdktypes.AnomalyOptions) string {
+	if options.Method == sdktypes.AnomalyMethodEWMA {
+		return fmt.Sprintf("more than %d warmup points", options.WarmupPoints)
+	}
+	return fmt.Sprintf("values at the same time in %d previous seasons of %s", min(minSeasonalValues, options.Seasons), options.SeasonLength)
+}
+
+// pointScore is the evaluation of a single point of a series.
+type pointScore struct {
+	point     sdktypes.MetricPoint
+	expected  float64
+	score     float64
+	evaluated bool
+}
+
+// Detect finds the anomalies of a series with normalized options. Consecutive anomalous points
+// in the same direction are merged into one anomaly.
+func Detect(metric string, series []sdktypes.MetricPoint, options sdktypes.AnomalyOptions) *sdktypes.AnomalyDetectionReturn {
+	return detect(metric, series, nil, options)
+}
+
+// DetectRate finds the anomalies of a series of rates, e.g. the error rate, with normalized options.
+// sampleSizes holds the number of trials per bucket the rates are calculated from, e.g. the invocations.
+// The standard deviation of a rate is at least the Poisson noise of its expected count, and at least that
+// of a single event, so a baseline of zero errors does not turn a single error into an anomaly.
+func DetectRate(metric string, series, sampleSizes []sdktypes.MetricPoint, options sdktypes.AnomalyOptions) *sdktypes.AnomalyDetectionReturn {
+	sizes := make(map[int64]float64, len(sampleSizes))
+	for _, point := range sampleSizes {
+		sizes[point.Timestamp.UnixNano()] = point.Value
+	}
+	return detect(metric, series, sizes, options)
+}
+
+// detect scores the series with the method of the options and merges the anomalous points.
+// sampleSizes are keyed by the Unix nanoseconds of the buckets, nil if the series is no rate.
+func detect(metric string, series []sdktypes.MetricPoint, sampleSizes map[int64]float64, options sdktypes.AnomalyOptions) *sdktypes.AnomalyDetectionReturn {
+	sorted := append([]sdktypes.MetricPoint(nil), series...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	var scores []pointScore
+	if options.Method == sdktypes.AnomalyMethodEWMA {
+		scores = ewmaScores(sorted, sampleSizes, options)
+	} else {
+		scores = seasonalScores(sorted, sampleSizes, options)
+	}
+
+	result := &sdktypes.AnomalyDetectionReturn{
+		Metric:    metric,
+		Method:    options.Method,
+		Anomalies: []sdktypes.Anomaly{},
+	}
+	period := utils.BucketPeriod(sorted)
+	var current *sdktypes.Anomaly
+	for _, score := range scores {
+		if !score.evaluated {
+			result.SkippedPoints++
+			current = nil
+			continue
+		}
+		result.EvaluatedPoints++
+		direction, anomalous := classify(score, options)
+		if !anomalous {
+			current = nil
+			continue
+		}
+		if current == nil || current.Direction != direction {
+			result.Anomalies = append(result.Anomalies, sdktypes.Anomaly{
+				Metric:    metric,
+				Start:     score.point.Timestamp,
+				Direction: direction,
+			})
+			current = &result.Anomalies[len(result.Anomalies)-1]
+		}
+		current.End = score.point.Timestamp.Add(period)
+		current.Points++
+		if math.Abs(score.score) > current.Score {
+			current.Score = math.Abs(score.score)
+			current.Value = score.point.Value
+			current.Expected = score.expected
+		}
+	}
+	for i := range result.Anomalies {
+		result.Anomalies[i].Severity = severity(result.Anomalies[i].Score, options.Threshold)
+	}
+	return result
+}
+
+// seasonalScores scores every point by its robust z-score against the median and MAD of the values
+// at the same time in the previous seasons.
+func seasonalScores(series []sdktypes.MetricPoint, sampleSizes map[int64]float64, options sdktypes.AnomalyOptions) []pointScore {
+	values := make(map[int64]float64, len(series))
+	for _, point := range series {
+		values[point.Timestamp.UnixNano()] = point.Value
+	}
+	required := min(minSeasonalValues, options.Seasons)
+
+	scores := make([]pointScore, len(series))
+	for i, point := range series {
+		scores[i].point = point
+		history := []float64{}
+		for season := 1; season <= options.Seasons; season++ {
+			if value, ok := values[point.Timestamp.Add(-time.Duration(season)*options.SeasonLength).UnixNano()]; ok {
+				history = append(history, value)
+			}
+		}
+		if len(history) < required {
+			continue
+		}
+		median := utils.Median(history)
+		sigma := utils.NoiseFloor(utils.MADScale*utils.MedianAbsoluteDeviation(history), median)
+		if sampleSizes != nil {
+			sigma = math.Max(sigma, rateNoiseFloor(median, sampleSizes[point.Timestamp.UnixNano()]))
+		}
+		scores[i].expected = median
+		scores[i].score = (point.Value - median) / sigma
+		scores[i].evaluated = true
+	}
+	return scores
+}
+
+// ewmaScores scores every point after the warmup by the distance of the EWMA from the center line
+// of the warmup, in units of the standard deviation of the EWMA at that point.
+func ewmaScores(series []sdktypes.MetricPoint, sampleSizes map[int64]float64, options sdktypes.AnomalyOptions) []pointScore {
+	scores := make([]pointScore, len(series))
+	for i, point := range series {
+		scores[i].point = point
+	}
+	if len(series) <= options.WarmupPoints || options.WarmupPoints == 0 {
+		return scores
+	}
+	warmup := make([]float64, options.WarmupPoints)
+	for i := range warmup {
+		warmup[i] = series[i].Value
+	}
+	center := utils.Median(warmup)
+	sigma := utils.NoiseFloor(utils.MADScale*utils.MedianAbsoluteDeviation(warmup), center)
+	if sampleSizes != nil {
+		warmupSizes := make([]float64, options.WarmupPoints)
+		for i := range warmupSizes {
+			warmupSizes[i] = sampleSizes[series[i].Timestamp.UnixNano()]
+		}
+		sigma = math.Max(sigma, rateNoiseFloor(center, utils.Median(warmupSizes)))
+	}
+
+	lambda := options.Smoothing
+	ewma := center
+	for i := options.WarmupPoints; i < len(series); i++ {
+		step := float64(i - options.WarmupPoints + 1)
+		ewma = lambda*series[i].Value + (1-lambda)*ewma
+		ewmaSigma := sigma * math.Sqrt(lambda/(2-lambda)*(1-math.Pow(1-lambda, 2*step)))
+		scores[i].expected = center
+		scores[i].score = (ewma - center) / math.Max(ewmaSigma, utils.MinSigma)
+		scores[i].evaluated = true
+	}
+	return scores
+}
+
+// rateNoiseFloor returns the standard deviation of a rate with the expected value in n trials if the
+// events are Poisson distributed, counting at least one expected event. It is 0 without trials.
+func rateNoiseFloor(expected, n float64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	return math.Sqrt(math.Max(expected*n, 1)) / n
+}
+
+// classify returns the direction of a scored point and whether it is anomalous.
+func classify(score pointScore, options sdktypes.AnomalyOptions) (sdktypes.AnomalyDirection, bool) {
+	direction := sdktypes.AnomalyDirectionUp
+	if score.score < 0 {
+		direction = sdktypes.AnomalyDirectionDown
+	}
+	if options.Direction != sdktypes.AnomalyDirectionBoth && options.Direction != direction {
+		return direction, false
+	}
+	return direction, math.Abs(score.score) >= options.Threshold &&
+		math.Abs(score.point.Value-score.expected) >= options.MinDeviation
+}
+
+// severity ranks the score of an anomaly relative to the threshold.
+func severity(score, threshold float64) sdktypes.AnomalySeverity {
+	switch {
+	case score >= 2*threshold:
+		return sdktypes.AnomalySeverityHigh
+	case score >= 1.5*threshold:
+		return sdktypes.AnomalySeverityMedium
+	default:
+		return sdktypes.AnomalySeverityLow
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit holds the rules that check the configuration and observed metrics of a function
+// against best practices, and evaluates them into a report.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	// defaultDeprecationWarningDays is how many days before its deprecation a runtime is reported as soon deprecated.
+	defaultDeprecationWarningDays = 180
+	// defaultOverProvisionedUsageRate is the max memory usage relative to the memory size below which memory is over-provisioned.
+	defaultOverProvisionedUsageRate = 0.2
+	// minMemorySizeMB is the smallest memory size of Lambda, which cannot be over-provisioned.
+	minMemorySizeMB = 128
+	// maxTimeoutSeconds is the largest timeout Lambda allows.
+	maxTimeoutSeconds = 900
+)
+
+// runtimeDeprecations are the dates from which on AWS no longer applies security patches to a runtime,
+// as announced in the Lambda runtime deprecation policy.
+var runtimeDeprecations = map[string]time.Time{
+	"nodejs10.x":      date(2021, 7, 30),
+	"nodejs12.x":      date(2023, 3, 31),
+	"nodejs14.x":      date(2023, 12, 4),
+	"nodejs16.x":      date(2024, 6, 12),
+	"nodejs18.x":      date(2025, 9, 1),
+	"nodejs20.x":      date(2026, 4, 30),
+	"nodejs22.x":      date(2027, 4, 30),
+	"python2.7":       date(2021, 7, 15),
+	"python3.6":       date(2022, 7, 18),
+	"python3.7":       date(2023, 12, 4),
+	"python3.8":       date(2024, 10, 14),
+	"python3.9":       date(2025, 12, 15),
+	"python3.10":      date(2026, 6, 30),
+	"python3.11":      date(2026, 6, 30),
+	"python3.12":      date(2028, 10, 31),
+	"python3.13":      date(2029, 6, 30),
+	"java8":           date(2024, 1, 8),
+	"java8.al2":       date(2026, 6, 30),
+	"java11":          date(2026, 6, 30),
+	"java17":          date(2026, 6, 30),
+	"java21":          date(2029, 6, 30),
+	"dotnetcore2.1":   date(2022, 1, 5),
+	"dotnetcore3.1":   date(2023, 4, 3),
+	"dotnet6":         date(2024, 12, 20),
+	"dotnet8":         date(2026, 11, 10),
+	"ruby2.5":         date(2021, 7, 30),
+	"ruby2.7":         date(2023, 12, 7),
+	"ruby3.2":         date(2026, 3, 31),
+	"ruby3.3":         date(2027, 3, 31),
+	"ruby3.4":         date(2028, 3, 31),
+	"go1.x":           date(2024, 1, 8),
+	"provided":        date(2024, 1, 8),
+	"provided.al2":    date(2026, 6, 30),
+	"provided.al2023": date(2029, 6, 30),
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// RuntimeDeprecation returns the built-in deprecation date of a runtime, false if none is announced.
+func RuntimeDeprecation(runtime string) (time.Time, bool) {
+	deprecation, ok := runtimeDeprecations[runtime]
+	return deprecation, ok
+}
+
+// RuntimeDeprecations returns a copy of the built-in deprecation dates by runtime.
+func RuntimeDeprecations() map[string]time.Time {
+	deprecations := make(map[string]time.Time, len(runtimeDeprecations))
+	for runtime, deprecation := range runtimeDeprecations {
+		deprecations[runtime] = deprecation
+	}
+	return deprecations
+}
+
+// LoadSettings reads audit settings from a JSON file.
+func LoadSettings(path string) (sdktypes.AuditSettings, error) {
+	var settings sdktypes.AuditSettings
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return settings, fmt.Errorf("failed to read audit settings: %w", err)
+	}
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse audit settings: %w", err)
+	}
+	return settings, nil
+}
+
+// resolvedSettings are the data the default rules compare against, with the overrides applied.
+type resolvedSettings struct {
+	deprecations             map[string]time.Time
+	deprecationWarningDays   int
+	overProvisionedUsageRate float64
+}
+
+// resolveSettings applies the overrides of the settings to the built-in data.
+func resolveSettings(settings sdktypes.AuditSettings) (resolvedSettings, error) {
+	resolved := resolvedSettings{
+		deprecations:             RuntimeDeprecations(),
+		deprecationWarningDays:   defaultDeprecationWarningDays,
+		overProvisionedUsageRate: defaultOverProvisionedUsageRate,
+	}
+	for runtime, value := range settings.RuntimeDeprecations {
+		deprecation, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return resolved, fmt.Errorf("invalid deprecation date %q of runtime %s: %w", value, runtime, err)
+		}
+		resolved.deprecations[runtime] = deprecation
+	}
+	if settings.DeprecationWarningDays < 0 {
+		return resolved, fmt.Errorf("deprecation warning days must not be negative, got %d", settings.DeprecationWarningDays)
+	}
+	if settings.DeprecationWarningDays > 0 {
+		resolved.deprecationWarningDays = settings.DeprecationWarningDays
+	}
+	if settings.OverProvisionedUsageRate < 0 || settings.OverProvisionedUsageRate > 1 {
+		return resolved, fmt.Errorf("over-provisioned usage rate must be between 0 and 1, got %v", settings.OverProvisionedUsageRate)
+	}
+	if settings.OverProvisionedUsageRate > 0 {
+		resolved.overProvisionedUsageRate = settings.OverProvisionedUsageRate
+	}
+	return resolved, nil
+}
+
+// DefaultRules returns the rules that are evaluated if no rules are configured.
+func DefaultRules() []sdktypes.AuditRule {
+	rules, _ := Rules(sdktypes.AuditSettings{})
+	return rules
+}
+
+// Rules returns the default rules with the data of the settings, e.g. additional runtime deprecations.
+// An error is returned if the settings are invalid.
+func Rules(settings sdktypes.AuditSettings) ([]sdktypes.AuditRule, error) {
+	resolved, err := resolveSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	warningPeriod := time.Duration(resolved.deprecationWarningDays) * 24 * time.Hour
+	return []sdktypes.AuditRule{
+		{
+			ID:          "runtime-deprecated",
+			Description: "The runtime is deprecated and no longer receives security patches.",
+			Severity:    sdktypes.AuditSeverityHigh,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				runtime := subject.Configuration.Runtime
+				deprecation, ok := resolved.deprecations[runtime]
+				if !ok || subject.Now.Before(deprecation) {
+					return nil
+				}
+				return []string{fmt.Sprintf("runtime %s is deprecated since %s", runtime, deprecation.Format(time.DateOnly))}
+			},
+		},
+		{
+			ID:          "runtime-deprecation-soon",
+			Description: fmt.Sprintf("The runtime will be deprecated within %d days.", resolved.deprecationWarningDays),
+			Severity:    sdktypes.AuditSeverityMedium,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				runtime := subject.Configuration.Runtime
+				deprecation, ok := resolved.deprecations[runtime]
+				if !ok || !subject.Now.Before(deprecation) || deprecation.Sub(subject.Now) > warningPeriod {
+					return nil
+				}
+				return []string{fmt.Sprintf("runtime %s will be deprecated on %s", runtime, deprecation.Format(time.DateOnly))}
+			},
+		},
+		{
+			ID:          "memory-over-provisioned",
+			Description: fmt.Sprintf("The invocations use less than %.0f%% of the memory size.", resolved.overProvisionedUsageRate*100),
+			Severity:    sdktypes.AuditSeverityMedium,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				memorySize := subject.Configuration.MemorySizeMB
+				if subject.MemoryUsage == nil || memorySize == nil || *memorySize <= minMemorySizeMB ||
+					subject.MemoryUsage.MaxUsageRate >= resolved.overProvisionedUsageRate {
+					return nil
+				}
+				return []string{fmt.Sprintf(
+					"max memory used is %.0f%% of %d MB; memory also scales CPU, so check the duration when lowering it",
+					subject.MemoryUsage.MaxUsageRate*100, *memorySize)}
+			},
+		},
+		{
+			ID:          "timeout-at-maximum",
+			Description: "The timeout is the maximum of 900 s, so hung invocations are billed for 15 minutes.",
+			Severity:    sdktypes.AuditSeverityLow,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				timeout := subject.Configuration.TimeoutSeconds
+				if timeout == nil || *timeout < maxTimeoutSeconds {
+					return nil
+				}
+				return []string{fmt.Sprintf("timeout is %d s, the maximum Lambda allows", *timeout)}
+			},
+		},
+		{
+			ID:          "async-without-failure-destination",
+			Description: "The function is invoked asynchronously but has neither a dead-letter queue nor an on-failure destination.",
+			Severity:    sdktypes.AuditSeverityHigh,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				config := subject.Configuration
+				if subject.AsyncEventsReceived == 0 || config.DeadLetterTargetArn != "" ||
+					(config.AsyncInvokeConfig != nil && config.AsyncInvokeConfig.OnFailureDestination != "") {
+					return nil
+				}
+				return []string{fmt.Sprintf(
+					"%.0f asynchronous events were received, events that fail all retries are lost", subject.AsyncEventsReceived)}
+			},
+		},
+		{
+			ID:          "critical-without-reserved-concurrency",
+			Description: "The function is critical but has no reserved concurrency, so other functions can exhaust the account concurrency.",
+			Severity:    sdktypes.AuditSeverityMedium,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				if !subject.Critical || subject.Configuration.ReservedConcurrency != nil {
+					return nil
+				}
+				return []string{"critical function has no reserved concurrency"}
+			},
+		},
+		{
+			ID:          "tracing-disabled",
+			Description: "X-Ray active tracing is disabled.",
+			Severity:    sdktypes.AuditSeverityLow,
+			Check: func(subject sdktypes.AuditSubject) []string {
+				if subject.Configuration.TracingMode == "Active" {
+					return nil
+				}
+				return []string{"X-Ray active tracing is disabled"}
+			},
+		},
+	}, nil
+}
+
+// severityOrder ranks the severities from the most to the least severe.
+var severityOrder = map[sdktypes.AuditSeverity]int{
+	sdktypes.AuditSeverityHigh:   0,
+	sdktypes.AuditSeverityMedium: 1,
+	sdktypes.AuditSeverityLow:    2,
+}
+
+// Evaluate checks the subject against the rules and fills the findings, their counts and the passed rules of the report.
+func Evaluate(subject sdktypes.AuditSubject, rules []sdktypes.AuditRule, report *sdktypes.AuditReportReturn) {
+	report.Findings = []sdktypes.AuditFinding{}
+	report.FindingCounts = make(map[sdktypes.AuditSeverity]int)
+	report.PassedRules = []string{}
+	for _, rule := range rules {
+		messages := rule.Check(subject)
+		if len(messages) == 0 {
+			report.PassedRules = append(report.PassedRules, rule.ID)
+			continue
+		}
+		for _, message := range messages {
+			report.Findings = append(report.Findings, sdktypes.AuditFinding{
+				RuleID:   rule.ID,
+				Severity: rule.Severity,
+				Message:  message,
+			})
+			report.FindingCounts[rule.Severity]++
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return rank(report.Findings[i].Severity) < rank(report.Findings[j].Severity)
+	})
+	if len(report.Findings) > 0 {
+		report.HighestSeverity = report.Findings[0].Severity
+	}
+}
+
+// rank returns the position of a severity, custom severities rank after the known ones.
+func rank(severity sdktypes.AuditSeverity) int {
+	if order, ok := severityOrder[severity]; ok {
+		return order
+	}
+	return len(severityOrder)
+}
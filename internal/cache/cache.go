@@ -72,3 +72,32 @@ func (c *Cache) Get(key CacheKey) (int, bool) {
 	count, ok := c.store[key.String()]
 	return count, ok
 }
+
+// ResultCache stores computed results of time ranges that lie in the past and therefore no longer change,
+// e.g. the statistics of the previous period of a comparison, so they are computed once per process.
+// Thread safety is guaranteed via a mutex.
+type ResultCache[V any] struct {
+	mu    sync.RWMutex
+	store map[string]V
+}
+
+func NewResultCache[V any]() *ResultCache[V] {
+	return &ResultCache[V]{
+		store: make(map[string]V),
+	}
+}
+
+// Set stores the result for the given key.
+func (c *ResultCache[V]) Set(key CacheKey, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store[key.String()] = value
+}
+
+// Get returns the result for the key and a bool indicating if it was found.
+func (c *ResultCache[V]) Get(key CacheKey) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.store[key.String()]
+	return value, ok
+}
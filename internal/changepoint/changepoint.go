@@ -0,0 +1,218 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package changepoint finds the points in time at which the mean of a metric series shifted with PELT,
+// and annotates them with the nearest deployments.
+package changepoint
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	defaultPenalty          = 1
+	defaultMinSegmentPoints = 3
+	// defaultAttributionBuckets is the attribution window in buckets if none is configured.
+	defaultAttributionBuckets = 2
+)
+
+// Normalize fills the defaults of the options and returns an error if they are invalid.
+func Normalize(options sdktypes.ChangePointOptions) (sdktypes.ChangePointOptions, error) {
+	if options.Penalty == 0 {
+		options.Penalty = defaultPenalty
+	}
+	if options.MinSegmentPoints == 0 {
+		options.MinSegmentPoints = defaultMinSegmentPoints
+	}
+	if options.Penalty < 0 || options.MinSegmentPoints < 0 || options.MinRelativeChange < 0 || options.AttributionWindow < 0 {
+		return options, fmt.Errorf("change point options must not be negative")
+	}
+	return options, nil
+}
+
+// Detect finds the change points of a series with normalized options and annotates them with the nearest
+// of the deployments. Change points with a relative change below MinRelativeChange are merged into
+// their neighbouring segments, starting with the smallest change.
+func Detect(
+	metric string,
+	series []sdktypes.MetricPoint,
+	deployments []sdktypes.Deployment,
+	options sdktypes.ChangePointOptions,
+) *sdktypes.ChangePointDetectionReturn {
+	sorted := append([]sdktypes.MetricPoint(nil), series...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	values := make([]float64, len(sorted))
+	for i, point := range sorted {
+		values[i] = point.Value
+	}
+
+	result := &sdktypes.ChangePointDetectionReturn{
+		Metric:       metric,
+		ChangePoints: []sdktypes.ChangePoint{},
+	}
+	if len(values) < 2 {
+		return result
+	}
+	sigma := noiseStdDev(values)
+	result.NoiseStdDev = sigma
+
+	normalized := make([]float64, len(values))
+	for i, value := range values {
+		normalized[i] = value / sigma
+	}
+	penalty := options.Penalty * 2 * math.Log(float64(len(values)))
+	boundaries := pelt(normalized, penalty, options.MinSegmentPoints)
+	boundaries = mergeSmallChanges(values, boundaries, options.MinRelativeChange)
+
+	window := options.AttributionWindow
+	if window == 0 {
+		window = defaultAttributionBuckets * utils.BucketPeriod(sorted)
+	}
+	for i := 1; i < len(boundaries)-1; i++ {
+		before := utils.Mean(values[boundaries[i-1]:boundaries[i]])
+		after := utils.Mean(values[boundaries[i]:boundaries[i+1]])
+		changePoint := sdktypes.ChangePoint{
+			Metric:     metric,
+			Time:       sorted[boundaries[i]].Timestamp,
+			MeanBefore: before,
+			MeanAfter:  after,
+			Magnitude:  math.Abs(after-before) / sigma,
+			Direction:  sdktypes.AnomalyDirectionUp,
+		}
+		if after < before {
+			changePoint.Direction = sdktypes.AnomalyDirectionDown
+		}
+		if before != 0 {
+			relativeChange := (after - before) / math.Abs(before)
+			changePoint.RelativeChange = &relativeChange
+		}
+		annotate(&changePoint, deployments, window)
+		result.ChangePoints = append(result.ChangePoints, changePoint)
+	}
+	return result
+}
+
+// pelt returns the segment boundaries minimizing the squared deviations from the segment means plus the penalty
+// per change point, with the Pruned Exact Linear Time algorithm. The boundaries start with 0 and end with len(values).
+func pelt(values []float64, penalty float64, minSegmentPoints int) []int {
+	n := len(values)
+	minSegmentPoints = max(minSegmentPoints, 1)
+	sums := make([]float64, n+1)
+	squares := make([]float64, n+1)
+	for i, value := range values {
+		sums[i+1] = sums[i] + value
+		squares[i+1] = squares[i] + value*value
+	}
+	cost := func(from, to int) float64 {
+		sum := sums[to] - sums[from]
+		return squares[to] - squares[from] - sum*sum/float64(to-from)
+	}
+
+	best := make([]float64, n+1)
+	previous := make([]int, n+1)
+	best[0] = -penalty
+	candidates := []int{0}
+	for end := 1; end <= n; end++ {
+		best[end] = math.Inf(1)
+		for _, start := range candidates {
+			if end-start < minSegmentPoints {
+				continue
+			}
+			if total := best[start] + cost(start, end) + penalty; total < best[end] {
+				best[end] = total
+				previous[end] = start
+			}
+		}
+		if math.IsInf(best[end], 1) {
+			continue
+		}
+		// Starts that cannot be optimal for this end cannot be optimal for any later end either.
+		pruned := candidates[:0]
+		for _, start := range candidates {
+			if end-start < minSegmentPoints || best[start]+cost(start, end) <= best[end] {
+				pruned = append(pruned, start)
+			}
+		}
+		candidates = append(pruned, end)
+	}
+
+	boundaries := []int{n}
+	for end := n; end > 0; end = previous[end] {
+		boundaries = append(boundaries, previous[end])
+	}
+	for i, j := 0, len(boundaries)-1; i < j; i, j = i+1, j-1 {
+		boundaries[i], boundaries[j] = boundaries[j], boundaries[i]
+	}
+	return boundaries
+}
+
+// mergeSmallChanges removes the boundary with the smallest relative change of the mean until all changes
+// reach the minimum.
+func mergeSmallChanges(values []float64, boundaries []int, minRelativeChange float64) []int {
+	for minRelativeChange > 0 {
+		smallest, smallestChange := -1, minRelativeChange
+		for i := 1; i < len(boundaries)-1; i++ {
+			before := utils.Mean(values[boundaries[i-1]:boundaries[i]])
+			after := utils.Mean(values[boundaries[i]:boundaries[i+1]])
+			change := math.Inf(1)
+			if before != 0 {
+				change = math.Abs(after-before) / math.Abs(before)
+			}
+			if change < smallestChange {
+				smallest, smallestChange = i, change
+			}
+		}
+		if smallest < 0 {
+			break
+		}
+		boundaries = append(boundaries[:smallest], boundaries[smallest+1:]...)
+	}
+	return boundaries
+}
+
+// annotate sets the nearest deployment of a change point, and whether it is within the attribution window.
+func annotate(changePoint *sdktypes.ChangePoint, deployments []sdktypes.Deployment, window time.Duration) {
+	for i := range deployments {
+		distance := changePoint.Time.Sub(deployments[i].Time)
+		if changePoint.NearestDeployment == nil || absDuration(distance) < absDuration(changePoint.DeploymentDistance) {
+			changePoint.NearestDeployment = &deployments[i]
+			changePoint.DeploymentDistance = distance
+		}
+	}
+	changePoint.Attributed = changePoint.NearestDeployment != nil && absDuration(changePoint.DeploymentDistance) <= window
+}
+
+// noiseStdDev estimates the standard deviation of the noise from the differences of neighbouring points,
+// which are not affected by shifts of the mean except at the shifts themselves.
+func noiseStdDev(values []float64) float64 {
+	differences := make([]float64, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		differences[i-1] = values[i] - values[i-1]
+	}
+	sigma := utils.MADScale * utils.MedianAbsoluteDeviation(differences) / math.Sqrt2
+	return utils.NoiseFloor(sigma, utils.Median(values))
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -20,8 +20,10 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/dominikhei/serverless-statistics/internal/utils"
 	sdktypes "github.com/dominikhei/serverless-statistics/types"
@@ -43,5 +45,7 @@ func NewAWSClients(ctx context.Context, opts sdktypes.ConfigOptions) (*sdktypes.
 		LambdaClient:     lambda.NewFromConfig(cfg),
 		CloudWatchClient: cloudwatch.NewFromConfig(cfg),
 		LogsClient:       cloudwatchlogs.NewFromConfig(cfg),
+		CloudTrailClient: cloudtrail.NewFromConfig(cfg),
+		CodeDeployClient: codedeploy.NewFromConfig(cfg),
 	}, nil
 }
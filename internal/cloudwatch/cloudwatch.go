@@ -17,6 +17,7 @@ package cloudwatchfetcher
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
@@ -58,6 +59,22 @@ func (f *Fetcher) FetchMetric(
 	query sdktypes.FunctionQuery,
 	metricName string,
 	stat string,
+) ([]types.MetricDataResult, error) {
+	return f.FetchMetricSeries(ctx, query, metricName, stat, period)
+}
+
+// FetchMetricSeries fetches metric data for a given Lambda function within the specified
+// time range, aggregated over periods of the given length in seconds. Unlike FetchMetric
+// it is meant for time series, e.g. peak values per minute.
+//
+// The period must be a multiple of 60. Results are returned in the order of CloudWatch,
+// which is by descending timestamp.
+func (f *Fetcher) FetchMetricSeries(
+	ctx context.Context,
+	query sdktypes.FunctionQuery,
+	metricName string,
+	stat string,
+	periodSeconds int32,
 ) ([]types.MetricDataResult, error) {
 	dimensions := []types.Dimension{
 		{
@@ -80,6 +97,58 @@ func (f *Fetcher) FetchMetric(
 		Value: aws.String(resourceValue),
 	})
 
+	return f.fetch(ctx, query, "AWS/Lambda", metricName, stat, periodSeconds, dimensions)
+}
+
+// FetchAccountMetricSeries fetches account level metric data without function dimensions,
+// e.g. UnreservedConcurrentExecutions, within the time range of the query.
+func (f *Fetcher) FetchAccountMetricSeries(
+	ctx context.Context,
+	query sdktypes.FunctionQuery,
+	metricName string,
+	stat string,
+	periodSeconds int32,
+) ([]types.MetricDataResult, error) {
+	return f.fetch(ctx, query, "AWS/Lambda", metricName, stat, periodSeconds, nil)
+}
+
+// FetchDimensionMetricSeries fetches metric data of the given namespace and dimensions instead of
+// the function dimensions, e.g. metrics of an event source mapping or of the queue it reads from,
+// within the time range of the query.
+func (f *Fetcher) FetchDimensionMetricSeries(
+	ctx context.Context,
+	query sdktypes.FunctionQuery,
+	namespace string,
+	metricName string,
+	stat string,
+	periodSeconds int32,
+	dimensions map[string]string,
+) ([]types.MetricDataResult, error) {
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	dims := make([]types.Dimension, 0, len(names))
+	for _, name := range names {
+		dims = append(dims, types.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(dimensions[name]),
+		})
+	}
+	return f.fetch(ctx, query, namespace, metricName, stat, periodSeconds, dims)
+}
+
+// fetch runs a single GetMetricData query.
+func (f *Fetcher) fetch(
+	ctx context.Context,
+	query sdktypes.FunctionQuery,
+	namespace string,
+	metricName string,
+	stat string,
+	periodSeconds int32,
+	dimensions []types.Dimension,
+) ([]types.MetricDataResult, error) {
 	input := &cloudwatch.GetMetricDataInput{
 		StartTime: aws.Time(query.StartTime),
 		EndTime:   aws.Time(query.EndTime),
@@ -88,11 +157,11 @@ func (f *Fetcher) FetchMetric(
 				Id: aws.String("m1"),
 				MetricStat: &types.MetricStat{
 					Metric: &types.Metric{
-						Namespace:  aws.String("AWS/Lambda"),
+						Namespace:  aws.String(namespace),
 						MetricName: aws.String(metricName),
 						Dimensions: dimensions,
 					},
-					Period: aws.Int32(period),
+					Period: aws.Int32(periodSeconds),
 					Stat:   aws.String(stat),
 				},
 				ReturnData: aws.Bool(true),
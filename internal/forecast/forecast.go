@@ -0,0 +1,242 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package forecast forecasts metric time series with a linear trend or with additive Holt-Winters
+// exponential smoothing, together with prediction intervals.
+package forecast
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	defaultHorizon           = 30 * 24 * time.Hour
+	defaultConfidence        = 0.95
+	defaultSeasonLength      = 7 * 24 * time.Hour
+	defaultSmoothingLevel    = 0.3
+	defaultSmoothingTrend    = 0.05
+	defaultSmoothingSeasonal = 0.1
+)
+
+// Normalize fills the defaults of the options and returns an error if they are invalid.
+func Normalize(options sdktypes.ForecastOptions) (sdktypes.ForecastOptions, error) {
+	if options.Model == "" {
+		options.Model = sdktypes.ForecastModelLinear
+	}
+	if options.Horizon == 0 {
+		options.Horizon = defaultHorizon
+	}
+	if options.Confidence == 0 {
+		options.Confidence = defaultConfidence
+	}
+	if options.SeasonLength == 0 {
+		options.SeasonLength = defaultSeasonLength
+	}
+	if options.SmoothingLevel == 0 {
+		options.SmoothingLevel = defaultSmoothingLevel
+	}
+	if options.SmoothingTrend == 0 {
+		options.SmoothingTrend = defaultSmoothingTrend
+	}
+	if options.SmoothingSeasonal == 0 {
+		options.SmoothingSeasonal = defaultSmoothingSeasonal
+	}
+	switch {
+	case options.Model != sdktypes.ForecastModelLinear && options.Model != sdktypes.ForecastModelHoltWinters:
+		return options, fmt.Errorf("unknown forecast model %q", options.Model)
+	case options.Horizon < 0 || options.SeasonLength < 0:
+		return options, fmt.Errorf("horizon and season length must not be negative")
+	case options.Confidence < 0 || options.Confidence >= 1:
+		return options, fmt.Errorf("confidence must be between 0 and 1, got %v", options.Confidence)
+	case options.SmoothingLevel < 0 || options.SmoothingLevel > 1 ||
+		options.SmoothingTrend < 0 || options.SmoothingTrend > 1 ||
+		options.SmoothingSeasonal < 0 || options.SmoothingSeasonal > 1:
+		return options, fmt.Errorf("smoothing must be between 0 and 1")
+	}
+	return options, nil
+}
+
+// Forecast forecasts a series in buckets of equal width without gaps with normalized options, from the
+// bucket after the last point up to the horizon. Holt-Winters falls back to the linear model if the series
+// spans less than two seasons. If the series has no negative values, the forecast and its bounds are not either.
+func Forecast(metric string, series []sdktypes.MetricPoint, options sdktypes.ForecastOptions) (*sdktypes.SeriesForecast, error) {
+	sorted := append([]sdktypes.MetricPoint(nil), series...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	if len(sorted) < 2 {
+		return nil, errors.New("at least two points are required to forecast")
+	}
+	step := utils.BucketPeriod(sorted)
+	if step == 0 {
+		return nil, errors.New("points must have distinct timestamps")
+	}
+	values := make([]float64, len(sorted))
+	nonNegative := true
+	for i, point := range sorted {
+		values[i] = point.Value
+		nonNegative = nonNegative && point.Value >= 0
+	}
+	steps := int(math.Ceil(float64(options.Horizon) / float64(step)))
+
+	model := options.Model
+	seasonPoints := 0
+	if model == sdktypes.ForecastModelHoltWinters {
+		if options.SeasonLength%step != 0 || options.SeasonLength/step < 2 {
+			return nil, fmt.Errorf("season length must be a multiple of at least two buckets of %s, got %s", step, options.SeasonLength)
+		}
+		seasonPoints = int(options.SeasonLength / step)
+		if len(values) < 2*seasonPoints {
+			model = sdktypes.ForecastModelLinear
+		}
+	}
+
+	var fit *fitted
+	var err error
+	if model == sdktypes.ForecastModelHoltWinters {
+		fit = holtWinters(values, seasonPoints, steps, options)
+	} else {
+		fit, err = linear(values, steps)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &sdktypes.SeriesForecast{
+		Metric:         metric,
+		Model:          model,
+		Forecast:       make([]sdktypes.ForecastPoint, steps),
+		Trend:          fit.trend,
+		ResidualStdDev: fit.residualStdDev,
+	}
+	// Two-sided quantile of the standard normal distribution.
+	z := math.Sqrt2 * math.Erfinv(options.Confidence)
+	last := sorted[len(sorted)-1].Timestamp
+	for h := range steps {
+		point := sdktypes.ForecastPoint{
+			Timestamp: last.Add(time.Duration(h+1) * step),
+			Value:     fit.means[h],
+			Lower:     fit.means[h] - z*fit.stdDevs[h],
+			Upper:     fit.means[h] + z*fit.stdDevs[h],
+		}
+		if nonNegative {
+			point.Value = math.Max(point.Value, 0)
+			point.Lower = math.Max(point.Lower, 0)
+			point.Upper = math.Max(point.Upper, 0)
+		}
+		result.Forecast[h] = point
+	}
+	return result, nil
+}
+
+// fitted holds the forecast of a model: the expected values and the standard deviations of their errors
+// per step ahead, the trend per step at the end of the history and the standard deviation of the residuals.
+type fitted struct {
+	means          []float64
+	stdDevs        []float64
+	trend          float64
+	residualStdDev float64
+}
+
+// linear fits a least squares line through the values and extrapolates it. The prediction interval
+// accounts for the uncertainty of the line, so it widens with the distance from the history.
+func linear(values []float64, steps int) (*fitted, error) {
+	n := len(values)
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = float64(i)
+	}
+	slope, intercept, _, err := utils.LinearRegression(xs, values)
+	if err != nil {
+		return nil, err
+	}
+	meanX := float64(n-1) / 2
+	var sse, sxx float64
+	for i, value := range values {
+		residual := value - (intercept + slope*xs[i])
+		sse += residual * residual
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	var residualStdDev float64
+	if n > 2 {
+		residualStdDev = math.Sqrt(sse / float64(n-2))
+	}
+
+	fit := &fitted{
+		means:          make([]float64, steps),
+		stdDevs:        make([]float64, steps),
+		trend:          slope,
+		residualStdDev: residualStdDev,
+	}
+	for h := range steps {
+		x := float64(n + h)
+		fit.means[h] = intercept + slope*x
+		fit.stdDevs[h] = residualStdDev * math.Sqrt(1+1/float64(n)+(x-meanX)*(x-meanX)/sxx)
+	}
+	return fit, nil
+}
+
+// holtWinters runs additive Holt-Winters smoothing over values spanning at least two seasons of seasonPoints
+// and forecasts from the final level, trend and seasonality. The level and trend are initialized from the means
+// of the first two seasons and the seasonality from the detrended first season, which is why the residuals
+// are only taken from the second season on.
+func holtWinters(values []float64, seasonPoints, steps int, options sdktypes.ForecastOptions) *fitted {
+	alpha, beta, gamma := options.SmoothingLevel, options.SmoothingTrend, options.SmoothingSeasonal
+	m := seasonPoints
+	firstMean, secondMean := utils.Mean(values[:m]), utils.Mean(values[m:2*m])
+	trend := (secondMean - firstMean) / float64(m)
+	middle := float64(m-1) / 2
+	seasonal := make([]float64, m)
+	for i := range m {
+		seasonal[i] = values[i] - (firstMean + (float64(i)-middle)*trend)
+	}
+	level := firstMean + middle*trend
+
+	var sse float64
+	for t := m; t < len(values); t++ {
+		s := seasonal[t%m]
+		residual := values[t] - (level + trend + s)
+		sse += residual * residual
+		newLevel := alpha*(values[t]-s) + (1-alpha)*(level+trend)
+		trend = beta*(newLevel-level) + (1-beta)*trend
+		seasonal[t%m] = gamma*(values[t]-newLevel) + (1-gamma)*s
+		level = newLevel
+	}
+	residualStdDev := math.Sqrt(sse / float64(len(values)-m))
+
+	fit := &fitted{
+		means:          make([]float64, steps),
+		stdDevs:        make([]float64, steps),
+		trend:          trend,
+		residualStdDev: residualStdDev,
+	}
+	// The variance of the error h steps ahead grows with the weights errors of the previous steps carry into
+	// the level, trend and seasonality: 1 + Σ (α(1+jβ) + γ·[j is a multiple of m])² for j < h.
+	variance := 1.0
+	for h := 1; h <= steps; h++ {
+		fit.means[h-1] = level + float64(h)*trend + seasonal[(len(values)-1+h)%m]
+		fit.stdDevs[h-1] = residualStdDev * math.Sqrt(variance)
+		weight := alpha * (1 + float64(h)*beta)
+		if h%m == 0 {
+			weight += gamma
+		}
+		variance += weight * weight
+	}
+	return fit
+}
@@ -17,7 +17,9 @@ package fetcherinterfaces
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/dominikhei/serverless-statistics/internal/cache"
 	sdktypes "github.com/dominikhei/serverless-statistics/types"
@@ -31,6 +33,9 @@ type LogsInsightsFetcher interface {
 // This interface matches cloudwatchfetcher.Fetcher for tetsing the internal functions
 type CloudWatchFetcher interface {
 	FetchMetric(ctx context.Context, query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error)
+	FetchMetricSeries(ctx context.Context, query sdktypes.FunctionQuery, metricName string, stat string, periodSeconds int32) ([]types.MetricDataResult, error)
+	FetchAccountMetricSeries(ctx context.Context, query sdktypes.FunctionQuery, metricName string, stat string, periodSeconds int32) ([]types.MetricDataResult, error)
+	FetchDimensionMetricSeries(ctx context.Context, query sdktypes.FunctionQuery, namespace string, metricName string, stat string, periodSeconds int32, dimensions map[string]string) ([]types.MetricDataResult, error)
 }
 
 // This interface matches lambda.Client for tetsing the internal functions
@@ -38,8 +43,66 @@ type LambdaClient interface {
 	GetFunction(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
 }
 
+// ProvisionedConcurrencyClient is the part of lambda.Client that reads provisioned concurrency configs.
+type ProvisionedConcurrencyClient interface {
+	GetProvisionedConcurrencyConfig(ctx context.Context, params *lambda.GetProvisionedConcurrencyConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetProvisionedConcurrencyConfigOutput, error)
+}
+
+// ConcurrencyClient is the part of lambda.Client that reads the concurrency limits of a function and the account.
+type ConcurrencyClient interface {
+	GetFunctionConcurrency(ctx context.Context, params *lambda.GetFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionConcurrencyOutput, error)
+	GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput, optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error)
+}
+
+// EventInvokeConfigClient is the part of lambda.Client that reads the asynchronous invocation config of a function.
+type EventInvokeConfigClient interface {
+	GetFunctionEventInvokeConfig(ctx context.Context, params *lambda.GetFunctionEventInvokeConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionEventInvokeConfigOutput, error)
+}
+
+// EventSourceMappingClient is the part of lambda.Client that lists the event source mappings of a function.
+type EventSourceMappingClient interface {
+	ListEventSourceMappings(ctx context.Context, params *lambda.ListEventSourceMappingsInput, optFns ...func(*lambda.Options)) (*lambda.ListEventSourceMappingsOutput, error)
+}
+
+// FunctionVersionsClient is the part of lambda.Client that lists the published versions of a function.
+type FunctionVersionsClient interface {
+	ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error)
+}
+
+// FunctionListClient is the part of lambda.Client that lists the functions of an account.
+type FunctionListClient interface {
+	ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
+}
+
+// FunctionURLClient is the part of lambda.Client that reads the function URL config of a function.
+type FunctionURLClient interface {
+	GetFunctionUrlConfig(ctx context.Context, params *lambda.GetFunctionUrlConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionUrlConfigOutput, error)
+}
+
+// CloudTrailClient is the part of cloudtrail.Client that looks up the management events of a function.
+type CloudTrailClient interface {
+	LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error)
+}
+
+// AliasClient is the part of lambda.Client that reads the versions an alias routes traffic to.
+type AliasClient interface {
+	GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error)
+}
+
+// CodeDeployClient is the part of codedeploy.Client that reads deployments and reports hook statuses.
+type CodeDeployClient interface {
+	GetDeployment(ctx context.Context, params *codedeploy.GetDeploymentInput, optFns ...func(*codedeploy.Options)) (*codedeploy.GetDeploymentOutput, error)
+	PutLifecycleEventHookExecutionStatus(ctx context.Context, params *codedeploy.PutLifecycleEventHookExecutionStatusInput, optFns ...func(*codedeploy.Options)) (*codedeploy.PutLifecycleEventHookExecutionStatusOutput, error)
+}
+
 type Cache interface {
 	Has(key cache.CacheKey) bool
 	Set(key cache.CacheKey, value int)
 	Get(key cache.CacheKey) (int, bool)
 }
+
+// PeriodCache matches cache.ResultCache, which holds the metric values of past periods.
+type PeriodCache interface {
+	Set(key cache.CacheKey, value map[sdktypes.ComparisonMetric]sdktypes.PeriodValue)
+	Get(key cache.CacheKey) (map[sdktypes.ComparisonMetric]sdktypes.PeriodValue, bool)
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// platformEvent is a platform record of a function that uses the JSON log format.
+type platformEvent struct {
+	Time   string `json:"time"`
+	Type   string `json:"type"`
+	Record struct {
+		RequestID          string `json:"requestId"`
+		Status             string `json:"status"`
+		ErrorType          string `json:"errorType"`
+		InitializationType string `json:"initializationType"`
+		Metrics            struct {
+			DurationMs              float64  `json:"durationMs"`
+			BilledDurationMs        float64  `json:"billedDurationMs"`
+			MemorySizeMB            float64  `json:"memorySizeMB"`
+			MaxMemoryUsedMB         float64  `json:"maxMemoryUsedMB"`
+			InitDurationMs          *float64 `json:"initDurationMs"`
+			RestoreDurationMs       *float64 `json:"restoreDurationMs"`
+			BilledRestoreDurationMs *float64 `json:"billedRestoreDurationMs"`
+		} `json:"metrics"`
+	} `json:"record"`
+}
+
+// isJSON returns true if the message was written in the JSON log format.
+func isJSON(message string) bool {
+	return strings.HasPrefix(strings.TrimSpace(message), "{")
+}
+
+// parsePlatformEvent decodes a JSON formatted platform record.
+// The timestamp of the record is used, if the row has no @timestamp.
+func parsePlatformEvent(message string) (platformEvent, time.Time, error) {
+	var event platformEvent
+	if err := json.Unmarshal([]byte(message), &event); err != nil {
+		return platformEvent{}, time.Time{}, fmt.Errorf("decode JSON log: %w", err)
+	}
+	var ts time.Time
+	if event.Time != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, event.Time)
+		if err != nil {
+			return platformEvent{}, time.Time{}, fmt.Errorf("parse time %q: %w", event.Time, err)
+		}
+		ts = parsed.UTC()
+	}
+	return event, ts, nil
+}
+
+// parseJSONReport fills report from a JSON formatted platform.report record.
+func parseJSONReport(message string, report *InvocationReport) error {
+	event, ts, err := parsePlatformEvent(message)
+	if err != nil {
+		return err
+	}
+	if event.Type != "platform.report" {
+		return fmt.Errorf("unexpected record type %q", event.Type)
+	}
+	if report.RequestID == "" {
+		report.RequestID = event.Record.RequestID
+	}
+	if report.Timestamp.IsZero() {
+		report.Timestamp = ts
+	}
+	metrics := event.Record.Metrics
+	report.DurationMs = metrics.DurationMs
+	report.BilledDurationMs = metrics.BilledDurationMs
+	report.MemorySizeMB = metrics.MemorySizeMB
+	report.MaxMemoryUsedMB = metrics.MaxMemoryUsedMB
+	report.InitDurationMs = metrics.InitDurationMs
+	report.RestoreDurationMs = metrics.RestoreDurationMs
+	report.BilledRestoreDurationMs = metrics.BilledRestoreDurationMs
+	if event.Record.Status != "success" {
+		report.Status = event.Record.Status
+	}
+	report.ErrorType = event.Record.ErrorType
+	return nil
+}
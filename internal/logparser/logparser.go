@@ -0,0 +1,252 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logparser parses the platform records Lambda writes to CloudWatch Logs
+// into typed values, such that metrics can be calculated per invocation instead of
+// aggregating them inside of Logs Insights.
+package logparser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayout is the format of the @timestamp field returned by Logs Insights.
+const timestampLayout = "2006-01-02 15:04:05.000"
+
+var (
+	requestIDPattern             = regexp.MustCompile(`RequestId: ([A-Za-z0-9-]+)`)
+	durationPattern              = regexp.MustCompile(`RequestId: \S+\s+Duration: ([\d.]+) ms`)
+	billedDurationPattern        = regexp.MustCompile(`Billed Duration: ([\d.]+) ms`)
+	memorySizePattern            = regexp.MustCompile(`Memory Size: ([\d.]+) MB`)
+	maxMemoryUsedPattern         = regexp.MustCompile(`Max Memory Used: ([\d.]+) MB`)
+	initDurationPattern          = regexp.MustCompile(`Init Duration: ([\d.]+) ms`)
+	restoreDurationPattern       = regexp.MustCompile(`(?:^|[^d]\s)Restore Duration: ([\d.]+) ms`)
+	billedRestoreDurationPattern = regexp.MustCompile(`Billed Restore Duration: ([\d.]+) ms`)
+	statusPattern                = regexp.MustCompile(`Status: (\w+)`)
+	errorTypePattern             = regexp.MustCompile(`Error Type: ([\w.]+)`)
+)
+
+// InvocationReport holds the values of the REPORT line Lambda writes at the end of every invocation.
+// Optional values are nil if they are not part of the line, e.g. InitDurationMs for warm starts.
+type InvocationReport struct {
+	RequestID               string
+	LogStream               string    // Every log stream corresponds to one execution environment
+	Timestamp               time.Time // Time the REPORT line was written, i.e. the end of the invocation
+	DurationMs              float64
+	BilledDurationMs        float64
+	MemorySizeMB            float64
+	MaxMemoryUsedMB         float64
+	InitDurationMs          *float64
+	RestoreDurationMs       *float64 // Only reported for SnapStart functions
+	BilledRestoreDurationMs *float64 // Only reported for SnapStart functions
+	Status                  string   // Empty for successful invocations, e.g. "timeout" or "error" otherwise
+	ErrorType               string   // e.g. "Runtime.ExitError", only set if Status is "error"
+}
+
+// IsColdStart returns true if the invocation had to initialize or restore its execution environment.
+func (r InvocationReport) IsColdStart() bool {
+	return r.InitDurationMs != nil || r.RestoreDurationMs != nil
+}
+
+// ColdStartDurationMs returns the init or restore duration of a cold start and 0 for warm starts.
+func (r InvocationReport) ColdStartDurationMs() float64 {
+	if r.InitDurationMs != nil {
+		return *r.InitDurationMs
+	}
+	if r.RestoreDurationMs != nil {
+		return *r.RestoreDurationMs
+	}
+	return 0
+}
+
+// StartTime returns the approximate start of the handler execution.
+func (r InvocationReport) StartTime() time.Time {
+	return r.Timestamp.Add(-time.Duration(r.DurationMs * float64(time.Millisecond)))
+}
+
+// Failed returns true if the platform reported the invocation as timed out or failed.
+func (r InvocationReport) Failed() bool {
+	return r.Status != "" && r.Status != "success"
+}
+
+// ParseTimestamp parses the @timestamp field of a Logs Insights result row.
+func ParseTimestamp(value string) (time.Time, error) {
+	return time.ParseInLocation(timestampLayout, value, time.UTC)
+}
+
+// ParseReport parses a Logs Insights result row containing a REPORT line in text format
+// or a platform.report record in JSON log format.
+// The row is expected to hold the fields @timestamp, @logStream and @message.
+func ParseReport(row map[string]string) (InvocationReport, error) {
+	message, ok := row["@message"]
+	if !ok {
+		return InvocationReport{}, errors.New("row has no @message field")
+	}
+	report := InvocationReport{
+		LogStream: row["@logStream"],
+		RequestID: row["@requestId"],
+	}
+	if report.RequestID == "" {
+		if match := requestIDPattern.FindStringSubmatch(message); match != nil {
+			report.RequestID = match[1]
+		}
+	}
+	if tsStr, ok := row["@timestamp"]; ok {
+		ts, err := ParseTimestamp(tsStr)
+		if err != nil {
+			return InvocationReport{}, fmt.Errorf("parse timestamp %q: %w", tsStr, err)
+		}
+		report.Timestamp = ts
+	}
+	if isJSON(message) {
+		if err := parseJSONReport(message, &report); err != nil {
+			return InvocationReport{}, err
+		}
+		return report, nil
+	}
+
+	duration := findFloat(durationPattern, message)
+	if duration == nil {
+		return InvocationReport{}, fmt.Errorf("no duration in message %q", message)
+	}
+	report.DurationMs = *duration
+	if val := findFloat(billedDurationPattern, message); val != nil {
+		report.BilledDurationMs = *val
+	}
+	if val := findFloat(memorySizePattern, message); val != nil {
+		report.MemorySizeMB = *val
+	}
+	if val := findFloat(maxMemoryUsedPattern, message); val != nil {
+		report.MaxMemoryUsedMB = *val
+	}
+	report.InitDurationMs = findFloat(initDurationPattern, message)
+	report.RestoreDurationMs = findFloat(restoreDurationPattern, message)
+	report.BilledRestoreDurationMs = findFloat(billedRestoreDurationPattern, message)
+	if match := statusPattern.FindStringSubmatch(message); match != nil {
+		report.Status = match[1]
+	}
+	if match := errorTypePattern.FindStringSubmatch(message); match != nil {
+		report.ErrorType = match[1]
+	}
+	return report, nil
+}
+
+// InitEvent is a platform record written when an execution environment is initialized or restored.
+type InitEvent struct {
+	LogStream string
+	Timestamp time.Time
+	// InitType is "on-demand", "provisioned-concurrency" or "snap-start".
+	// It is empty if the record does not tell, e.g. INIT_START lines in text format.
+	InitType   string
+	DurationMs *float64 // Only set for INIT_REPORT and RESTORE_REPORT records
+}
+
+// ParseInitEvent parses a Logs Insights result row containing an init or restore record.
+// ok is false if the row holds no such record, e.g. for REPORT lines.
+func ParseInitEvent(row map[string]string) (event InitEvent, ok bool, err error) {
+	message := row["@message"]
+	event.LogStream = row["@logStream"]
+	if tsStr, found := row["@timestamp"]; found {
+		ts, err := ParseTimestamp(tsStr)
+		if err != nil {
+			return InitEvent{}, false, fmt.Errorf("parse timestamp %q: %w", tsStr, err)
+		}
+		event.Timestamp = ts
+	}
+
+	if isJSON(message) {
+		record, ts, err := parsePlatformEvent(message)
+		if err != nil {
+			return InitEvent{}, false, err
+		}
+		if event.Timestamp.IsZero() {
+			event.Timestamp = ts
+		}
+		event.InitType = record.Record.InitializationType
+		switch record.Type {
+		case "platform.initStart":
+		case "platform.initReport":
+			event.DurationMs = &record.Record.Metrics.DurationMs
+		case "platform.restoreStart":
+			event.InitType = "snap-start"
+		case "platform.restoreReport":
+			event.InitType = "snap-start"
+			event.DurationMs = &record.Record.Metrics.DurationMs
+		default:
+			return InitEvent{}, false, nil
+		}
+		return event, true, nil
+	}
+
+	switch {
+	case strings.HasPrefix(message, "INIT_START"):
+	case strings.HasPrefix(message, "INIT_REPORT"):
+		event.DurationMs = findFloat(initDurationPattern, message)
+	case strings.HasPrefix(message, "RESTORE_START"):
+		event.InitType = "snap-start"
+	case strings.HasPrefix(message, "RESTORE_REPORT"):
+		event.InitType = "snap-start"
+		event.DurationMs = findFloat(restoreDurationPattern, message)
+	default:
+		return InitEvent{}, false, nil
+	}
+	return event, true, nil
+}
+
+// ParseReports parses all rows into reports sorted by their timestamp.
+// Rows that can not be parsed are skipped with a warning, like in the metrics package.
+func ParseReports(rows []map[string]string) []InvocationReport {
+	reports := make([]InvocationReport, 0, len(rows))
+	for _, row := range rows {
+		report, err := ParseReport(row)
+		if err != nil {
+			fmt.Printf("warn: could not parse REPORT line: %v\n", err)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Timestamp.Before(reports[j].Timestamp)
+	})
+	return reports
+}
+
+// GroupByLogStream groups reports by their log stream, i.e. by execution environment.
+// The order of the reports is kept, so sorted input results in sorted groups.
+func GroupByLogStream(reports []InvocationReport) map[string][]InvocationReport {
+	groups := make(map[string][]InvocationReport)
+	for _, report := range reports {
+		groups[report.LogStream] = append(groups[report.LogStream], report)
+	}
+	return groups
+}
+
+// findFloat returns the first submatch of pattern in message as float64, or nil if it is not present.
+func findFloat(pattern *regexp.Regexp, message string) *float64 {
+	match := pattern.FindStringSubmatch(message)
+	if match == nil {
+		return nil
+	}
+	val, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return nil
+	}
+	return &val
+}
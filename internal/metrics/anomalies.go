@@ -0,0 +1,84 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/dominikhei/serverless-statistics/internal/anomaly"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetAnomalies fetches the error rate, p99 duration and cold starts of an AWS Lambda function per bucket
+// over a specified time range and qualifier (version), and detects anomalies in every series.
+// Buckets without invocations are left out of the error rate series, and the noise of the error rate
+// is estimated from the invocations of every bucket.
+func GetAnomalies(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	query sdktypes.FunctionQuery,
+	options sdktypes.AnomalyOptions,
+) (*sdktypes.AnomalyReportReturn, error) {
+
+	options, err := anomaly.Normalize(options)
+	if err != nil {
+		return nil, err
+	}
+	options.BucketSize, err = seriesBucketSize(options.BucketSize)
+	if err != nil {
+		return nil, err
+	}
+	series, err := bucketSeries(ctx, logsFetcher, cwFetcher, query, options.BucketSize,
+		errorRateSeries, p99DurationSeries, coldStartsSeries, invocationsSeries)
+	if err != nil {
+		return nil, err
+	}
+	// The invocations are only the sample sizes of the error rate.
+	invocations := series[invocationsSeries]
+	delete(series, invocationsSeries)
+
+	result := &sdktypes.AnomalyReportReturn{
+		Anomalies:    []sdktypes.Anomaly{},
+		Detections:   []sdktypes.AnomalyDetectionReturn{},
+		Series:       series,
+		Warnings:     []string{},
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	for _, metric := range []string{errorRateSeries, p99DurationSeries, coldStartsSeries} {
+		var detection *sdktypes.AnomalyDetectionReturn
+		if metric == errorRateSeries {
+			detection = anomaly.DetectRate(metric, series[metric], invocations, options)
+		} else {
+			detection = anomaly.Detect(metric, series[metric], options)
+		}
+		if detection.EvaluatedPoints == 0 && len(series[metric]) > 0 {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: no point has enough history to be evaluated, which requires %s",
+				metric, anomaly.HistoryRequirement(options)))
+		}
+		result.Detections = append(result.Detections, *detection)
+		result.Anomalies = append(result.Anomalies, detection.Anomalies...)
+	}
+	sort.SliceStable(result.Anomalies, func(i, j int) bool {
+		return result.Anomalies[i].Start.Before(result.Anomalies[j].Start)
+	})
+	return result, nil
+}
@@ -0,0 +1,289 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/pricing"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	// defaultPerformanceRatio assumes the same duration on both architectures if no ratio is given.
+	defaultPerformanceRatio = 1.0
+	// minMeasuredInvocations is the number of invocations both versions need for a measured performance ratio.
+	minMeasuredInvocations = 30
+)
+
+// GetArchitectureMigrationEstimate projects the cost of the invocations of an AWS Lambda function over a specified
+// time range and qualifier (version) on the other instruction set architecture, i.e. arm64 for x86_64 functions
+// and vice versa. The billed durations are scaled by the performance ratio, which is the duration on the target
+// architecture relative to the current one. If another version of the function runs on the target architecture
+// and both versions have at least 30 invocations in the time range, the ratio of their median durations is used instead.
+// The billed durations are taken from the REPORT lines, which are at most 10000, and scaled to all invocations
+// of the Invocations metric.
+func GetArchitectureMigrationEstimate(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+	performanceRatio float64,
+) (*sdktypes.ArchitectureMigrationEstimateReturn, error) {
+
+	if performanceRatio < 0 {
+		return nil, fmt.Errorf("performance ratio must not be negative, got %v", performanceRatio)
+	}
+	if performanceRatio == 0 {
+		performanceRatio = defaultPerformanceRatio
+	}
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	var memorySizeMB float64
+	architecture := pricing.ArchitectureX86
+	if funcConfig.Configuration != nil {
+		memorySizeMB = float64(aws.ToInt32(funcConfig.Configuration.MemorySize))
+		architecture = functionArchitecture(funcConfig.Configuration.Architectures)
+	}
+	targetArchitecture := pricing.ArchitectureArm64
+	if architecture == pricing.ArchitectureArm64 {
+		targetArchitecture = pricing.ArchitectureX86
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+	if memorySizeMB == 0 {
+		memorySizeMB = reports[0].MemorySizeMB
+	}
+
+	result := &sdktypes.ArchitectureMigrationEstimateReturn{
+		Architecture:       architecture,
+		TargetArchitecture: targetArchitecture,
+		PerformanceRatio:   performanceRatio,
+		Invocations:        int(invocationsSum),
+		SampledInvocations: len(reports),
+		MemorySizeMB:       memorySizeMB,
+		FunctionName:       query.FunctionName,
+		Qualifier:          query.Qualifier,
+		StartTime:          query.StartTime,
+		EndTime:            query.EndTime,
+	}
+
+	measuredRatio, measuredVersion, err := measuredPerformanceRatio(ctx, logsFetcher, cwFetcher, versionsClient, invocationsCache, query, targetArchitecture, reports)
+	if err != nil {
+		return nil, err
+	}
+	if measuredVersion != "" {
+		result.PerformanceRatio = measuredRatio
+		result.MeasuredVersion = measuredVersion
+	}
+
+	var sampledBilledMs, projectedBilledMs float64
+	for _, report := range reports {
+		sampledBilledMs += report.BilledDurationMs
+		// Lambda bills the duration rounded up to the next millisecond.
+		projectedBilledMs += math.Ceil(report.BilledDurationMs * result.PerformanceRatio)
+	}
+	// The REPORT lines are a sample if the query limit is hit or logs are missing.
+	scale := invocationsSum / float64(len(reports))
+	result.BilledDurationMs = sampledBilledMs * scale
+	projectedBilledMs *= scale
+	requests := float64(result.Invocations) / 1e6
+	current := pricing.ForArchitecture(architecture)
+	target := pricing.ForArchitecture(targetArchitecture)
+	result.CurrentCost = requests*current.PerMillionRequests +
+		pricing.GBSeconds(result.BilledDurationMs, memorySizeMB)*current.PerGBSecond
+	result.ProjectedCost = requests*target.PerMillionRequests +
+		pricing.GBSeconds(projectedBilledMs, memorySizeMB)*target.PerGBSecond
+	result.Savings = result.CurrentCost - result.ProjectedCost
+	if result.CurrentCost > 0 {
+		result.SavingsRate = result.Savings / result.CurrentCost
+	}
+	return result, nil
+}
+
+// measuredPerformanceRatio returns the ratio of the median durations of the newest other version on the target
+// architecture with enough invocations and the queried version, together with that version.
+// An empty version is returned if there is none.
+func measuredPerformanceRatio(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+	targetArchitecture string,
+	reports []logparser.InvocationReport,
+) (float64, string, error) {
+	if len(reports) < minMeasuredInvocations {
+		return 0, "", nil
+	}
+	versions, err := listFunctionVersions(ctx, versionsClient, query.FunctionName)
+	if err != nil {
+		return 0, "", err
+	}
+	// Published versions are listed in ascending order, so the newest ones are checked first.
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := aws.ToString(versions[i].Version)
+		if version == query.Qualifier || functionArchitecture(versions[i].Architectures) != targetArchitecture {
+			continue
+		}
+		versionQuery := query
+		versionQuery.Qualifier = version
+		invocations, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, versionQuery)
+		if err != nil {
+			return 0, "", err
+		}
+		if invocations < minMeasuredInvocations {
+			continue
+		}
+		targetReports, err := getReports(ctx, logsFetcher, versionQuery)
+		if err != nil {
+			return 0, "", err
+		}
+		if len(targetReports) < minMeasuredInvocations {
+			continue
+		}
+		currentMedian := medianDuration(reports)
+		if currentMedian == 0 {
+			return 0, "", nil
+		}
+		return medianDuration(targetReports) / currentMedian, version, nil
+	}
+	return 0, "", nil
+}
+
+// medianDuration returns the median duration of the reports in milliseconds.
+func medianDuration(reports []logparser.InvocationReport) float64 {
+	durations := make([]float64, len(reports))
+	for i, report := range reports {
+		durations[i] = report.DurationMs
+	}
+	sort.Float64s(durations)
+	return utils.Quantile(0.5, durations)
+}
+
+// GetArchitectureMigrationRanking estimates the savings of migrating x86_64 functions to arm64 and ranks them by
+// descending savings. The $LATEST version of every function is estimated with GetArchitectureMigrationEstimate.
+// If no function names are given, all functions of the account in the region are ranked.
+// Functions without invocations in the time range and functions already on arm64 are left out.
+// Functions that cannot be estimated are left out with a warning, so that one of them does not fail the ranking.
+func GetArchitectureMigrationRanking(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	functionListClient sdkinterfaces.FunctionListClient,
+	invocationsCache sdkinterfaces.Cache,
+	functionNames []string,
+	startTime, endTime time.Time,
+	performanceRatio float64,
+) (*sdktypes.ArchitectureMigrationRankingReturn, error) {
+
+	if len(functionNames) == 0 {
+		var err error
+		functionNames, err = listX86FunctionNames(ctx, functionListClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &sdktypes.ArchitectureMigrationRankingReturn{
+		Candidates: []sdktypes.ArchitectureMigrationEstimateReturn{},
+		Warnings:   []string{},
+		StartTime:  startTime,
+		EndTime:    endTime,
+	}
+	for _, functionName := range functionNames {
+		query := sdktypes.FunctionQuery{
+			FunctionName: functionName,
+			Qualifier:    "$LATEST",
+			StartTime:    startTime,
+			EndTime:      endTime,
+		}
+		estimate, err := GetArchitectureMigrationEstimate(ctx, logsFetcher, cwFetcher, lambdaClient, versionsClient, invocationsCache, query, performanceRatio)
+		var noInvocations *sdkerrors.NoInvocationsError
+		if errors.As(err, &noInvocations) {
+			continue
+		}
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("estimate %s: %v", functionName, err))
+			continue
+		}
+		if estimate.Architecture != pricing.ArchitectureX86 {
+			continue
+		}
+		result.Candidates = append(result.Candidates, *estimate)
+	}
+	sort.SliceStable(result.Candidates, func(i, j int) bool {
+		return result.Candidates[i].Savings > result.Candidates[j].Savings
+	})
+	return result, nil
+}
+
+// listX86FunctionNames returns the names of all x86_64 functions of the account, following the pagination markers.
+func listX86FunctionNames(ctx context.Context, functionListClient sdkinterfaces.FunctionListClient) ([]string, error) {
+	names := []string{}
+	var marker *string
+	for {
+		output, err := functionListClient.ListFunctions(ctx, &lambda.ListFunctionsInput{Marker: marker})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list functions: %w", err)
+		}
+		for _, function := range output.Functions {
+			if functionArchitecture(function.Architectures) == pricing.ArchitectureX86 {
+				names = append(names, aws.ToString(function.FunctionName))
+			}
+		}
+		if output.NextMarker == nil || *output.NextMarker == "" {
+			return names, nil
+		}
+		marker = output.NextMarker
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// Defaults Lambda applies if a function has no event invoke config.
+const (
+	defaultMaximumRetryAttempts   int32 = 2
+	defaultMaximumEventAgeSeconds int32 = 21600
+)
+
+// eventAgeWarningThreshold is the share of the maximum event age from which on event ages are warned about.
+const eventAgeWarningThreshold = 0.8
+
+// GetAsyncInvocationStatistics calculates the health of the asynchronous invocations of an AWS Lambda function
+// over a specified time range and qualifier (version): how many events are dropped, how many fail to be
+// delivered to the dead-letter queue or a destination and how long events wait in the queue.
+// The event age is compared with the maximum event age of the event invoke config.
+func GetAsyncInvocationStatistics(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	eventInvokeConfigClient sdkinterfaces.EventInvokeConfigClient,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.AsyncInvocationStatisticsReturn, error) {
+
+	result := &sdktypes.AsyncInvocationStatisticsReturn{
+		MaximumRetryAttempts:   defaultMaximumRetryAttempts,
+		MaximumEventAgeSeconds: defaultMaximumEventAgeSeconds,
+		Warnings:               []string{},
+		FunctionName:           query.FunctionName,
+		Qualifier:              query.Qualifier,
+		StartTime:              query.StartTime,
+		EndTime:                query.EndTime,
+	}
+
+	for metricName, target := range map[string]*float64{
+		"AsyncEventsReceived":         &result.EventsReceived,
+		"AsyncEventsDropped":          &result.EventsDropped,
+		"DeadLetterErrors":            &result.DeadLetterErrors,
+		"DestinationDeliveryFailures": &result.DestinationDeliveryFailures,
+	} {
+		results, err := cwFetcher.FetchMetric(ctx, query, metricName, "Sum")
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s metric: %w", metricName, err)
+		}
+		sum, err := utils.SumMetricValues(results)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s metric data: %w", metricName, err)
+		}
+		*target = sum
+	}
+	if result.EventsReceived == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+	result.DropRate = result.EventsDropped / result.EventsReceived
+	result.DeliveryFailureRate = (result.DeadLetterErrors + result.DestinationDeliveryFailures) / result.EventsReceived
+
+	period := windowPeriod(query)
+	for stat, target := range map[string]**float64{
+		"p50":     &result.P50EventAge,
+		"p90":     &result.P90EventAge,
+		"p99":     &result.P99EventAge,
+		"Maximum": &result.MaxEventAge,
+	} {
+		results, err := cwFetcher.FetchMetricSeries(ctx, query, "AsyncEventAge", stat, period)
+		if err != nil {
+			return nil, fmt.Errorf("fetch AsyncEventAge metric: %w", err)
+		}
+		*target = singleValue(results)
+	}
+
+	invokeConfig, err := eventInvokeConfigClient.GetFunctionEventInvokeConfig(ctx, &lambda.GetFunctionEventInvokeConfigInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	var notFound *lambdatypes.ResourceNotFoundException
+	switch {
+	case errors.As(err, &notFound):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get event invoke config: %w", err)
+	default:
+		if invokeConfig.MaximumRetryAttempts != nil {
+			result.MaximumRetryAttempts = *invokeConfig.MaximumRetryAttempts
+		}
+		if invokeConfig.MaximumEventAgeInSeconds != nil {
+			result.MaximumEventAgeSeconds = *invokeConfig.MaximumEventAgeInSeconds
+		}
+		if destinations := invokeConfig.DestinationConfig; destinations != nil {
+			if destinations.OnSuccess != nil {
+				result.OnSuccessDestination = aws.ToString(destinations.OnSuccess.Destination)
+			}
+			if destinations.OnFailure != nil {
+				result.OnFailureDestination = aws.ToString(destinations.OnFailure.Destination)
+			}
+		}
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	if funcConfig.Configuration != nil && funcConfig.Configuration.DeadLetterConfig != nil {
+		result.DeadLetterQueue = aws.ToString(funcConfig.Configuration.DeadLetterConfig.TargetArn)
+	}
+
+	result.Warnings = asyncInvocationWarnings(result)
+	return result, nil
+}
+
+// asyncInvocationWarnings returns warnings for event ages close to the maximum event age
+// and for events that are lost without a place to keep them.
+func asyncInvocationWarnings(result *sdktypes.AsyncInvocationStatisticsReturn) []string {
+	warnings := []string{}
+	maxAgeMs := float64(result.MaximumEventAgeSeconds) * 1000
+	for _, age := range []struct {
+		name  string
+		value *float64
+	}{
+		{"p99", result.P99EventAge},
+		{"max", result.MaxEventAge},
+	} {
+		if age.value != nil && maxAgeMs > 0 && *age.value >= eventAgeWarningThreshold*maxAgeMs {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s event age of %.0f ms is %.0f%% of the maximum event age of %d s, events are about to be dropped",
+				age.name, *age.value, *age.value/maxAgeMs*100, result.MaximumEventAgeSeconds))
+		}
+	}
+	if result.EventsDropped > 0 && result.OnFailureDestination == "" && result.DeadLetterQueue == "" {
+		warnings = append(warnings, fmt.Sprintf(
+			"%.0f events were dropped without an on-failure destination or dead-letter queue to keep them", result.EventsDropped))
+	}
+	if result.DeliveryFailureRate > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"%.0f events could not be delivered to the dead-letter queue or a destination, check their permissions and limits",
+			result.DeadLetterErrors+result.DestinationDeliveryFailures))
+	}
+	return warnings
+}
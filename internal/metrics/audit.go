@@ -0,0 +1,89 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/audit"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetAuditReport evaluates audit rules against the configuration of an AWS Lambda function with a specific
+// qualifier and the metrics observed in a specified time range: the memory usage and the asynchronous events
+// received. Functions without invocations are audited too, rules on the memory usage then pass.
+// If no rules are configured, the default rules are evaluated with the data of the settings.
+func GetAuditReport(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	provisionedConcurrencyClient sdkinterfaces.ProvisionedConcurrencyClient,
+	eventInvokeConfigClient sdkinterfaces.EventInvokeConfigClient,
+	functionURLClient sdkinterfaces.FunctionURLClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+	options sdktypes.AuditOptions,
+	now time.Time,
+) (*sdktypes.AuditReportReturn, error) {
+
+	rules := options.Rules
+	if rules == nil {
+		var err error
+		rules, err = audit.Rules(options.Settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+	configuration, err := GetFunctionConfiguration(ctx, lambdaClient, provisionedConcurrencyClient, eventInvokeConfigClient,
+		functionURLClient, query, sdktypes.RedactionPolicy{})
+	if err != nil {
+		return nil, err
+	}
+	memoryUsage, err := GetMaxMemoryUsageStatistics(ctx, logsFetcher, cwFetcher, invocationsCache, query)
+	var noInvocations *sdkerrors.NoInvocationsError
+	if err != nil && !errors.As(err, &noInvocations) {
+		return nil, err
+	}
+	asyncResults, err := cwFetcher.FetchMetric(ctx, query, "AsyncEventsReceived", "Sum")
+	if err != nil {
+		return nil, fmt.Errorf("fetch AsyncEventsReceived metric: %w", err)
+	}
+	asyncEventsReceived, err := utils.SumMetricValues(asyncResults)
+	if err != nil {
+		return nil, fmt.Errorf("parse AsyncEventsReceived metric data: %w", err)
+	}
+
+	report := &sdktypes.AuditReportReturn{
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	audit.Evaluate(sdktypes.AuditSubject{
+		Configuration:       configuration,
+		MemoryUsage:         memoryUsage,
+		AsyncEventsReceived: asyncEventsReceived,
+		Critical:            options.Critical,
+		Now:                 now,
+	}, rules, report)
+	return report, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// Names of the series bucketSeries fetches.
+const (
+	invocationsSeries   = "invocations"
+	errorRateSeries     = "errorRate"
+	p99DurationSeries   = "p99DurationMs"
+	coldStartsSeries    = "coldStarts"
+	maxMemoryUsedSeries = "maxMemoryUsedMB"
+)
+
+// defaultSeriesBucketSize is the width of the buckets the series are fetched in if none is configured.
+const defaultSeriesBucketSize = time.Hour
+
+// seriesBucketSize returns the bucket size of the series, the default if none is configured,
+// and an error if CloudWatch and Logs Insights cannot aggregate in it.
+func seriesBucketSize(bucketSize time.Duration) (time.Duration, error) {
+	if bucketSize == 0 {
+		return defaultSeriesBucketSize, nil
+	}
+	if bucketSize < time.Minute || bucketSize%time.Minute != 0 {
+		return 0, fmt.Errorf("bucket size must be a multiple of one minute, got %s", bucketSize)
+	}
+	return bucketSize, nil
+}
+
+// bucketSeries fetches the named series of the query per bucket. The invocations, error rate and p99 duration are read
+// from CloudWatch metrics, leaving out buckets without invocations from the error rate, and the cold starts
+// and max memory used from the REPORT lines in the logs.
+func bucketSeries(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	query sdktypes.FunctionQuery,
+	bucketSize time.Duration,
+	names ...string,
+) (map[string][]sdktypes.MetricPoint, error) {
+	period := int32(bucketSize / time.Second)
+	minutes := int(bucketSize / time.Minute)
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+
+	series := make(map[string][]sdktypes.MetricPoint, len(names))
+	for _, name := range names {
+		switch name {
+		case invocationsSeries:
+			invocationsResults, err := cwFetcher.FetchMetricSeries(ctx, query, "Invocations", "Sum", period)
+			if err != nil {
+				return nil, fmt.Errorf("fetch invocations metric: %w", err)
+			}
+			series[name] = metricPoints(invocationsResults)
+		case errorRateSeries:
+			invocationsResults, err := cwFetcher.FetchMetricSeries(ctx, query, "Invocations", "Sum", period)
+			if err != nil {
+				return nil, fmt.Errorf("fetch invocations metric: %w", err)
+			}
+			errorsResults, err := cwFetcher.FetchMetricSeries(ctx, query, "Errors", "Sum", period)
+			if err != nil {
+				return nil, fmt.Errorf("fetch errors metric: %w", err)
+			}
+			errorsPerBucket := make(map[time.Time]float64)
+			for _, point := range metricPoints(errorsResults) {
+				errorsPerBucket[point.Timestamp] = point.Value
+			}
+			points := []sdktypes.MetricPoint{}
+			for _, point := range metricPoints(invocationsResults) {
+				if point.Value > 0 {
+					points = append(points, sdktypes.MetricPoint{
+						Timestamp: point.Timestamp,
+						Value:     errorsPerBucket[point.Timestamp] / point.Value,
+					})
+				}
+			}
+			series[name] = points
+		case p99DurationSeries:
+			durationResults, err := cwFetcher.FetchMetricSeries(ctx, query, "Duration", "p99", period)
+			if err != nil {
+				return nil, fmt.Errorf("fetch duration metric: %w", err)
+			}
+			series[name] = metricPoints(durationResults)
+		case coldStartsSeries, maxMemoryUsedSeries:
+			queryString := fmt.Sprintf(queries.LambdaColdStartsPerBinWithVersion, escapedQualifier, minutes)
+			if name == maxMemoryUsedSeries {
+				queryString = fmt.Sprintf(queries.LambdaMaxMemoryUsedPerBinWithVersion, escapedQualifier, minutes)
+			}
+			rows, err := logsFetcher.RunQuery(ctx, query, queryString)
+			if err != nil {
+				return nil, fmt.Errorf("run logs insights query: %w", err)
+			}
+			points := []sdktypes.MetricPoint{}
+			for _, row := range rows {
+				bucket, err := logparser.ParseTimestamp(row["bucket"])
+				if err != nil {
+					return nil, fmt.Errorf("parse bucket from logs: %w", err)
+				}
+				value, err := parseCount(row, name)
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, sdktypes.MetricPoint{Timestamp: bucket, Value: value})
+			}
+			series[name] = points
+		default:
+			return nil, fmt.Errorf("unknown series %q", name)
+		}
+	}
+	return series, nil
+}
@@ -0,0 +1,418 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	codedeploytypes "github.com/aws/aws-sdk-go-v2/service/codedeploy/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultCanaryThresholds returns thresholds that tolerate one percentage point more errors, a 20% higher
+// p99 duration and half a percentage point more timeouts, decided on at least 100 invocations per version.
+func DefaultCanaryThresholds() sdktypes.CanaryThresholds {
+	return sdktypes.CanaryThresholds{
+		MaxErrorRateIncrease:   0.01,
+		MaxP99DurationIncrease: 0.2,
+		MaxTimeoutRateIncrease: 0.005,
+		MinInvocations:         100,
+	}
+}
+
+// GetCanaryAnalysis compares the canary version of an AWS Lambda function with the baseline version over the same
+// time range and decides whether the rollout can proceed. The error rate is calculated from the CloudWatch metrics,
+// the p99 duration and timeout rate from the REPORT lines of the versions as in the per-qualifier metrics.
+// If an alias is given, the error rate is read from the invocations through the alias per executed version,
+// and missing versions are read from the routing config of the alias.
+func GetCanaryAnalysis(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	aliasClient sdkinterfaces.AliasClient,
+	query sdktypes.FunctionQuery,
+	alias, baselineVersion, canaryVersion string,
+	thresholds sdktypes.CanaryThresholds,
+) (*sdktypes.CanaryAnalysisReturn, error) {
+
+	result := &sdktypes.CanaryAnalysisReturn{
+		Warnings:     []string{},
+		FunctionName: query.FunctionName,
+		Alias:        alias,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	if baselineVersion == "" || canaryVersion == "" {
+		if alias == "" {
+			return nil, fmt.Errorf("an alias is required if the baseline and canary versions are not set")
+		}
+		routedBaseline, routedCanary, weight, err := aliasVersions(ctx, aliasClient, query.FunctionName, alias)
+		if err != nil {
+			return nil, err
+		}
+		if baselineVersion == "" {
+			baselineVersion = routedBaseline
+		}
+		if canaryVersion == "" {
+			canaryVersion = routedCanary
+		}
+		result.CanaryWeight = &weight
+	}
+	if baselineVersion == canaryVersion {
+		return nil, fmt.Errorf("baseline and canary are both version %q", baselineVersion)
+	}
+
+	baseline, err := canaryVersionStatistics(ctx, logsFetcher, cwFetcher, query, alias, baselineVersion)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	canary, err := canaryVersionStatistics(ctx, logsFetcher, cwFetcher, query, alias, canaryVersion)
+	if err != nil {
+		return nil, fmt.Errorf("canary: %w", err)
+	}
+	result.Baseline = *baseline
+	result.Canary = *canary
+	for _, stats := range []*sdktypes.CanaryVersionStatistics{baseline, canary} {
+		if stats.ReportedInvocations >= logsQueryLimit {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"version %s: only the first %d REPORT lines are analyzed, the p99 duration and timeout rate are from a sample", stats.Version, logsQueryLimit))
+		}
+	}
+	result.Checks = canaryChecks(baseline, canary, alias, thresholds)
+	result.Verdict = canaryVerdict(result.Checks)
+	return result, nil
+}
+
+// aliasVersions returns the primary version of an alias as baseline and the single additional version
+// it routes traffic to as canary, together with the weight of the canary.
+func aliasVersions(
+	ctx context.Context,
+	aliasClient sdkinterfaces.AliasClient,
+	functionName, alias string,
+) (string, string, float64, error) {
+	output, err := aliasClient.GetAlias(ctx, &lambda.GetAliasInput{
+		FunctionName: aws.String(functionName),
+		Name:         aws.String(alias),
+	})
+	if err != nil {
+		return "", "", 0, fmt.Errorf("failed to get alias: %w", err)
+	}
+	if output.RoutingConfig == nil || len(output.RoutingConfig.AdditionalVersionWeights) != 1 {
+		return "", "", 0, fmt.Errorf("alias %q does not route traffic to exactly one additional version, set the versions explicitly", alias)
+	}
+	for version, weight := range output.RoutingConfig.AdditionalVersionWeights {
+		return aws.ToString(output.FunctionVersion), version, weight, nil
+	}
+	return "", "", 0, nil
+}
+
+// canaryVersionStatistics calculates the statistics of one version in the time range of the query.
+// The invocations and error rate are read from CloudWatch metrics, the p99 duration and timeout rate
+// from the REPORT lines of the version in the logs.
+func canaryVersionStatistics(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	query sdktypes.FunctionQuery,
+	alias, version string,
+) (*sdktypes.CanaryVersionStatistics, error) {
+	versionQuery := query
+	versionQuery.Qualifier = version
+	stats := &sdktypes.CanaryVersionStatistics{Version: version}
+
+	sums := make(map[string]float64, 2)
+	for _, metricName := range []string{"Invocations", "Errors"} {
+		var results []types.MetricDataResult
+		var err error
+		if alias != "" {
+			results, err = cwFetcher.FetchDimensionMetricSeries(ctx, versionQuery, "AWS/Lambda", metricName, "Sum", windowPeriod(versionQuery), map[string]string{
+				"FunctionName":    query.FunctionName,
+				"Resource":        query.FunctionName + ":" + alias,
+				"ExecutedVersion": version,
+			})
+		} else {
+			results, err = cwFetcher.FetchMetric(ctx, versionQuery, metricName, "Sum")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s metric: %w", metricName, err)
+		}
+		sums[metricName], err = utils.SumMetricValues(results)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s metric data: %w", metricName, err)
+		}
+	}
+	stats.Invocations = int(sums["Invocations"])
+	if sums["Invocations"] > 0 {
+		stats.ErrorRate = sums["Errors"] / sums["Invocations"]
+	}
+
+	reports, err := getReports(ctx, logsFetcher, versionQuery)
+	if err != nil {
+		return nil, err
+	}
+	stats.ReportedInvocations = len(reports)
+	durations := make([]float64, 0, len(reports))
+	for _, report := range reports {
+		durations = append(durations, report.DurationMs)
+		if report.Status == "timeout" {
+			stats.TimedOutInvocations++
+		}
+	}
+	if len(reports) > 0 {
+		stats.TimeoutRate = float64(stats.TimedOutInvocations) / float64(len(reports))
+		summary, err := utils.CalcSummaryStats(durations)
+		if err != nil {
+			return nil, fmt.Errorf("error calculating summary statistics: %w", err)
+		}
+		stats.P99DurationMs = summary.P99
+	}
+	return stats, nil
+}
+
+// canaryChecks compares the error rate, p99 duration and timeout rate of the canary with the baseline.
+// Every check is decided on the sample size of the source it is calculated from.
+func canaryChecks(baseline, canary *sdktypes.CanaryVersionStatistics, alias string, thresholds sdktypes.CanaryThresholds) []sdktypes.CanaryCheck {
+	sample := func(baselineInvocations, canaryInvocations int) (bool, string) {
+		return baselineInvocations >= thresholds.MinInvocations && canaryInvocations >= thresholds.MinInvocations,
+			fmt.Sprintf("baseline has %d and canary %d invocations, %d are required", baselineInvocations, canaryInvocations, thresholds.MinInvocations)
+	}
+	metricSource := fmt.Sprintf("CloudWatch Errors and Invocations metrics of the versions, %d and %d invocations",
+		baseline.Invocations, canary.Invocations)
+	if alias != "" {
+		metricSource = fmt.Sprintf("CloudWatch Errors and Invocations metrics of alias %s per executed version, %d and %d invocations",
+			alias, baseline.Invocations, canary.Invocations)
+	}
+	logSource := fmt.Sprintf("REPORT lines of the versions in the logs, %d and %d invocations",
+		baseline.ReportedInvocations, canary.ReportedInvocations)
+	if alias != "" {
+		logSource += ", including invocations that are not through the alias"
+	}
+
+	enoughInvocations, sampleReason := sample(baseline.Invocations, canary.Invocations)
+	errorCheck := sdktypes.CanaryCheck{
+		Metric:     "errorRate",
+		Source:     metricSource,
+		Baseline:   baseline.ErrorRate,
+		Canary:     canary.ErrorRate,
+		Difference: canary.ErrorRate - baseline.ErrorRate,
+		Threshold:  thresholds.MaxErrorRateIncrease,
+	}
+	checks := []sdktypes.CanaryCheck{decideCanaryCheck(errorCheck, enoughInvocations, sampleReason, "percentage points", 100)}
+
+	enoughReports, reportsReason := sample(baseline.ReportedInvocations, canary.ReportedInvocations)
+	durationCheck := sdktypes.CanaryCheck{Metric: "p99DurationMs", Source: logSource, Threshold: thresholds.MaxP99DurationIncrease}
+	switch {
+	case baseline.P99DurationMs == nil || canary.P99DurationMs == nil:
+		durationCheck.Status = sdktypes.CanaryCheckInconclusive
+		durationCheck.Reason = "p99 duration requires at least 100 invocations of both versions"
+	default:
+		durationCheck.Baseline = *baseline.P99DurationMs
+		durationCheck.Canary = *canary.P99DurationMs
+		if durationCheck.Baseline > 0 {
+			durationCheck.Difference = durationCheck.Canary/durationCheck.Baseline - 1
+		}
+		durationCheck = decideCanaryCheck(durationCheck, enoughReports, reportsReason, "%", 100)
+	}
+	timeoutCheck := sdktypes.CanaryCheck{
+		Metric:     "timeoutRate",
+		Source:     logSource,
+		Baseline:   baseline.TimeoutRate,
+		Canary:     canary.TimeoutRate,
+		Difference: canary.TimeoutRate - baseline.TimeoutRate,
+		Threshold:  thresholds.MaxTimeoutRateIncrease,
+	}
+	return append(checks, durationCheck, decideCanaryCheck(timeoutCheck, enoughReports, reportsReason, "percentage points", 100))
+}
+
+// decideCanaryCheck sets the status and reason of a check from its difference and threshold,
+// which are reported multiplied by scale in the given unit.
+func decideCanaryCheck(check sdktypes.CanaryCheck, enoughInvocations bool, sampleReason, unit string, scale float64) sdktypes.CanaryCheck {
+	switch {
+	case !enoughInvocations:
+		check.Status = sdktypes.CanaryCheckInconclusive
+		check.Reason = sampleReason
+	case check.Difference > check.Threshold:
+		check.Status = sdktypes.CanaryCheckFailed
+		check.Reason = fmt.Sprintf("canary is %.2f %s worse than the baseline, %.2f are allowed", check.Difference*scale, unit, check.Threshold*scale)
+	default:
+		check.Status = sdktypes.CanaryCheckPassed
+		check.Reason = fmt.Sprintf("canary is within %.2f %s of the baseline", check.Threshold*scale, unit)
+	}
+	return check
+}
+
+// canaryVerdict rolls back if any check failed, and is inconclusive if any check could not be decided.
+func canaryVerdict(checks []sdktypes.CanaryCheck) sdktypes.CanaryVerdict {
+	verdict := sdktypes.CanaryVerdictProceed
+	for _, check := range checks {
+		switch check.Status {
+		case sdktypes.CanaryCheckFailed:
+			return sdktypes.CanaryVerdictRollback
+		case sdktypes.CanaryCheckInconclusive:
+			verdict = sdktypes.CanaryVerdictInconclusive
+		}
+	}
+	return verdict
+}
+
+// HandleCanaryHook analyzes a canary for a CodeDeploy lifecycle event hook and reports the outcome to CodeDeploy:
+// Succeeded if the verdict is PROCEED, or INCONCLUSIVE and proceeding on inconclusive analyses is configured,
+// and Failed otherwise, which makes CodeDeploy roll back. Failed analyses are reported as Failed too,
+// so the deployment does not wait for the hook to time out.
+//
+// Versions that are not configured are read from the AppSpec of the deployment, as the alias has no routing
+// config while the hooks run.
+//
+// A BeforeAllowTraffic (PreTraffic) hook runs before any traffic is shifted to the canary, so the canary has
+// no invocations yet and the verdict is INCONCLUSIVE. Such hooks are reported as Failed unless
+// ProceedOnInconclusive is set.
+func HandleCanaryHook(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	aliasClient sdkinterfaces.AliasClient,
+	codeDeployClient sdkinterfaces.CodeDeployClient,
+	config sdktypes.CanaryHookConfig,
+	event sdktypes.CodeDeployHookEvent,
+	now time.Time,
+) (*sdktypes.CanaryAnalysisReturn, error) {
+	analysis, analysisErr := analyzeCanaryHook(ctx, logsFetcher, cwFetcher, aliasClient, codeDeployClient, config, event, now)
+
+	status := codedeploytypes.LifecycleEventStatusFailed
+	if analysisErr == nil && (analysis.Verdict == sdktypes.CanaryVerdictProceed ||
+		(analysis.Verdict == sdktypes.CanaryVerdictInconclusive && config.ProceedOnInconclusive)) {
+		status = codedeploytypes.LifecycleEventStatusSucceeded
+	}
+	_, err := codeDeployClient.PutLifecycleEventHookExecutionStatus(ctx, &codedeploy.PutLifecycleEventHookExecutionStatusInput{
+		DeploymentId:                  aws.String(event.DeploymentID),
+		LifecycleEventHookExecutionId: aws.String(event.LifecycleEventHookExecutionID),
+		Status:                        status,
+	})
+	if analysisErr != nil {
+		return nil, fmt.Errorf("analyze canary: %w", analysisErr)
+	}
+	if err != nil {
+		return analysis, fmt.Errorf("failed to put lifecycle event hook execution status: %w", err)
+	}
+	return analysis, nil
+}
+
+// analyzeCanaryHook analyzes the canary over the window before the hook, with the versions and alias
+// of the deployment for those that are not configured.
+func analyzeCanaryHook(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	aliasClient sdkinterfaces.AliasClient,
+	codeDeployClient sdkinterfaces.CodeDeployClient,
+	config sdktypes.CanaryHookConfig,
+	event sdktypes.CodeDeployHookEvent,
+	now time.Time,
+) (*sdktypes.CanaryAnalysisReturn, error) {
+	alias, baselineVersion, canaryVersion := config.Alias, config.BaselineVersion, config.CanaryVersion
+	if baselineVersion == "" || canaryVersion == "" {
+		deployed, err := deploymentAppSpec(ctx, codeDeployClient, event.DeploymentID, config.FunctionName)
+		if err != nil {
+			return nil, err
+		}
+		if alias == "" {
+			alias = deployed.Alias
+		}
+		if baselineVersion == "" {
+			baselineVersion = deployed.CurrentVersion
+		}
+		if canaryVersion == "" {
+			canaryVersion = deployed.TargetVersion
+		}
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: config.FunctionName,
+		StartTime:    now.Add(-config.Window),
+		EndTime:      now,
+	}
+	return GetCanaryAnalysis(ctx, logsFetcher, cwFetcher, aliasClient, query, alias,
+		baselineVersion, canaryVersion, config.Thresholds)
+}
+
+// lambdaAppSpecProperties are the properties of a Lambda function resource in an AppSpec file.
+type lambdaAppSpecProperties struct {
+	Name           string `yaml:"Name"`
+	Alias          string `yaml:"Alias"`
+	CurrentVersion string `yaml:"CurrentVersion"`
+	TargetVersion  string `yaml:"TargetVersion"`
+}
+
+// lambdaAppSpec is the part of an AppSpec file of a Lambda deployment that names the versions
+// traffic is shifted between. AppSpec files are YAML or JSON, which is YAML too.
+type lambdaAppSpec struct {
+	Resources []map[string]struct {
+		Type       string                  `yaml:"Type"`
+		Properties lambdaAppSpecProperties `yaml:"Properties"`
+	} `yaml:"Resources"`
+}
+
+// deploymentAppSpec returns the alias and the versions a CodeDeploy deployment shifts the traffic
+// of a function between, read from the AppSpec of its revision.
+func deploymentAppSpec(
+	ctx context.Context,
+	codeDeployClient sdkinterfaces.CodeDeployClient,
+	deploymentID, functionName string,
+) (*lambdaAppSpecProperties, error) {
+	output, err := codeDeployClient.GetDeployment(ctx, &codedeploy.GetDeploymentInput{
+		DeploymentId: aws.String(deploymentID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	var content string
+	if output.DeploymentInfo != nil && output.DeploymentInfo.Revision != nil {
+		revision := output.DeploymentInfo.Revision
+		switch {
+		case revision.AppSpecContent != nil:
+			content = aws.ToString(revision.AppSpecContent.Content)
+		case revision.String_ != nil:
+			content = aws.ToString(revision.String_.Content)
+		}
+	}
+	if content == "" {
+		return nil, fmt.Errorf("deployment %s has no AppSpec content, set the versions explicitly", deploymentID)
+	}
+	var appSpec lambdaAppSpec
+	if err := yaml.Unmarshal([]byte(content), &appSpec); err != nil {
+		return nil, fmt.Errorf("parse AppSpec of deployment %s: %w", deploymentID, err)
+	}
+	for _, resources := range appSpec.Resources {
+		for _, resource := range resources {
+			properties := resource.Properties
+			if resource.Type != "AWS::Lambda::Function" || properties.Name != functionName {
+				continue
+			}
+			if properties.CurrentVersion == "" || properties.TargetVersion == "" {
+				return nil, fmt.Errorf("AppSpec of deployment %s has no current and target version of %s", deploymentID, functionName)
+			}
+			return &properties, nil
+		}
+	}
+	return nil, fmt.Errorf("AppSpec of deployment %s does not deploy function %s", deploymentID, functionName)
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"sort"
+
+	"github.com/dominikhei/serverless-statistics/internal/changepoint"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetChangePoints fetches the error rate, p99 duration and max memory used of an AWS Lambda function per bucket
+// over a specified time range and qualifier (version), finds the points in time their mean shifted at, and
+// annotates them with the nearest deployment of the deployment timeline.
+func GetChangePoints(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	cloudTrailClient sdkinterfaces.CloudTrailClient,
+	query sdktypes.FunctionQuery,
+	options sdktypes.ChangePointOptions,
+) (*sdktypes.ChangePointReportReturn, error) {
+
+	options, err := changepoint.Normalize(options)
+	if err != nil {
+		return nil, err
+	}
+	options.BucketSize, err = seriesBucketSize(options.BucketSize)
+	if err != nil {
+		return nil, err
+	}
+	series, err := bucketSeries(ctx, logsFetcher, cwFetcher, query, options.BucketSize,
+		errorRateSeries, p99DurationSeries, maxMemoryUsedSeries)
+	if err != nil {
+		return nil, err
+	}
+	timeline, err := GetDeploymentTimeline(ctx, versionsClient, cloudTrailClient, query.FunctionName, query.StartTime, query.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if options.AttributionWindow == 0 {
+		options.AttributionWindow = 2 * options.BucketSize
+	}
+
+	result := &sdktypes.ChangePointReportReturn{
+		ChangePoints: []sdktypes.ChangePoint{},
+		Detections:   []sdktypes.ChangePointDetectionReturn{},
+		Deployments:  timeline.Deployments,
+		Series:       series,
+		Warnings:     timeline.Warnings,
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	for _, metric := range []string{errorRateSeries, p99DurationSeries, maxMemoryUsedSeries} {
+		detection := changepoint.Detect(metric, series[metric], timeline.Deployments, options)
+		result.Detections = append(result.Detections, *detection)
+		result.ChangePoints = append(result.ChangePoints, detection.ChangePoints...)
+	}
+	sort.SliceStable(result.ChangePoints, func(i, j int) bool {
+		return result.ChangePoints[i].Time.Before(result.ChangePoints[j].Time)
+	})
+	return result, nil
+}
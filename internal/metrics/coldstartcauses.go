@@ -0,0 +1,285 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// defaultIdleThreshold is the idle time after which a cold start is attributed to
+// environments being recycled, if no threshold is given.
+const defaultIdleThreshold = 10 * time.Minute
+
+// GetColdStartCauses classifies every cold start of an AWS Lambda function over a specified time range
+// and qualifier (version) by its cause. A cold start is attributed to, in this order:
+//   - a deployment, if no other environment was started since the version was last modified
+//   - an error replacement, if the previous invocation of the environment, or the last invocation
+//     of another environment within the idle threshold, failed
+//   - a scale-out, if another environment was busy when the environment started
+//   - an idle recycle, if the environment that retired most recently, i.e. the one being replaced,
+//     served its last invocation longer than the idle threshold ago
+//
+// Cold starts matching none of these, including cold starts without any environment that
+// retired earlier in the time range, are reported as unknown.
+func GetColdStartCauses(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+	idleThreshold time.Duration,
+) (*sdktypes.ColdStartCausesReturn, error) {
+
+	if idleThreshold <= 0 {
+		idleThreshold = defaultIdleThreshold
+	}
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	var lastModified time.Time
+	if funcConfig.Configuration != nil && funcConfig.Configuration.LastModified != nil {
+		lastModified, err = utils.ParseLastModified(*funcConfig.Configuration.LastModified)
+		if err != nil {
+			fmt.Printf("warn: %v\n", err)
+		}
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+	if len(reports) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only the first %d REPORT lines are analyzed, cold starts after %s are not classified",
+			logsQueryLimit, reports[len(reports)-1].Timestamp.Format(time.RFC3339)))
+	}
+
+	classifier := newColdStartClassifier(reports, lastModified, query.StartTime, idleThreshold)
+
+	result := &sdktypes.ColdStartCausesReturn{
+		Classifications: []sdktypes.ColdStartClassification{},
+		Warnings:        warnings,
+		FunctionName:    query.FunctionName,
+		Qualifier:       query.Qualifier,
+		StartTime:       query.StartTime,
+		EndTime:         query.EndTime,
+	}
+	for _, report := range reports {
+		if !report.IsColdStart() {
+			continue
+		}
+		cause := classifier.classify(report)
+		result.Classifications = append(result.Classifications, sdktypes.ColdStartClassification{
+			RequestID: report.RequestID,
+			LogStream: report.LogStream,
+			Timestamp: initStart(report),
+			Cause:     cause,
+		})
+		switch cause {
+		case sdktypes.ColdStartCauseDeployment:
+			result.Deployment.Count++
+		case sdktypes.ColdStartCauseErrorReplacement:
+			result.ErrorReplacement.Count++
+		case sdktypes.ColdStartCauseScaleOut:
+			result.ScaleOut.Count++
+		case sdktypes.ColdStartCauseIdleRecycle:
+			result.IdleRecycle.Count++
+		default:
+			result.Unknown.Count++
+		}
+	}
+
+	result.ColdStarts = len(result.Classifications)
+	if result.ColdStarts > 0 {
+		total := float64(result.ColdStarts)
+		for _, stats := range []*sdktypes.ColdStartCauseStatistics{
+			&result.Deployment, &result.ErrorReplacement, &result.ScaleOut, &result.IdleRecycle, &result.Unknown,
+		} {
+			stats.Rate = float64(stats.Count) / total
+		}
+	}
+	return result, nil
+}
+
+// initStart returns the start of the init phase of a cold start, or the start of the invocation otherwise.
+func initStart(report logparser.InvocationReport) time.Time {
+	return report.StartTime().Add(-time.Duration(report.ColdStartDurationMs() * float64(time.Millisecond)))
+}
+
+// coldStartClassifier holds the time ordered reports of all environments needed to
+// attribute a cold start to its cause.
+type coldStartClassifier struct {
+	envs          map[string][]logparser.InvocationReport
+	lastReports   []logparser.InvocationReport // Last invocation of every environment, ordered by time
+	lastModified  time.Time
+	windowStart   time.Time
+	idleThreshold time.Duration
+	replaced      map[string]bool // Failed environments that already have been replaced
+}
+
+// newColdStartClassifier indexes the reports, which have to be ordered by time, by environment once.
+func newColdStartClassifier(
+	reports []logparser.InvocationReport,
+	lastModified time.Time,
+	windowStart time.Time,
+	idleThreshold time.Duration,
+) *coldStartClassifier {
+	envs := logparser.GroupByLogStream(reports)
+	lastReports := make([]logparser.InvocationReport, 0, len(envs))
+	for _, envReports := range envs {
+		lastReports = append(lastReports, envReports[len(envReports)-1])
+	}
+	sort.Slice(lastReports, func(i, j int) bool {
+		return lastReports[i].Timestamp.Before(lastReports[j].Timestamp)
+	})
+	return &coldStartClassifier{
+		envs:          envs,
+		lastReports:   lastReports,
+		lastModified:  lastModified,
+		windowStart:   windowStart,
+		idleThreshold: idleThreshold,
+		replaced:      make(map[string]bool),
+	}
+}
+
+// classify returns the cause of the cold start coldStart.
+func (c *coldStartClassifier) classify(coldStart logparser.InvocationReport) sdktypes.ColdStartCause {
+	start := initStart(coldStart)
+
+	if c.isFirstAfterDeployment(coldStart.LogStream, start) {
+		return sdktypes.ColdStartCauseDeployment
+	}
+	if c.replacesFailedEnvironment(coldStart.LogStream, start) {
+		return sdktypes.ColdStartCauseErrorReplacement
+	}
+	if c.otherEnvironmentBusy(coldStart.LogStream, start) {
+		return sdktypes.ColdStartCauseScaleOut
+	}
+
+	// Without an environment that retired before, there is no evidence of an environment that was recycled.
+	retired, ok := c.lastRetired(coldStart.LogStream, start)
+	if ok && start.Sub(retired.Timestamp) > c.idleThreshold {
+		return sdktypes.ColdStartCauseIdleRecycle
+	}
+	return sdktypes.ColdStartCauseUnknown
+}
+
+// lastRetired returns the last invocation of the environment, other than logStream,
+// that served its last invocation most recently before start.
+func (c *coldStartClassifier) lastRetired(logStream string, start time.Time) (logparser.InvocationReport, bool) {
+	idx := sort.Search(len(c.lastReports), func(i int) bool {
+		return !c.lastReports[i].Timestamp.Before(start)
+	})
+	for i := idx - 1; i >= 0; i-- {
+		if c.lastReports[i].LogStream != logStream {
+			return c.lastReports[i], true
+		}
+	}
+	return logparser.InvocationReport{}, false
+}
+
+// isFirstAfterDeployment returns true if the version was modified within the time window
+// and no other environment was started between the modification and start.
+func (c *coldStartClassifier) isFirstAfterDeployment(logStream string, start time.Time) bool {
+	if c.lastModified.IsZero() || c.lastModified.Before(c.windowStart) || !c.lastModified.Before(start) {
+		return false
+	}
+	for stream, reports := range c.envs {
+		if stream == logStream {
+			continue
+		}
+		first := initStart(reports[0])
+		if !first.Before(c.lastModified) && first.Before(start) {
+			return false
+		}
+	}
+	return true
+}
+
+// replacesFailedEnvironment returns true if the invocation before start in the same environment failed,
+// or if another environment ended with a failed invocation within the idle threshold before start
+// and has not been replaced yet.
+func (c *coldStartClassifier) replacesFailedEnvironment(logStream string, start time.Time) bool {
+	reports := c.envs[logStream]
+	idx := sort.Search(len(reports), func(i int) bool {
+		return !reports[i].Timestamp.Before(start)
+	})
+	if idx > 0 && reports[idx-1].Failed() {
+		return true
+	}
+
+	// The most recently failed environment is replaced first.
+	var replaced string
+	var replacedAt time.Time
+	for stream, reports := range c.envs {
+		last := reports[len(reports)-1]
+		if stream == logStream || c.replaced[stream] || !last.Failed() {
+			continue
+		}
+		if last.Timestamp.Before(start) && start.Sub(last.Timestamp) <= c.idleThreshold && last.Timestamp.After(replacedAt) {
+			replaced = stream
+			replacedAt = last.Timestamp
+		}
+	}
+	if replaced == "" {
+		return false
+	}
+	c.replaced[replaced] = true
+	return true
+}
+
+// otherEnvironmentBusy returns true if another environment was initializing or handling a request at start.
+// An environment handles one request at a time, so only its first invocation ending at or after start
+// can overlap start.
+func (c *coldStartClassifier) otherEnvironmentBusy(logStream string, start time.Time) bool {
+	for stream, reports := range c.envs {
+		if stream == logStream {
+			continue
+		}
+		idx := sort.Search(len(reports), func(i int) bool {
+			return !reports[i].Timestamp.Before(start)
+		})
+		if idx < len(reports) && !initStart(reports[idx]).After(start) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetColdStartsByInitType splits the cold starts of an AWS Lambda function over a specified time range
+// and qualifier (version) by the init type of the execution environments: on-demand, provisioned concurrency
+// and SnapStart. The init type is read from the initializationType of the JSON log format, from
+// RESTORE records for SnapStart, and otherwise derived from whether the invocations waited for the init.
+func GetColdStartsByInitType(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.ColdStartsByInitTypeReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+	queryString := fmt.Sprintf(queries.LambdaInitRecordsQueryWithVersion, escapedQualifier)
+	results, err := logsFetcher.RunQuery(ctx, query, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("run logs insights query: %w", err)
+	}
+
+	events := make(map[string][]logparser.InitEvent)
+	var reportRows []map[string]string
+	for _, row := range results {
+		event, ok, err := logparser.ParseInitEvent(row)
+		if err != nil {
+			fmt.Printf("warn: could not parse init record: %v\n", err)
+			continue
+		}
+		if ok {
+			events[event.LogStream] = append(events[event.LogStream], event)
+			continue
+		}
+		reportRows = append(reportRows, row)
+	}
+	envs := logparser.GroupByLogStream(logparser.ParseReports(reportRows))
+	for logStream := range events {
+		if _, ok := envs[logStream]; !ok {
+			envs[logStream] = nil
+		}
+	}
+
+	result := &sdktypes.ColdStartsByInitTypeReturn{
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	durations := make(map[sdktypes.InitType][]float64)
+	for logStream, reports := range envs {
+		initType := environmentInitType(events[logStream], reports)
+		var stats *sdktypes.InitTypeStatistics
+		switch initType {
+		case sdktypes.InitTypeOnDemand:
+			stats = &result.OnDemand
+		case sdktypes.InitTypeProvisionedConcurrency:
+			stats = &result.ProvisionedConcurrency
+		case sdktypes.InitTypeSnapStart:
+			stats = &result.SnapStart
+		default:
+			result.UnknownEnvironments++
+			continue
+		}
+
+		stats.Environments++
+		stats.Invocations += len(reports)
+		for _, report := range reports {
+			if report.IsColdStart() {
+				stats.ColdStarts++
+				durations[initType] = append(durations[initType], report.ColdStartDurationMs())
+			}
+		}
+		// Provisioned concurrency inits are only visible in the init records.
+		if initType == sdktypes.InitTypeProvisionedConcurrency {
+			for _, event := range events[logStream] {
+				if event.DurationMs != nil {
+					durations[initType] = append(durations[initType], *event.DurationMs)
+				}
+			}
+		}
+	}
+
+	for initType, stats := range map[sdktypes.InitType]*sdktypes.InitTypeStatistics{
+		sdktypes.InitTypeOnDemand:               &result.OnDemand,
+		sdktypes.InitTypeProvisionedConcurrency: &result.ProvisionedConcurrency,
+		sdktypes.InitTypeSnapStart:              &result.SnapStart,
+	} {
+		if stats.Invocations > 0 {
+			stats.ColdStartRate = float64(stats.ColdStarts) / float64(stats.Invocations)
+		}
+		stats.InitDuration = summarize(durations[initType])
+	}
+	return result, nil
+}
+
+// environmentInitType determines how an environment was initialized. An explicit initializationType
+// takes precedence, then restore durations point to SnapStart and init durations to on-demand.
+// Environments with an init record but no invocation waiting for it were provisioned ahead of time.
+// An empty InitType is returned if the environment was initialized before the time window.
+func environmentInitType(events []logparser.InitEvent, reports []logparser.InvocationReport) sdktypes.InitType {
+	for _, event := range events {
+		if event.InitType != "" {
+			return sdktypes.InitType(event.InitType)
+		}
+	}
+	for _, report := range reports {
+		if report.RestoreDurationMs != nil {
+			return sdktypes.InitTypeSnapStart
+		}
+	}
+	for _, report := range reports {
+		if report.InitDurationMs != nil {
+			return sdktypes.InitTypeOnDemand
+		}
+	}
+	if len(events) > 0 {
+		return sdktypes.InitTypeProvisionedConcurrency
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// saturationThreshold is the share of the concurrency limit from which on it counts as saturated.
+const saturationThreshold = 0.9
+
+const (
+	concurrencySourceCloudWatch = "cloudwatch"
+	concurrencySourceLogs       = "logs"
+)
+
+// GetConcurrencyProfile analyzes the concurrency of an AWS Lambda function over a specified time range
+// and qualifier (version) against its reserved concurrency or the unreserved concurrency of the account.
+// Throttles are correlated with periods in which the applicable limit was saturated.
+//
+// The peak concurrency per period is read from the ConcurrentExecutions metric. If it has no data for
+// periods with invocations, e.g. because the qualifier has no metrics of its own, the concurrency is
+// estimated from the overlap of the invocations in the REPORT lines instead.
+func GetConcurrencyProfile(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	concurrencyClient sdkinterfaces.ConcurrencyClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.ConcurrencyProfileReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	functionConcurrency, err := concurrencyClient.GetFunctionConcurrency(ctx, &lambda.GetFunctionConcurrencyInput{
+		FunctionName: aws.String(query.FunctionName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function concurrency: %w", err)
+	}
+	accountSettings, err := concurrencyClient.GetAccountSettings(ctx, &lambda.GetAccountSettingsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account settings: %w", err)
+	}
+
+	result := &sdktypes.ConcurrencyProfileReturn{
+		ReservedConcurrency: functionConcurrency.ReservedConcurrentExecutions,
+		ConcurrencySource:   concurrencySourceCloudWatch,
+		ThrottleBursts:      []sdktypes.ThrottleBurst{},
+		FunctionName:        query.FunctionName,
+		Qualifier:           query.Qualifier,
+		StartTime:           query.StartTime,
+		EndTime:             query.EndTime,
+	}
+	if limit := accountSettings.AccountLimit; limit != nil {
+		result.AccountConcurrencyLimit = limit.ConcurrentExecutions
+		result.UnreservedConcurrencyLimit = aws.ToInt32(limit.UnreservedConcurrentExecutions)
+	}
+	result.ConcurrencyLimit = result.UnreservedConcurrencyLimit
+	if result.ReservedConcurrency != nil {
+		result.ConcurrencyLimit = *result.ReservedConcurrency
+	}
+
+	period := seriesPeriod(query)
+	periodDuration := time.Duration(period) * time.Second
+	series := make(map[string][]sdktypes.MetricPoint)
+	for _, metric := range []struct{ name, stat string }{
+		{"ConcurrentExecutions", "Maximum"},
+		{"Invocations", "Sum"},
+		{"Throttles", "Sum"},
+	} {
+		results, err := cwFetcher.FetchMetricSeries(ctx, query, metric.name, metric.stat, period)
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s metric: %w", metric.name, err)
+		}
+		series[metric.name] = metricPoints(results)
+	}
+	unreservedResults, err := cwFetcher.FetchAccountMetricSeries(ctx, query, "UnreservedConcurrentExecutions", "Maximum", period)
+	if err != nil {
+		return nil, fmt.Errorf("fetch UnreservedConcurrentExecutions metric: %w", err)
+	}
+	result.UnreservedConcurrencyOverTime = metricPoints(unreservedResults)
+
+	result.ConcurrencyOverTime = series["ConcurrentExecutions"]
+	if !coversInvocations(result.ConcurrencyOverTime, series["Invocations"], periodDuration) {
+		reports, err := getReports(ctx, logsFetcher, query)
+		if err != nil {
+			return nil, err
+		}
+		result.ConcurrencyOverTime, _ = peakConcurrency(reports, query)
+		result.ConcurrencySource = concurrencySourceLogs
+	}
+	for _, point := range result.ConcurrencyOverTime {
+		if point.Value > result.PeakConcurrency {
+			result.PeakConcurrency = point.Value
+		}
+	}
+	if result.ConcurrencyLimit > 0 {
+		result.Headroom = float64(result.ConcurrencyLimit) - result.PeakConcurrency
+		result.HeadroomRate = result.Headroom / float64(result.ConcurrencyLimit)
+	}
+
+	// Functions without reserved concurrency are throttled when the unreserved pool of the account is exhausted.
+	limitedSeries := result.ConcurrencyOverTime
+	if result.ReservedConcurrency == nil && len(result.UnreservedConcurrencyOverTime) > 0 {
+		limitedSeries = result.UnreservedConcurrencyOverTime
+	}
+	var saturatedThrottles float64
+	for _, point := range series["Throttles"] {
+		if point.Value <= 0 {
+			continue
+		}
+		concurrency, _ := valueInPeriod(limitedSeries, point.Timestamp, periodDuration)
+		burst := sdktypes.ThrottleBurst{
+			Timestamp:       point.Timestamp,
+			Throttles:       point.Value,
+			PeakConcurrency: concurrency,
+			Saturated:       result.ConcurrencyLimit > 0 && concurrency >= saturationThreshold*float64(result.ConcurrencyLimit),
+		}
+		result.ThrottleBursts = append(result.ThrottleBursts, burst)
+		result.Throttles += point.Value
+		if burst.Saturated {
+			saturatedThrottles += point.Value
+		}
+	}
+	if result.Throttles > 0 {
+		result.SaturatedThrottleShare = saturatedThrottles / result.Throttles
+	}
+	return result, nil
+}
+
+// valueInPeriod returns the maximum value of the points within the period starting at start.
+func valueInPeriod(points []sdktypes.MetricPoint, start time.Time, period time.Duration) (float64, bool) {
+	var value float64
+	var found bool
+	end := start.Add(period)
+	for _, point := range points {
+		if point.Timestamp.Before(start) || !point.Timestamp.Before(end) {
+			continue
+		}
+		if !found || point.Value > value {
+			value = point.Value
+		}
+		found = true
+	}
+	return value, found
+}
+
+// coversInvocations returns true if concurrency has a value for every period with invocations.
+func coversInvocations(concurrency, invocations []sdktypes.MetricPoint, period time.Duration) bool {
+	if len(concurrency) == 0 {
+		return false
+	}
+	for _, point := range invocations {
+		if point.Value <= 0 {
+			continue
+		}
+		if _, ok := valueInPeriod(concurrency, point.Timestamp, period); !ok {
+			return false
+		}
+	}
+	return true
+}
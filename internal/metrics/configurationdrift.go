@@ -0,0 +1,325 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetConfigurationDrift compares two configuration snapshots of an AWS Lambda function, e.g. of two qualifiers
+// or of the same qualifier at two points in time. It reports the changes of memory, timeout, runtime, environment
+// variable names, layers, VPC and concurrency settings. If the window is positive, the metrics of the baseline
+// qualifier in the window before the last modification of the target are compared with the metrics of the target
+// qualifier in the window after it.
+func GetConfigurationDrift(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	baseline *sdktypes.BaseStatisticsReturn,
+	target *sdktypes.BaseStatisticsReturn,
+	window time.Duration,
+) (*sdktypes.ConfigurationDriftReturn, error) {
+
+	if baseline == nil || target == nil {
+		return nil, fmt.Errorf("both a baseline and a target snapshot are required")
+	}
+	if baseline.FunctionName != target.FunctionName {
+		return nil, fmt.Errorf("snapshots are of different functions %q and %q", baseline.FunctionName, target.FunctionName)
+	}
+
+	result := &sdktypes.ConfigurationDriftReturn{
+		Warnings:             []string{},
+		FunctionName:         target.FunctionName,
+		BaselineQualifier:    baseline.Qualifier,
+		BaselineLastModified: baseline.LastModified,
+		TargetQualifier:      target.Qualifier,
+		TargetLastModified:   target.LastModified,
+	}
+	var uncomparable []string
+	result.Changes, uncomparable = configurationChanges(baseline, target)
+	result.HasDrift = len(result.Changes) > 0
+	if len(uncomparable) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"values of the environment variables %s are redacted or reported differently in the snapshots, so changes of them cannot be detected; take snapshots with HashSecrets and the same HashKey to compare secrets",
+			strings.Join(uncomparable, ", ")))
+	}
+
+	if window <= 0 {
+		return result, nil
+	}
+	if target.LastModified == "" {
+		result.Warnings = append(result.Warnings, "target snapshot has no last modification date, metric shifts cannot be correlated")
+		return result, nil
+	}
+	changeTime, err := utils.ParseLastModified(target.LastModified)
+	if err != nil {
+		return nil, err
+	}
+	result.MetricShift, err = metricShift(ctx, cwFetcher, invocationsCache, target.FunctionName, baseline.Qualifier, target.Qualifier, changeTime, window)
+	if err != nil {
+		return nil, err
+	}
+	if result.MetricShift.Before.Invocations == 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("no invocations of %s in the window before the change", baseline.Qualifier))
+	}
+	if result.MetricShift.After.Invocations == 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("no invocations of %s in the window after the change", target.Qualifier))
+	}
+	return result, nil
+}
+
+// configurationChanges returns the changes between two snapshots, sorted by field, and the names of
+// environment variables present in both whose values cannot be compared.
+func configurationChanges(baseline, target *sdktypes.BaseStatisticsReturn) ([]sdktypes.ConfigurationChange, []string) {
+	changes := []sdktypes.ConfigurationChange{}
+	add := func(field string, baselineValue, targetValue *string) {
+		if change, ok := configurationChange(field, baselineValue, targetValue); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	add("memorySizeMb", int32String(baseline.MemorySizeMB), int32String(target.MemorySizeMB))
+	add("timeoutSeconds", int32String(baseline.TimeoutSeconds), int32String(target.TimeoutSeconds))
+	add("runtime", nonEmpty(baseline.Runtime), nonEmpty(target.Runtime))
+	add("reservedConcurrency", int32String(baseline.ReservedConcurrency), int32String(target.ReservedConcurrency))
+	add("provisionedConcurrency", int32String(baseline.ProvisionedConcurrency), int32String(target.ProvisionedConcurrency))
+
+	baselineVpc, targetVpc := vpcFields(baseline.VpcConfig), vpcFields(target.VpcConfig)
+	for _, field := range []string{"vpcId", "subnetIds", "securityGroupIds"} {
+		add("vpcConfig."+field, baselineVpc[field], targetVpc[field])
+	}
+
+	baselineLayers, targetLayers := layersByName(baseline.Layers), layersByName(target.Layers)
+	for _, name := range unionKeys(baselineLayers, targetLayers) {
+		add("layers."+name, baselineLayers[name], targetLayers[name])
+	}
+
+	// Values of environment variables are compared but never reported, as they may hold secrets.
+	var uncomparable []string
+	for _, name := range unionKeys(baseline.EnvironmentVariables, target.EnvironmentVariables) {
+		baselineValue, inBaseline := baseline.EnvironmentVariables[name]
+		targetValue, inTarget := target.EnvironmentVariables[name]
+		field := "environmentVariables." + name
+		switch {
+		case !inBaseline:
+			changes = append(changes, sdktypes.ConfigurationChange{Field: field, Kind: sdktypes.ConfigurationChangeAdded})
+		case !inTarget:
+			changes = append(changes, sdktypes.ConfigurationChange{Field: field, Kind: sdktypes.ConfigurationChangeRemoved})
+		case !comparableVisibility(baseline.EnvironmentVariableVisibility[name], target.EnvironmentVariableVisibility[name]):
+			uncomparable = append(uncomparable, name)
+		case baselineValue != targetValue:
+			changes = append(changes, sdktypes.ConfigurationChange{Field: field, Kind: sdktypes.ConfigurationChangeModified})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, uncomparable
+}
+
+// configurationChange returns the change of a field, where nil values are not set.
+// ok is false if the values are equal.
+func configurationChange(field string, baseline, target *string) (sdktypes.ConfigurationChange, bool) {
+	change := sdktypes.ConfigurationChange{Field: field}
+	switch {
+	case baseline == nil && target == nil:
+		return change, false
+	case baseline == nil:
+		change.Kind = sdktypes.ConfigurationChangeAdded
+		change.Target = *target
+	case target == nil:
+		change.Kind = sdktypes.ConfigurationChangeRemoved
+		change.Baseline = *baseline
+	case *baseline == *target:
+		return change, false
+	default:
+		change.Kind = sdktypes.ConfigurationChangeModified
+		change.Baseline = *baseline
+		change.Target = *target
+	}
+	return change, true
+}
+
+// comparableVisibility returns true if values reported with the two visibilities can be compared.
+// Snapshots taken without redaction have no visibility, which means the values are revealed.
+func comparableVisibility(baseline, target sdktypes.EnvironmentVariableVisibility) bool {
+	if baseline == "" {
+		baseline = sdktypes.EnvironmentVariableRevealed
+	}
+	if target == "" {
+		target = sdktypes.EnvironmentVariableRevealed
+	}
+	return baseline == target && baseline != sdktypes.EnvironmentVariableRedacted
+}
+
+// vpcFields returns the settings of a VPC config by field, with lists joined by commas.
+func vpcFields(config *sdktypes.VpcConfiguration) map[string]*string {
+	if config == nil {
+		return map[string]*string{}
+	}
+	return map[string]*string{
+		"vpcId":            nonEmpty(config.VpcID),
+		"subnetIds":        nonEmpty(strings.Join(config.SubnetIDs, ",")),
+		"securityGroupIds": nonEmpty(strings.Join(config.SecurityGroupIDs, ",")),
+	}
+}
+
+// layersByName returns the ARNs of the layers keyed by their ARN without version,
+// such that a new version of a layer is a modification instead of a replacement.
+func layersByName(layers []sdktypes.LayerConfiguration) map[string]*string {
+	byName := make(map[string]*string, len(layers))
+	for _, layer := range layers {
+		arn := layer.Arn
+		name := arn
+		if i := strings.LastIndex(arn, ":"); i >= 0 {
+			if _, err := strconv.Atoi(arn[i+1:]); err == nil {
+				name = arn[:i]
+			}
+		}
+		byName[name] = &arn
+	}
+	return byName
+}
+
+// unionKeys returns the sorted keys present in any of the maps.
+func unionKeys[V any](maps ...map[string]V) []string {
+	set := make(map[string]struct{})
+	for _, m := range maps {
+		for key := range m {
+			set[key] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// int32String formats an optional integer setting.
+func int32String(value *int32) *string {
+	if value == nil {
+		return nil
+	}
+	s := strconv.Itoa(int(*value))
+	return &s
+}
+
+// nonEmpty returns nil for empty strings, which are settings that are not configured.
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// metricShift compares the metrics of the baseline qualifier in the window before the change
+// with the metrics of the target qualifier in the window after it.
+func metricShift(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	functionName, baselineQualifier, targetQualifier string,
+	changeTime time.Time,
+	window time.Duration,
+) (*sdktypes.MetricShift, error) {
+	afterEnd := changeTime.Add(window)
+	if now := time.Now(); afterEnd.After(now) {
+		afterEnd = now
+	}
+	before, err := getMetricWindow(ctx, cwFetcher, invocationsCache, sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    baselineQualifier,
+		StartTime:    changeTime.Add(-window),
+		EndTime:      changeTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("before change: %w", err)
+	}
+	after, err := getMetricWindow(ctx, cwFetcher, invocationsCache, sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    targetQualifier,
+		StartTime:    changeTime,
+		EndTime:      afterEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("after change: %w", err)
+	}
+
+	shift := &sdktypes.MetricShift{
+		ChangeTime:           changeTime,
+		Before:               *before,
+		After:                *after,
+		ErrorRateChange:      after.ErrorRate - before.ErrorRate,
+		ThrottleRateChange:   after.ThrottleRate - before.ThrottleRate,
+		MeanDurationChangeMs: after.MeanDurationMs - before.MeanDurationMs,
+	}
+	if before.MeanDurationMs > 0 {
+		relative := shift.MeanDurationChangeMs / before.MeanDurationMs
+		shift.RelativeMeanDurationChange = &relative
+	}
+	return shift, nil
+}
+
+// getMetricWindow returns the invocations, errors, throttles and the mean duration of a function qualifier
+// in the query interval. Rates and the mean duration are 0 if there are no invocations.
+func getMetricWindow(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.MetricWindowStatistics, error) {
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	sums := make(map[string]float64)
+	for _, metricName := range []string{"Errors", "Throttles", "Duration"} {
+		results, err := cwFetcher.FetchMetric(ctx, query, metricName, "Sum")
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s metric: %w", strings.ToLower(metricName), err)
+		}
+		sums[metricName], err = utils.SumMetricValues(results)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s metric data: %w", strings.ToLower(metricName), err)
+		}
+	}
+
+	stats := &sdktypes.MetricWindowStatistics{
+		Invocations: int(invocationsSum),
+		Errors:      int(sums["Errors"]),
+		Throttles:   int(sums["Throttles"]),
+		Qualifier:   query.Qualifier,
+		StartTime:   query.StartTime,
+		EndTime:     query.EndTime,
+	}
+	if invocationsSum > 0 {
+		stats.ErrorRate = sums["Errors"] / invocationsSum
+		stats.ThrottleRate = sums["Throttles"] / invocationsSum
+		stats.MeanDurationMs = sums["Duration"] / invocationsSum
+	}
+	return stats, nil
+}
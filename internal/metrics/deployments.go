@@ -0,0 +1,312 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// updateFunctionCodeEvent is the name of the CloudTrail event of UpdateFunctionCode.
+const updateFunctionCodeEvent = "UpdateFunctionCode20150331v2"
+
+// cloudTrailUpdateFunctionCode holds the fields of an UpdateFunctionCode CloudTrail event that are used.
+type cloudTrailUpdateFunctionCode struct {
+	RequestParameters struct {
+		FunctionName string `json:"functionName"`
+	} `json:"requestParameters"`
+	ResponseElements struct {
+		FunctionName string `json:"functionName"`
+		CodeSha256   string `json:"codeSha256"`
+	} `json:"responseElements"`
+}
+
+// GetDeploymentTimeline returns the code deployments of an AWS Lambda function that were active within a specified
+// time range. Deployments are read from the last modification of the published versions and of $LATEST, and from
+// UpdateFunctionCode events in CloudTrail where available. Entries with the same code as the previous one,
+// e.g. publishing a version or updating the configuration, are merged into the earlier one.
+func GetDeploymentTimeline(
+	ctx context.Context,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	cloudTrailClient sdkinterfaces.CloudTrailClient,
+	functionName string,
+	startTime, endTime time.Time,
+) (*sdktypes.DeploymentTimelineReturn, error) {
+
+	versions, err := listFunctionVersions(ctx, versionsClient, functionName)
+	if err != nil {
+		return nil, err
+	}
+	result := &sdktypes.DeploymentTimelineReturn{
+		Deployments:  []sdktypes.Deployment{},
+		Warnings:     []string{},
+		FunctionName: functionName,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	candidates := []sdktypes.Deployment{}
+	for _, version := range versions {
+		lastModified, err := utils.ParseLastModified(aws.ToString(version.LastModified))
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+			continue
+		}
+		deployment := sdktypes.Deployment{
+			Time:       lastModified,
+			CodeSha256: aws.ToString(version.CodeSha256),
+			Source:     sdktypes.DeploymentSourceLastModified,
+		}
+		if qualifier := aws.ToString(version.Version); qualifier != "$LATEST" {
+			deployment.Version = qualifier
+			deployment.Source = sdktypes.DeploymentSourceVersion
+		}
+		candidates = append(candidates, deployment)
+	}
+	codeUpdates, err := lookupCodeUpdates(ctx, cloudTrailClient, functionResourceNames(functionName, versions), functionName, startTime, endTime)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("CloudTrail events are not available, deployments are only read from the versions: %v", err))
+	}
+	candidates = append(candidates, codeUpdates...)
+	if len(versions) <= 1 && len(codeUpdates) == 0 {
+		result.Warnings = append(result.Warnings,
+			"without published versions or CloudTrail events only the last modification of $LATEST is known, which can also be a configuration update")
+	}
+
+	for _, deployment := range mergeDeployments(candidates) {
+		if deployment.Time.After(endTime) {
+			break
+		}
+		// Only the last deployment before the start time is kept, as it was active at the start.
+		if !deployment.Time.After(startTime) && len(result.Deployments) > 0 {
+			result.Deployments = result.Deployments[:0]
+		}
+		result.Deployments = append(result.Deployments, deployment)
+	}
+	return result, nil
+}
+
+// lookupCodeUpdates returns the UpdateFunctionCode events of a function in the time range from CloudTrail.
+// Events are looked up by resource name, once for the name and once for the ARN of the function, as CloudTrail
+// records the function the way it was referred to in the request. This way only the events of the function
+// are read instead of the UpdateFunctionCode events of all functions in the account.
+func lookupCodeUpdates(
+	ctx context.Context,
+	cloudTrailClient sdkinterfaces.CloudTrailClient,
+	resourceNames []string,
+	functionName string,
+	startTime, endTime time.Time,
+) ([]sdktypes.Deployment, error) {
+	deployments := []sdktypes.Deployment{}
+	seen := make(map[string]bool)
+	for _, resourceName := range resourceNames {
+		var nextToken *string
+		for {
+			output, err := cloudTrailClient.LookupEvents(ctx, &cloudtrail.LookupEventsInput{
+				LookupAttributes: []cloudtrailtypes.LookupAttribute{{
+					AttributeKey:   cloudtrailtypes.LookupAttributeKeyResourceName,
+					AttributeValue: aws.String(resourceName),
+				}},
+				StartTime: aws.Time(startTime),
+				EndTime:   aws.Time(endTime),
+				NextToken: nextToken,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up cloudtrail events: %w", err)
+			}
+			for _, event := range output.Events {
+				if aws.ToString(event.EventName) != updateFunctionCodeEvent || event.EventTime == nil {
+					continue
+				}
+				// Events are found once per resource name if the request referred to the function by both.
+				if eventID := aws.ToString(event.EventId); eventID != "" {
+					if seen[eventID] {
+						continue
+					}
+					seen[eventID] = true
+				}
+				var details cloudTrailUpdateFunctionCode
+				if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &details); err != nil {
+					continue
+				}
+				if !sameFunction(details.RequestParameters.FunctionName, functionName) &&
+					!sameFunction(details.ResponseElements.FunctionName, functionName) {
+					continue
+				}
+				deployments = append(deployments, sdktypes.Deployment{
+					Time:       event.EventTime.UTC(),
+					CodeSha256: details.ResponseElements.CodeSha256,
+					Source:     sdktypes.DeploymentSourceCloudTrail,
+					User:       aws.ToString(event.Username),
+				})
+			}
+			if output.NextToken == nil || *output.NextToken == "" {
+				break
+			}
+			nextToken = output.NextToken
+		}
+	}
+	return deployments, nil
+}
+
+// functionResourceNames returns the names CloudTrail can record a function by: its name and its unqualified ARN,
+// which is read from the versions if any are listed.
+func functionResourceNames(functionName string, versions []lambdatypes.FunctionConfiguration) []string {
+	names := []string{functionName}
+	for _, version := range versions {
+		arn := aws.ToString(version.FunctionArn)
+		parts := strings.Split(arn, ":function:")
+		if len(parts) != 2 {
+			continue
+		}
+		// Versions have qualified ARNs, e.g. arn:aws:lambda:us-east-1:123456789012:function:my-function:1.
+		unqualified := parts[0] + ":function:" + strings.Split(parts[1], ":")[0]
+		if unqualified != functionName {
+			names = append(names, unqualified)
+		}
+		break
+	}
+	return names
+}
+
+// sameFunction returns true if the function name or ARN of an event refers to the function.
+func sameFunction(eventFunction, functionName string) bool {
+	if eventFunction == functionName {
+		return true
+	}
+	// ARNs can be qualified, e.g. arn:aws:lambda:us-east-1:123456789012:function:my-function:$LATEST.
+	parts := strings.Split(eventFunction, ":function:")
+	return len(parts) == 2 && strings.Split(parts[1], ":")[0] == functionName
+}
+
+// deploymentSourceOrder decides which source is kept if deployments happened at the same time,
+// CloudTrail events being the most precise.
+var deploymentSourceOrder = map[sdktypes.DeploymentSource]int{
+	sdktypes.DeploymentSourceCloudTrail:   0,
+	sdktypes.DeploymentSourceVersion:      1,
+	sdktypes.DeploymentSourceLastModified: 2,
+}
+
+// mergeDeployments sorts the deployments by time and merges deployments with the same code as the previous one
+// into it, as they did not change the code. The version of a merged published version is kept.
+func mergeDeployments(candidates []sdktypes.Deployment) []sdktypes.Deployment {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if !candidates[i].Time.Equal(candidates[j].Time) {
+			return candidates[i].Time.Before(candidates[j].Time)
+		}
+		return deploymentSourceOrder[candidates[i].Source] < deploymentSourceOrder[candidates[j].Source]
+	})
+	merged := []sdktypes.Deployment{}
+	for _, deployment := range candidates {
+		if last := len(merged) - 1; last >= 0 && deployment.CodeSha256 != "" && deployment.CodeSha256 == merged[last].CodeSha256 {
+			if merged[last].Version == "" {
+				merged[last].Version = deployment.Version
+			}
+			continue
+		}
+		merged = append(merged, deployment)
+	}
+	return merged
+}
+
+// deploymentWindow is the part of a time range in which one code revision was deployed.
+type deploymentWindow struct {
+	deployment *sdktypes.Deployment
+	start, end time.Time
+}
+
+// deploymentWindows splits the time range at the deployments of the timeline.
+func deploymentWindows(deployments []sdktypes.Deployment, startTime, endTime time.Time) []deploymentWindow {
+	windows := []deploymentWindow{{start: startTime, end: endTime}}
+	for i := range deployments {
+		deployment := &deployments[i]
+		current := &windows[len(windows)-1]
+		if !deployment.Time.After(startTime) {
+			current.deployment = deployment
+			continue
+		}
+		if !deployment.Time.Before(endTime) {
+			break
+		}
+		current.end = deployment.Time
+		windows = append(windows, deploymentWindow{deployment: deployment, start: deployment.Time, end: endTime})
+	}
+	return windows
+}
+
+// SplitByDeployments splits the time range of a query of $LATEST at the deployments of the function and
+// calculates the metric for every code revision separately. Revisions without invocations are marked instead of
+// failing the split. Published versions are immutable, so only $LATEST can be split.
+func SplitByDeployments[T any](
+	ctx context.Context,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	cloudTrailClient sdkinterfaces.CloudTrailClient,
+	query sdktypes.FunctionQuery,
+	metric func(ctx context.Context, query sdktypes.FunctionQuery) (T, error),
+) (*sdktypes.DeploymentSplitReturn[T], error) {
+
+	if query.Qualifier != "$LATEST" {
+		return nil, fmt.Errorf("only $LATEST can be split by deployments, version %q is immutable", query.Qualifier)
+	}
+	timeline, err := GetDeploymentTimeline(ctx, versionsClient, cloudTrailClient, query.FunctionName, query.StartTime, query.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sdktypes.DeploymentSplitReturn[T]{
+		Segments:     []sdktypes.DeploymentSegment[T]{},
+		Warnings:     timeline.Warnings,
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	for _, window := range deploymentWindows(timeline.Deployments, query.StartTime, query.EndTime) {
+		segmentQuery := query
+		segmentQuery.StartTime = window.start
+		segmentQuery.EndTime = window.end
+		segment := sdktypes.DeploymentSegment[T]{
+			Deployment: window.deployment,
+			StartTime:  window.start,
+			EndTime:    window.end,
+		}
+		value, err := metric(ctx, segmentQuery)
+		var noInvocations *sdkerrors.NoInvocationsError
+		switch {
+		case errors.As(err, &noInvocations):
+			segment.NoInvocations = true
+		case err != nil:
+			return nil, fmt.Errorf("segment from %s: %w", window.start.Format(time.RFC3339), err)
+		default:
+			segment.Result = value
+		}
+		result.Segments = append(result.Segments, segment)
+	}
+	return result, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetEndToEndLatencyStatistics calculates the latency callers of an AWS Lambda function experience
+// over a specified time range and qualifier (version). Unlike GetDurationStatistics, the init or
+// restore duration is added to the duration of cold starts.
+func GetEndToEndLatencyStatistics(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.EndToEndLatencyReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+	if len(reports) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only the first %d REPORT lines are analyzed, the latency of invocations after %s is missing",
+			logsQueryLimit, reports[len(reports)-1].Timestamp.Format(time.RFC3339)))
+	}
+
+	var all, warm, cold, durations []float64
+	for _, report := range reports {
+		latency := report.DurationMs + report.ColdStartDurationMs()
+		all = append(all, latency)
+		durations = append(durations, report.DurationMs)
+		if report.IsColdStart() {
+			cold = append(cold, latency)
+		} else {
+			warm = append(warm, latency)
+		}
+	}
+
+	result := &sdktypes.EndToEndLatencyReturn{
+		All:          summarize(all),
+		Warm:         summarize(warm),
+		Cold:         summarize(cold),
+		Warnings:     warnings,
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	if result.All == nil || result.All.P99 == nil {
+		return result, nil
+	}
+
+	p99 := *result.All.P99
+	var tailInvocations, tailColdStarts int
+	var tailLatency, tailInit float64
+	for _, report := range reports {
+		latency := report.DurationMs + report.ColdStartDurationMs()
+		if latency < p99 {
+			continue
+		}
+		tailInvocations++
+		tailLatency += latency
+		tailInit += report.ColdStartDurationMs()
+		if report.IsColdStart() {
+			tailColdStarts++
+		}
+	}
+	coldShare := float64(tailColdStarts) / float64(tailInvocations)
+	result.ColdStartShareOfP99Tail = &coldShare
+	if tailLatency > 0 {
+		initShare := tailInit / tailLatency
+		result.InitTimeShareOfP99Tail = &initShare
+	}
+	if durationStats := summarize(durations); durationStats != nil && durationStats.P99 != nil {
+		increase := p99 - *durationStats.P99
+		result.P99IncreaseFromColdStarts = &increase
+	}
+	return result, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetEnvironmentLifecycleStatistics analyzes the lifecycle of the execution environments of an AWS Lambda
+// function over a specified time range and qualifier (version). Every log stream is treated as one environment.
+// It reports how many invocations environments serve before they are recycled, how long they live,
+// how long they idle between invocations, how much of their time is spent in init and
+// how many environments were alive over time.
+func GetEnvironmentLifecycleStatistics(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.EnvironmentLifecycleReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+	if len(reports) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only the first %d REPORT lines are analyzed, environments and invocations after %s are missing",
+			logsQueryLimit, reports[len(reports)-1].Timestamp.Format(time.RFC3339)))
+	}
+
+	environments := []sdktypes.EnvironmentLifecycle{}
+	var invocations, lifetimes, idleGaps []float64
+	var initTotal, busyTotal float64
+	for logStream, envReports := range logparser.GroupByLogStream(reports) {
+		env, gaps := environmentLifecycle(logStream, envReports)
+		environments = append(environments, env)
+		invocations = append(invocations, float64(env.Invocations))
+		lifetimes = append(lifetimes, env.LifetimeSeconds)
+		idleGaps = append(idleGaps, gaps...)
+		initTotal += env.InitDurationMs
+		busyTotal += env.BusyDurationMs
+	}
+	sort.Slice(environments, func(i, j int) bool {
+		if !environments[i].FirstSeen.Equal(environments[j].FirstSeen) {
+			return environments[i].FirstSeen.Before(environments[j].FirstSeen)
+		}
+		return environments[i].LogStream < environments[j].LogStream
+	})
+
+	var initFraction float64
+	if initTotal+busyTotal > 0 {
+		initFraction = initTotal / (initTotal + busyTotal)
+	}
+	concurrent := concurrentEnvironments(environments, query)
+	var peak int
+	for _, point := range concurrent {
+		if point.Environments > peak {
+			peak = point.Environments
+		}
+	}
+
+	return &sdktypes.EnvironmentLifecycleReturn{
+		EnvironmentCount:           len(environments),
+		InvocationsPerEnvironment:  summarize(invocations),
+		LifetimeSeconds:            summarize(lifetimes),
+		IdleGapSeconds:             summarize(idleGaps),
+		InitTimeFraction:           initFraction,
+		ConcurrentEnvironments:     concurrent,
+		PeakConcurrentEnvironments: peak,
+		Environments:               environments,
+		Warnings:                   warnings,
+		FunctionName:               query.FunctionName,
+		Qualifier:                  query.Qualifier,
+		StartTime:                  query.StartTime,
+		EndTime:                    query.EndTime,
+	}, nil
+}
+
+// environmentLifecycle summarizes the time ordered reports of one environment.
+// It additionally returns the idle gaps between consecutive invocations in seconds.
+func environmentLifecycle(logStream string, reports []logparser.InvocationReport) (sdktypes.EnvironmentLifecycle, []float64) {
+	env := sdktypes.EnvironmentLifecycle{
+		LogStream:   logStream,
+		Invocations: len(reports),
+	}
+	gaps := make([]float64, 0, len(reports))
+	for i, report := range reports {
+		env.InitDurationMs += report.ColdStartDurationMs()
+		env.BusyDurationMs += report.DurationMs
+		if i > 0 {
+			gap := report.StartTime().Sub(reports[i-1].Timestamp).Seconds()
+			if gap < 0 {
+				gap = 0
+			}
+			gaps = append(gaps, gap)
+			if gap > env.MaxIdleGapSeconds {
+				env.MaxIdleGapSeconds = gap
+			}
+		}
+	}
+
+	first := reports[0]
+	env.FirstSeen = first.StartTime().Add(-time.Duration(first.ColdStartDurationMs() * float64(time.Millisecond)))
+	env.LastSeen = reports[len(reports)-1].Timestamp
+	env.LifetimeSeconds = env.LastSeen.Sub(env.FirstSeen).Seconds()
+	if env.InitDurationMs+env.BusyDurationMs > 0 {
+		env.InitTimeFraction = env.InitDurationMs / (env.InitDurationMs + env.BusyDurationMs)
+	}
+	return env, gaps
+}
+
+// concurrentEnvironments counts the environments alive in every time bucket of the query interval.
+// An environment is alive from its first until its last observed activity.
+// Buckets are aligned to the start of the query, like the memory usage over time.
+func concurrentEnvironments(environments []sdktypes.EnvironmentLifecycle, query sdktypes.FunctionQuery) []sdktypes.EnvironmentCountPoint {
+	bucketSize := utils.BucketSize(query.StartTime, query.EndTime, timeSeriesBuckets)
+	points := []sdktypes.EnvironmentCountPoint{}
+	for bucket := query.StartTime; bucket.Before(query.EndTime); bucket = bucket.Add(bucketSize) {
+		bucketEnd := bucket.Add(bucketSize)
+		var count int
+		for _, env := range environments {
+			if env.FirstSeen.Before(bucketEnd) && !env.LastSeen.Before(bucket) {
+				count++
+			}
+		}
+		points = append(points, sdktypes.EnvironmentCountPoint{Timestamp: bucket, Environments: count})
+	}
+	return points
+}
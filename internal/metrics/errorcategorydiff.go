@@ -0,0 +1,148 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetErrorCategoryDiff compares the error categories of two function queries.
+// The queries can either target two qualifiers (e.g. the previous and the newly deployed version)
+// or the same qualifier in two time windows. Error counts are normalized by the invocations
+// of the respective query, such that windows with different traffic can be compared.
+func GetErrorCategoryDiff(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	baseline sdktypes.FunctionQuery,
+	target sdktypes.FunctionQuery,
+) (*sdktypes.ErrorCategoryDiffReturn, error) {
+
+	baselineInvocations, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	if baselineInvocations == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: baseline.FunctionName}
+	}
+	targetInvocations, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, target)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+	if targetInvocations == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: target.FunctionName}
+	}
+
+	baselineErrors, err := GetErrorTypes(ctx, logsFetcher, cwFetcher, invocationsCache, baseline)
+	if err != nil {
+		return nil, fmt.Errorf("baseline error categories: %w", err)
+	}
+	targetErrors, err := GetErrorTypes(ctx, logsFetcher, cwFetcher, invocationsCache, target)
+	if err != nil {
+		return nil, fmt.Errorf("target error categories: %w", err)
+	}
+
+	baselineCounts := countsByCategory(baselineErrors.Errors)
+	targetCounts := countsByCategory(targetErrors.Errors)
+
+	categories := make(map[string]struct{})
+	for category := range baselineCounts {
+		categories[category] = struct{}{}
+	}
+	for category := range targetCounts {
+		categories[category] = struct{}{}
+	}
+
+	result := &sdktypes.ErrorCategoryDiffReturn{
+		FunctionName:        target.FunctionName,
+		BaselineQualifier:   baseline.Qualifier,
+		BaselineStartTime:   baseline.StartTime,
+		BaselineEndTime:     baseline.EndTime,
+		BaselineInvocations: int(baselineInvocations),
+		TargetQualifier:     target.Qualifier,
+		TargetStartTime:     target.StartTime,
+		TargetEndTime:       target.EndTime,
+		TargetInvocations:   int(targetInvocations),
+		New:                 []sdktypes.ErrorCategoryChange{},
+		Resolved:            []sdktypes.ErrorCategoryChange{},
+		Increased:           []sdktypes.ErrorCategoryChange{},
+		Decreased:           []sdktypes.ErrorCategoryChange{},
+	}
+
+	for category := range categories {
+		baselineCount := baselineCounts[category]
+		targetCount := targetCounts[category]
+		change := sdktypes.ErrorCategoryChange{
+			ErrorCategory: category,
+			BaselineCount: baselineCount,
+			TargetCount:   targetCount,
+			BaselineRate:  float64(baselineCount) / baselineInvocations,
+			TargetRate:    float64(targetCount) / targetInvocations,
+		}
+		change.RateChange = change.TargetRate - change.BaselineRate
+		if change.BaselineRate > 0 {
+			relative := change.RateChange / change.BaselineRate
+			change.RelativeRateChange = &relative
+		}
+
+		switch {
+		case baselineCount == 0 && targetCount > 0:
+			result.New = append(result.New, change)
+		case baselineCount > 0 && targetCount == 0:
+			result.Resolved = append(result.Resolved, change)
+		case change.RateChange > 0:
+			result.Increased = append(result.Increased, change)
+		case change.RateChange < 0:
+			result.Decreased = append(result.Decreased, change)
+		}
+	}
+
+	for _, changes := range [][]sdktypes.ErrorCategoryChange{result.New, result.Resolved, result.Increased, result.Decreased} {
+		sortByRateChange(changes)
+	}
+	result.HasNewErrors = len(result.New) > 0
+
+	return result, nil
+}
+
+// countsByCategory sums up the error counts per category, as the same category
+// can in theory be returned multiple times.
+func countsByCategory(errorTypes []sdktypes.ErrorType) map[string]int {
+	counts := make(map[string]int)
+	for _, errorType := range errorTypes {
+		counts[errorType.ErrorCategory] += errorType.ErrorCount
+	}
+	return counts
+}
+
+// sortByRateChange sorts the changes by the absolute rate change in descending order,
+// such that the most significant changes come first.
+func sortByRateChange(changes []sdktypes.ErrorCategoryChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		di, dj := math.Abs(changes[i].RateChange), math.Abs(changes[j].RateChange)
+		if di != dj {
+			return di > dj
+		}
+		return changes[i].ErrorCategory < changes[j].ErrorCategory
+	})
+}
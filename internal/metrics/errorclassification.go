@@ -0,0 +1,155 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetErrorClassification classifies the failed invocations of an AWS Lambda function
+// over a specified time range and qualifier (version).
+// The classes are derived from the REPORT and platform records of each invocation instead of
+// matching the word "ERROR", such that every class can be reported with its own rate.
+func GetErrorClassification(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.ErrorClassificationReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	errorsResults, err := cwFetcher.FetchMetric(ctx, query, "Errors", "Sum")
+	if err != nil {
+		return nil, fmt.Errorf("fetch errors metric: %w", err)
+	}
+	errorsSum, err := utils.SumMetricValues(errorsResults)
+	if err != nil {
+		return nil, fmt.Errorf("parse errors metric data: %w", err)
+	}
+
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+	queryString := fmt.Sprintf(queries.LambdaErrorSignalsPerRequestWithVersion, escapedQualifier)
+	results, err := logsFetcher.RunQuery(ctx, query, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("run logs insights query: %w", err)
+	}
+
+	warnings := []string{}
+	if len(results) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only %d invocations with error signals are classified, the classes derived from logs are undercounted", logsQueryLimit))
+	}
+
+	// Every invocation is assigned to the most severe class it shows signals of. Invocations that only logged
+	// errors succeeded if their status is logged as success, otherwise they may be handled errors.
+	var outOfMemory, exitErrors, timeouts, unhandled, loggedSucceeded, loggedUnknown int
+	for _, row := range results {
+		switch {
+		case signalCount(row, "outOfMemoryLines") > 0:
+			outOfMemory++
+		case signalCount(row, "exitErrorLines") > 0:
+			exitErrors++
+		case signalCount(row, "timeoutLines") > 0:
+			timeouts++
+		case signalCount(row, "unhandledErrorLines") > 0:
+			unhandled++
+		case signalCount(row, "failedStatusLines") > 0:
+			// The invocation logged errors and failed without a platform or unhandled exception signal,
+			// so the handler returned the error. It is counted as handled error from the Errors metric
+			// below and must not be counted as logged only error.
+		case signalCount(row, "errorLogLines") > 0 && signalCount(row, "successStatusLines") > 0:
+			loggedSucceeded++
+		case signalCount(row, "errorLogLines") > 0:
+			loggedUnknown++
+		}
+	}
+
+	queryString = fmt.Sprintf(queries.LambdaInitFailuresWithVersion, escapedQualifier)
+	results, err = logsFetcher.RunQuery(ctx, query, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("run logs insights query: %w", err)
+	}
+	var initFailures int
+	if len(results) > 0 {
+		initFailures = signalCount(results[0], "initFailures")
+	}
+
+	functionErrors := int(errorsSum)
+	// Function errors which do not show any platform or unhandled exception signal
+	// were returned by the handler itself.
+	handled := functionErrors - outOfMemory - exitErrors - timeouts - unhandled
+	if handled < 0 {
+		handled = 0
+	}
+	// Without a logged status, handled errors are assumed to be among the invocations that logged errors,
+	// so that no invocation is counted as both.
+	loggedOnly := loggedSucceeded + max(loggedUnknown-handled, 0)
+
+	classStats := func(count int) sdktypes.ErrorClassStatistics {
+		return sdktypes.ErrorClassStatistics{
+			Count: count,
+			Rate:  float64(count) / invocationsSum,
+		}
+	}
+
+	return &sdktypes.ErrorClassificationReturn{
+		Invocations:       int(invocationsSum),
+		FunctionErrors:    classStats(functionErrors),
+		HandledErrors:     classStats(handled),
+		UnhandledErrors:   classStats(unhandled),
+		RuntimeExitErrors: classStats(exitErrors),
+		OutOfMemoryErrors: classStats(outOfMemory),
+		InitFailures:      classStats(initFailures),
+		SandboxTimeouts:   classStats(timeouts),
+		LoggedOnlyErrors:  classStats(loggedOnly),
+		Warnings:          warnings,
+		FunctionName:      query.FunctionName,
+		Qualifier:         query.Qualifier,
+		StartTime:         query.StartTime,
+		EndTime:           query.EndTime,
+	}, nil
+}
+
+// signalCount parses a count field of a Logs Insights row.
+// Missing or invalid values are treated as zero.
+func signalCount(row map[string]string, field string) int {
+	val, ok := row[field]
+	if !ok || val == "" {
+		return 0
+	}
+	count, err := strconv.Atoi(val)
+	if err != nil {
+		fmt.Printf("warn: could not parse %s %q: %v\n", field, val, err)
+		return 0
+	}
+	return count
+}
@@ -0,0 +1,404 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	// fallingBehindRSquared is the minimum coefficient of determination of a linear fit through
+	// a growing lag, from which on a consumer is considered to be falling behind.
+	fallingBehindRSquared = 0.5
+	// batchDurationWarningThreshold is the share of the timeout from which on batch durations are warned about.
+	batchDurationWarningThreshold = 0.8
+	// smallBatchThreshold is the share of the batch size below which batches are considered small.
+	smallBatchThreshold = 0.1
+)
+
+// recordKeys are keys that occur exactly once in every record of a logged event of the source type.
+// Kinesis records use camel case, DynamoDB stream records Pascal case for the sequence number.
+var recordKeys = map[sdktypes.EventSourceType]string{
+	sdktypes.EventSourceTypeSQS:             "receiptHandle",
+	sdktypes.EventSourceTypeKinesis:         "sequenceNumber",
+	sdktypes.EventSourceTypeDynamoDBStreams: "SequenceNumber",
+	sdktypes.EventSourceTypeKafka:           "timestampType",
+}
+
+// batchItemFailureSourceTypes are the source types whose mappings support ReportBatchItemFailures.
+// Other sources, e.g. Amazon MQ, always retry the whole batch.
+var batchItemFailureSourceTypes = map[sdktypes.EventSourceType]bool{
+	sdktypes.EventSourceTypeSQS:             true,
+	sdktypes.EventSourceTypeKinesis:         true,
+	sdktypes.EventSourceTypeDynamoDBStreams: true,
+	sdktypes.EventSourceTypeKafka:           true,
+}
+
+// GetEventSourceMappingStatistics lists the event source mappings of an AWS Lambda function and qualifier (version)
+// and calculates their lag, batch durations and records per invocation over a specified time range.
+// Records per invocation are counted in the logged events, so they are only available if the function
+// logs the event it receives and no other mapping of the function has the same source type.
+// A mapping is falling behind if its lag grows steadily over the time range. Warnings are added for
+// mappings falling behind and for batch size, batching window and failure reporting settings that
+// do not fit the observed batches.
+//
+// Unlike the other metrics, no NoInvocationsError is returned without invocations,
+// as a consumer that is stuck is one of the cases the statistics are meant to show.
+func GetEventSourceMappingStatistics(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	eventSourceMappingClient sdkinterfaces.EventSourceMappingClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.EventSourceMappingStatisticsReturn, error) {
+
+	mappings, err := listEventSourceMappings(ctx, eventSourceMappingClient, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sdktypes.EventSourceMappingStatisticsReturn{
+		Mappings:     []sdktypes.EventSourceMappingStatistics{},
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    query.StartTime,
+		EndTime:      query.EndTime,
+	}
+	if len(mappings) == 0 {
+		return result, nil
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	if funcConfig.Configuration != nil && funcConfig.Configuration.Timeout != nil {
+		result.TimeoutSeconds = *funcConfig.Configuration.Timeout
+	}
+
+	result.Invocations, err = getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	errorsResults, err := cwFetcher.FetchMetric(ctx, query, "Errors", "Sum")
+	if err != nil {
+		return nil, fmt.Errorf("fetch Errors metric: %w", err)
+	}
+	result.FunctionErrors, err = utils.SumMetricValues(errorsResults)
+	if err != nil {
+		return nil, fmt.Errorf("parse Errors metric data: %w", err)
+	}
+
+	if result.Invocations > 0 {
+		reports, err := getReports(ctx, logsFetcher, query)
+		if err != nil {
+			return nil, err
+		}
+		durations := []float64{}
+		for _, report := range reports {
+			durations = append(durations, report.DurationMs)
+		}
+		result.BatchDuration = summarize(durations)
+	}
+
+	sourceTypes := map[sdktypes.EventSourceType]int{}
+	for _, mapping := range mappings {
+		sourceTypes[eventSourceType(mapping)]++
+	}
+
+	for _, mapping := range mappings {
+		stats, err := eventSourceMappingStatistics(ctx, cwFetcher, query, mapping)
+		if err != nil {
+			return nil, err
+		}
+		stats.Warnings = eventSourceMappingWarnings(stats, result)
+		// IteratorAge and OffsetLag are only emitted per function, not per mapping.
+		if stats.SourceType != sdktypes.EventSourceTypeSQS && stats.LagMetric != "" && sourceTypes[stats.SourceType] > 1 {
+			stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+				"%s is reported per function, the lag is the maximum of all %d %s mappings and may belong to another one",
+				stats.LagMetric, sourceTypes[stats.SourceType], stats.SourceType))
+		}
+		// Logged records can only be attributed to a mapping if no other mapping has the same source type.
+		if key, ok := recordKeys[stats.SourceType]; ok && sourceTypes[stats.SourceType] == 1 && result.Invocations > 0 {
+			recordsPerInvocation, loggedInvocations, truncated, err := loggedRecordsPerInvocation(ctx, logsFetcher, query, key)
+			if err != nil {
+				return nil, err
+			}
+			stats.RecordsPerInvocation = recordsPerInvocation
+			stats.RecordsLoggedInvocations = loggedInvocations
+			if recordsPerInvocation != nil {
+				if warning, ok := smallBatchWarning(stats); ok {
+					stats.Warnings = append(stats.Warnings, warning)
+				}
+			}
+			if truncated {
+				stats.Warnings = append(stats.Warnings, fmt.Sprintf(
+					"records per invocation are based on the first %d invocations that logged records", logsQueryLimit))
+			}
+		}
+		result.Mappings = append(result.Mappings, stats)
+	}
+	return result, nil
+}
+
+// loggedRecordsPerInvocation counts the records in the events the function logged and returns their
+// average over the invocations with log lines containing the key, together with the number of these
+// invocations. The average is nil if no log line contains the key. It also reports whether the
+// query hit its limit.
+func loggedRecordsPerInvocation(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	query sdktypes.FunctionQuery,
+	key string,
+) (*float64, int, bool, error) {
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+	// The key is used in string literals of the query, the length of the unescaped key is the one matched.
+	escapedKey := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key)
+	queryString := fmt.Sprintf(queries.LambdaLoggedRecordsPerRequestWithVersion, escapedQualifier, escapedKey, escapedKey, len(key))
+	results, err := logsFetcher.RunQuery(ctx, query, queryString)
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("run logs insights query: %w", err)
+	}
+
+	var records, invocations float64
+	for _, row := range results {
+		count, err := strconv.ParseFloat(row["records"], 64)
+		if err != nil || count <= 0 {
+			continue
+		}
+		records += count
+		invocations++
+	}
+	if invocations == 0 {
+		return nil, 0, false, nil
+	}
+	recordsPerInvocation := records / invocations
+	return &recordsPerInvocation, int(invocations), len(results) >= logsQueryLimit, nil
+}
+
+// listEventSourceMappings returns all event source mappings of the function qualifier, following the pagination markers.
+func listEventSourceMappings(
+	ctx context.Context,
+	client sdkinterfaces.EventSourceMappingClient,
+	query sdktypes.FunctionQuery,
+) ([]lambdatypes.EventSourceMappingConfiguration, error) {
+	functionName := query.FunctionName
+	// Mappings of $LATEST are attached to the unqualified function.
+	if query.Qualifier != "$LATEST" {
+		functionName = fmt.Sprintf("%s:%s", query.FunctionName, query.Qualifier)
+	}
+
+	mappings := []lambdatypes.EventSourceMappingConfiguration{}
+	var marker *string
+	for {
+		output, err := client.ListEventSourceMappings(ctx, &lambda.ListEventSourceMappingsInput{
+			FunctionName: aws.String(functionName),
+			Marker:       marker,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list event source mappings: %w", err)
+		}
+		mappings = append(mappings, output.EventSourceMappings...)
+		if output.NextMarker == nil || *output.NextMarker == "" {
+			return mappings, nil
+		}
+		marker = output.NextMarker
+	}
+}
+
+// eventSourceMappingStatistics reads the settings of a mapping and fetches its lag and polled events.
+func eventSourceMappingStatistics(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	query sdktypes.FunctionQuery,
+	mapping lambdatypes.EventSourceMappingConfiguration,
+) (sdktypes.EventSourceMappingStatistics, error) {
+	stats := sdktypes.EventSourceMappingStatistics{
+		UUID:                         aws.ToString(mapping.UUID),
+		EventSourceArn:               aws.ToString(mapping.EventSourceArn),
+		SourceType:                   eventSourceType(mapping),
+		State:                        aws.ToString(mapping.State),
+		LastProcessingResult:         aws.ToString(mapping.LastProcessingResult),
+		BatchSize:                    aws.ToInt32(mapping.BatchSize),
+		MaximumBatchingWindowSeconds: aws.ToInt32(mapping.MaximumBatchingWindowInSeconds),
+		ParallelizationFactor:        aws.ToInt32(mapping.ParallelizationFactor),
+		LagOverTime:                  []sdktypes.MetricPoint{},
+		Warnings:                     []string{},
+	}
+	for _, responseType := range mapping.FunctionResponseTypes {
+		if responseType == lambdatypes.FunctionResponseTypeReportBatchItemFailures {
+			stats.ReportsBatchItemFailures = true
+		}
+	}
+
+	period := seriesPeriod(query)
+	var lagResults []types.MetricDataResult
+	var err error
+	switch stats.SourceType {
+	case sdktypes.EventSourceTypeKinesis, sdktypes.EventSourceTypeDynamoDBStreams:
+		stats.LagMetric, stats.LagUnit = "IteratorAge", "Milliseconds"
+		lagResults, err = cwFetcher.FetchMetricSeries(ctx, query, stats.LagMetric, "Maximum", period)
+	case sdktypes.EventSourceTypeKafka:
+		stats.LagMetric, stats.LagUnit = "OffsetLag", "Count"
+		lagResults, err = cwFetcher.FetchMetricSeries(ctx, query, stats.LagMetric, "Maximum", period)
+	case sdktypes.EventSourceTypeSQS:
+		stats.LagMetric, stats.LagUnit = "ApproximateAgeOfOldestMessage", "Seconds"
+		queueName := stats.EventSourceArn[strings.LastIndex(stats.EventSourceArn, ":")+1:]
+		lagResults, err = cwFetcher.FetchDimensionMetricSeries(ctx, query, "AWS/SQS", stats.LagMetric, "Maximum", period,
+			map[string]string{"QueueName": queueName})
+	}
+	if err != nil {
+		return stats, fmt.Errorf("fetch %s metric: %w", stats.LagMetric, err)
+	}
+	stats.LagOverTime = metricPoints(lagResults)
+	stats.MaxLag = singleValue(lagResults)
+	stats.LagGrowthPerHour, stats.FallingBehind = lagGrowth(stats.LagOverTime)
+
+	polledResults, err := cwFetcher.FetchDimensionMetricSeries(ctx, query, "AWS/Lambda", "PolledEventCount", "Sum", windowPeriod(query),
+		map[string]string{"EventSourceMappingUUID": stats.UUID})
+	if err != nil {
+		return stats, fmt.Errorf("fetch PolledEventCount metric: %w", err)
+	}
+	if len(metricPoints(polledResults)) > 0 {
+		polled, err := utils.SumMetricValues(polledResults)
+		if err != nil {
+			return stats, fmt.Errorf("parse PolledEventCount metric data: %w", err)
+		}
+		stats.PolledEvents = &polled
+	}
+	return stats, nil
+}
+
+// eventSourceType derives the kind of source of a mapping from the service of its event source ARN.
+// Self-managed event sources are always Apache Kafka clusters.
+func eventSourceType(mapping lambdatypes.EventSourceMappingConfiguration) sdktypes.EventSourceType {
+	if mapping.EventSourceArn == nil {
+		if mapping.SelfManagedEventSource != nil {
+			return sdktypes.EventSourceTypeKafka
+		}
+		return sdktypes.EventSourceTypeOther
+	}
+	// ARNs have the format arn:partition:service:region:account:resource
+	parts := strings.SplitN(*mapping.EventSourceArn, ":", 4)
+	if len(parts) < 3 {
+		return sdktypes.EventSourceTypeOther
+	}
+	switch parts[2] {
+	case "sqs":
+		return sdktypes.EventSourceTypeSQS
+	case "kinesis":
+		return sdktypes.EventSourceTypeKinesis
+	case "dynamodb":
+		return sdktypes.EventSourceTypeDynamoDBStreams
+	case "kafka":
+		return sdktypes.EventSourceTypeKafka
+	default:
+		return sdktypes.EventSourceTypeOther
+	}
+}
+
+// lagGrowth fits a line through the lag and returns its slope per hour. The consumer is falling
+// behind if the lag grows and the fit explains at least fallingBehindRSquared of its variance.
+// At least three points are required, otherwise nil and false are returned.
+func lagGrowth(points []sdktypes.MetricPoint) (*float64, bool) {
+	if len(points) < 3 {
+		return nil, false
+	}
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, point := range points {
+		xs[i] = point.Timestamp.Sub(points[0].Timestamp).Hours()
+		ys[i] = point.Value
+	}
+	slope, _, rSquared, err := utils.LinearRegression(xs, ys)
+	if err != nil {
+		return nil, false
+	}
+	return &slope, slope > 0 && rSquared >= fallingBehindRSquared
+}
+
+// eventSourceMappingWarnings returns warnings for a mapping that is not enabled or falling behind,
+// for batch durations close to the timeout and for failures retrying whole batches of the source types
+// that support ReportBatchItemFailures.
+func eventSourceMappingWarnings(
+	stats sdktypes.EventSourceMappingStatistics,
+	result *sdktypes.EventSourceMappingStatisticsReturn,
+) []string {
+	warnings := []string{}
+	if stats.State != "" && stats.State != "Enabled" {
+		warnings = append(warnings, fmt.Sprintf("mapping is %s, no records are processed", stats.State))
+	}
+	if stats.FallingBehind {
+		var advice string
+		switch stats.SourceType {
+		case sdktypes.EventSourceTypeKinesis, sdktypes.EventSourceTypeDynamoDBStreams:
+			advice = fmt.Sprintf("raise the parallelization factor (currently %d) or the batch size", stats.ParallelizationFactor)
+		case sdktypes.EventSourceTypeKafka:
+			advice = "add partitions to the topic or raise the batch size"
+		default:
+			advice = "raise the maximum concurrency or the batch size"
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"%s grows by %.0f %s per hour, the consumer is falling behind: %s",
+			stats.LagMetric, *stats.LagGrowthPerHour, strings.ToLower(stats.LagUnit), advice))
+	}
+	if duration := result.BatchDuration; duration != nil && result.TimeoutSeconds > 0 {
+		name, tail := "max", duration.Max
+		if duration.P99 != nil {
+			name, tail = "p99", *duration.P99
+		}
+		timeoutMs := float64(result.TimeoutSeconds) * 1000
+		if tail >= batchDurationWarningThreshold*timeoutMs {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s batch duration of %.0f ms is %.0f%% of the timeout of %d s, lower the batch size (currently %d)",
+				name, tail, tail/timeoutMs*100, result.TimeoutSeconds, stats.BatchSize))
+		}
+	}
+	if batchItemFailureSourceTypes[stats.SourceType] && result.FunctionErrors > 0 && !stats.ReportsBatchItemFailures {
+		warnings = append(warnings, fmt.Sprintf(
+			"%.0f invocations failed without ReportBatchItemFailures, every failure retries the whole batch",
+			result.FunctionErrors))
+	}
+	return warnings
+}
+
+// smallBatchWarning returns a warning if the batches of a mapping without batching window
+// hold only a small share of the batch size.
+func smallBatchWarning(stats sdktypes.EventSourceMappingStatistics) (string, bool) {
+	if stats.BatchSize <= 1 || stats.MaximumBatchingWindowSeconds > 0 ||
+		*stats.RecordsPerInvocation >= smallBatchThreshold*float64(stats.BatchSize) {
+		return "", false
+	}
+	return fmt.Sprintf(
+		"batches hold %.1f of up to %d records on average, a batching window would reduce the number of invocations",
+		*stats.RecordsPerInvocation, stats.BatchSize), true
+}
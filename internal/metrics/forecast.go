@@ -0,0 +1,230 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/dominikhei/serverless-statistics/internal/forecast"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/pricing"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// oneDay is the width of the buckets of the capacity forecast.
+const oneDay = 24 * time.Hour
+
+// Names of the series GetCapacityForecast forecasts.
+const (
+	invocationsForecast            = "invocations"
+	gbSecondsForecast              = "gbSeconds"
+	peakConcurrencyForecast        = "peakConcurrency"
+	accountPeakConcurrencyForecast = "accountPeakConcurrency"
+)
+
+// GetCapacityForecast forecasts the daily invocations, GB-seconds and peak concurrency of an AWS Lambda function
+// from their history over a specified time range and qualifier (version). It predicts when the peak concurrency
+// reaches the reserved concurrency of the function, or the unreserved concurrency if none is reserved, and when
+// the peak concurrency of the account reaches the account limit, and estimates the cost of the next calendar month.
+//
+// The history is made of the full UTC days from the day of the start time on, days without data count as 0. The GB-seconds are
+// the summed Duration metric at the current memory size, which ignores that Lambda rounds every invocation up
+// to the next millisecond.
+func GetCapacityForecast(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	concurrencyClient sdkinterfaces.ConcurrencyClient,
+	query sdktypes.FunctionQuery,
+	options sdktypes.ForecastOptions,
+) (*sdktypes.CapacityForecastReturn, error) {
+
+	options, err := forecast.Normalize(options)
+	if err != nil {
+		return nil, err
+	}
+	// CloudWatch aligns daily datapoints to the start of the day, so the history is as well.
+	history := query
+	history.StartTime = query.StartTime.UTC().Truncate(oneDay)
+	days := int(query.EndTime.Sub(history.StartTime) / oneDay)
+	if days < 2 {
+		return nil, fmt.Errorf("time range must cover at least two full days, got %s", query.EndTime.Sub(history.StartTime))
+	}
+	history.EndTime = history.StartTime.Add(time.Duration(days) * oneDay)
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	var memorySizeMB float64
+	architecture := pricing.ArchitectureX86
+	if funcConfig.Configuration != nil {
+		memorySizeMB = float64(aws.ToInt32(funcConfig.Configuration.MemorySize))
+		architecture = functionArchitecture(funcConfig.Configuration.Architectures)
+	}
+	functionConcurrency, err := concurrencyClient.GetFunctionConcurrency(ctx, &lambda.GetFunctionConcurrencyInput{
+		FunctionName: aws.String(query.FunctionName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function concurrency: %w", err)
+	}
+	accountSettings, err := concurrencyClient.GetAccountSettings(ctx, &lambda.GetAccountSettingsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account settings: %w", err)
+	}
+
+	series := make(map[string][]sdktypes.MetricPoint)
+	for _, metric := range []struct {
+		series, name, stat string
+		account            bool
+	}{
+		{invocationsForecast, "Invocations", "Sum", false},
+		{gbSecondsForecast, "Duration", "Sum", false},
+		{peakConcurrencyForecast, "ConcurrentExecutions", "Maximum", false},
+		{accountPeakConcurrencyForecast, "ConcurrentExecutions", "Maximum", true},
+	} {
+		var results []types.MetricDataResult
+		if metric.account {
+			results, err = cwFetcher.FetchAccountMetricSeries(ctx, history, metric.name, metric.stat, int32(oneDay/time.Second))
+		} else {
+			results, err = cwFetcher.FetchMetricSeries(ctx, history, metric.name, metric.stat, int32(oneDay/time.Second))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s metric: %w", metric.name, err)
+		}
+		series[metric.series] = dailyPoints(results, history.StartTime, days)
+	}
+	for i, point := range series[gbSecondsForecast] {
+		series[gbSecondsForecast][i].Value = pricing.GBSeconds(point.Value, memorySizeMB)
+	}
+
+	result := &sdktypes.CapacityForecastReturn{
+		Forecasts:    []sdktypes.SeriesForecast{},
+		Limits:       []sdktypes.ConcurrencyLimitForecast{},
+		Series:       series,
+		Warnings:     []string{},
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    history.StartTime,
+		EndTime:      history.EndTime,
+	}
+	if memorySizeMB == 0 {
+		result.Warnings = append(result.Warnings, "memory size of the function is unknown, GB-seconds and duration cost are 0")
+	}
+
+	// The forecasts reach at least until the end of the next month for the cost estimate,
+	// and are cut to the horizon afterwards.
+	nextMonth := time.Date(history.EndTime.Year(), history.EndTime.Month()+1, 1, 0, 0, 0, 0, history.EndTime.Location())
+	lastDay := history.EndTime.Add(-oneDay)
+	extended := options
+	extended.Horizon = max(options.Horizon, nextMonth.AddDate(0, 1, 0).Sub(lastDay))
+	horizonDays := int(math.Ceil(float64(options.Horizon) / float64(oneDay)))
+
+	forecasts := make(map[string]*sdktypes.SeriesForecast)
+	for _, metric := range []string{invocationsForecast, gbSecondsForecast, peakConcurrencyForecast, accountPeakConcurrencyForecast} {
+		seriesForecast, err := forecast.Forecast(metric, series[metric], extended)
+		if err != nil {
+			return nil, fmt.Errorf("forecast %s: %w", metric, err)
+		}
+		if seriesForecast.Model != options.Model {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: less than two seasons of %s of history, forecast with the %s model",
+				metric, options.SeasonLength, seriesForecast.Model))
+		}
+		forecasts[metric] = seriesForecast
+	}
+	result.NextMonthCost = monthCost(nextMonth, forecasts[invocationsForecast].Forecast, forecasts[gbSecondsForecast].Forecast,
+		architecture, memorySizeMB)
+
+	for _, metric := range []string{invocationsForecast, gbSecondsForecast, peakConcurrencyForecast, accountPeakConcurrencyForecast} {
+		forecasts[metric].Forecast = forecasts[metric].Forecast[:horizonDays]
+		result.Forecasts = append(result.Forecasts, *forecasts[metric])
+	}
+
+	if reserved := functionConcurrency.ReservedConcurrentExecutions; reserved != nil {
+		result.Limits = append(result.Limits, concurrencyLimitForecast("reserved", *reserved,
+			series[peakConcurrencyForecast], forecasts[peakConcurrencyForecast].Forecast))
+	} else if limit := accountSettings.AccountLimit; limit != nil && aws.ToInt32(limit.UnreservedConcurrentExecutions) > 0 {
+		result.Limits = append(result.Limits, concurrencyLimitForecast("unreserved", *limit.UnreservedConcurrentExecutions,
+			series[peakConcurrencyForecast], forecasts[peakConcurrencyForecast].Forecast))
+	}
+	if limit := accountSettings.AccountLimit; limit != nil && limit.ConcurrentExecutions > 0 {
+		result.Limits = append(result.Limits, concurrencyLimitForecast("account", limit.ConcurrentExecutions,
+			series[accountPeakConcurrencyForecast], forecasts[accountPeakConcurrencyForecast].Forecast))
+	}
+	return result, nil
+}
+
+// dailyPoints returns one point per day from start on with the values of the results, 0 for days without data.
+// Every value is assigned to the nearest day, such that timestamps slightly off the start of a day do not shift it.
+func dailyPoints(results []types.MetricDataResult, start time.Time, days int) []sdktypes.MetricPoint {
+	points := make([]sdktypes.MetricPoint, days)
+	for i := range points {
+		points[i].Timestamp = start.Add(time.Duration(i) * oneDay)
+	}
+	for _, point := range metricPoints(results) {
+		if index := int(math.Round(float64(point.Timestamp.Sub(start)) / float64(oneDay))); index >= 0 && index < days {
+			points[index].Value += point.Value
+		}
+	}
+	return points
+}
+
+// monthCost sums the forecast invocations and GB-seconds of the days starting within the month and prices them.
+// The bounds of the cost are the sums of the daily bounds, which is conservative as daily errors partly cancel out.
+func monthCost(month time.Time, invocations, gbSeconds []sdktypes.ForecastPoint, architecture string, memorySizeMB float64) sdktypes.CostForecast {
+	prices := pricing.ForArchitecture(architecture)
+	cost := sdktypes.CostForecast{Month: month, Architecture: architecture, MemorySizeMB: memorySizeMB}
+	end := month.AddDate(0, 1, 0)
+	for i := range invocations {
+		if invocations[i].Timestamp.Before(month) || !invocations[i].Timestamp.Before(end) {
+			continue
+		}
+		cost.Invocations += invocations[i].Value
+		cost.GBSeconds += gbSeconds[i].Value
+		cost.Cost += invocations[i].Value/1e6*prices.PerMillionRequests + gbSeconds[i].Value*prices.PerGBSecond
+		cost.CostLower += invocations[i].Lower/1e6*prices.PerMillionRequests + gbSeconds[i].Lower*prices.PerGBSecond
+		cost.CostUpper += invocations[i].Upper/1e6*prices.PerMillionRequests + gbSeconds[i].Upper*prices.PerGBSecond
+	}
+	return cost
+}
+
+// concurrencyLimitForecast returns the first days the forecast and its upper bound reach the limit.
+func concurrencyLimitForecast(name string, limit int32, history []sdktypes.MetricPoint, points []sdktypes.ForecastPoint) sdktypes.ConcurrencyLimitForecast {
+	result := sdktypes.ConcurrencyLimitForecast{Limit: name, Concurrency: limit}
+	for _, point := range history {
+		result.PeakConcurrency = math.Max(result.PeakConcurrency, point.Value)
+	}
+	if limit > 0 {
+		result.Utilization = result.PeakConcurrency / float64(limit)
+	}
+	for _, point := range points {
+		if result.EarliestAt == nil && point.Upper >= float64(limit) {
+			result.EarliestAt = aws.Time(point.Timestamp)
+		}
+		if result.ExpectedAt == nil && point.Value >= float64(limit) {
+			result.ExpectedAt = aws.Time(point.Timestamp)
+		}
+	}
+	return result
+}
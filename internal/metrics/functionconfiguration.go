@@ -16,19 +16,32 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/pricing"
+	"github.com/dominikhei/serverless-statistics/internal/redaction"
 	sdktypes "github.com/dominikhei/serverless-statistics/types"
 )
 
-// GetFunctionConfiguration gets configurations of an AWS Lambda function with a sprcific qualifier.
+// GetFunctionConfiguration gets a snapshot of the configuration of an AWS Lambda function with a specific qualifier.
+// Besides the settings returned by GetFunction, it reads the provisioned concurrency of published versions,
+// the event invoke config and the function URL of $LATEST. Settings that are not configured are left empty.
+// The values of environment variables that are secrets according to the redaction policy are redacted or hashed.
+// Tags, the event invoke config and the function URL are left empty with a warning if the caller may not read them.
 func GetFunctionConfiguration(
 	ctx context.Context,
 	lambdaClient sdkinterfaces.LambdaClient,
+	provisionedConcurrencyClient sdkinterfaces.ProvisionedConcurrencyClient,
+	eventInvokeConfigClient sdkinterfaces.EventInvokeConfigClient,
+	functionURLClient sdkinterfaces.FunctionURLClient,
 	query sdktypes.FunctionQuery,
+	redactionPolicy sdktypes.RedactionPolicy,
 ) (*sdktypes.BaseStatisticsReturn, error) {
 
 	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
@@ -38,19 +51,183 @@ func GetFunctionConfiguration(
 	if err != nil {
 		return nil, fmt.Errorf("failed to get function configuration: %w", err)
 	}
+	config := funcConfig.Configuration
 
-	envVars := make(map[string]string)
-	if funcConfig.Configuration.Environment != nil && funcConfig.Configuration.Environment.Variables != nil {
-		envVars = funcConfig.Configuration.Environment.Variables
-	}
-	return &sdktypes.BaseStatisticsReturn{
-		FunctionARN:          aws.ToString(funcConfig.Configuration.FunctionArn),
-		FunctionName:         aws.ToString(funcConfig.Configuration.FunctionName),
-		Qualifier:            aws.ToString(funcConfig.Configuration.Version),
-		MemorySizeMB:         funcConfig.Configuration.MemorySize,
-		TimeoutSeconds:       funcConfig.Configuration.Timeout,
-		Runtime:              string(funcConfig.Configuration.Runtime),
-		LastModified:         aws.ToString(funcConfig.Configuration.LastModified),
-		EnvironmentVariables: envVars,
-	}, nil
+	var envVars map[string]string
+	if config.Environment != nil {
+		envVars = config.Environment.Variables
+	}
+	envVars, envVarVisibility := redaction.Redact(envVars, redactionPolicy)
+	architectures := make([]string, 0, len(config.Architectures))
+	for _, architecture := range config.Architectures {
+		architectures = append(architectures, string(architecture))
+	}
+	snapshot := &sdktypes.BaseStatisticsReturn{
+		FunctionARN:                   aws.ToString(config.FunctionArn),
+		FunctionName:                  aws.ToString(config.FunctionName),
+		Qualifier:                     aws.ToString(config.Version),
+		MemorySizeMB:                  config.MemorySize,
+		TimeoutSeconds:                config.Timeout,
+		Runtime:                       string(config.Runtime),
+		Architectures:                 architectures,
+		LastModified:                  aws.ToString(config.LastModified),
+		EnvironmentVariables:          envVars,
+		EnvironmentVariableVisibility: envVarVisibility,
+		Handler:                       aws.ToString(config.Handler),
+		Role:                          aws.ToString(config.Role),
+		PackageType:                   string(config.PackageType),
+		CodeSizeBytes:                 config.CodeSize,
+		Layers:                        []sdktypes.LayerConfiguration{},
+		Tags:                          make(map[string]string),
+		Warnings:                      []string{},
+	}
+	if config.EphemeralStorage != nil {
+		snapshot.EphemeralStorageMB = config.EphemeralStorage.Size
+	}
+	for _, layer := range config.Layers {
+		snapshot.Layers = append(snapshot.Layers, sdktypes.LayerConfiguration{
+			Arn:           aws.ToString(layer.Arn),
+			CodeSizeBytes: layer.CodeSize,
+		})
+	}
+	// Functions without VPC return an empty VPC config.
+	if vpc := config.VpcConfig; vpc != nil && aws.ToString(vpc.VpcId) != "" {
+		snapshot.VpcConfig = &sdktypes.VpcConfiguration{
+			VpcID:                   aws.ToString(vpc.VpcId),
+			SubnetIDs:               sortedCopy(vpc.SubnetIds),
+			SecurityGroupIDs:        sortedCopy(vpc.SecurityGroupIds),
+			Ipv6AllowedForDualStack: aws.ToBool(vpc.Ipv6AllowedForDualStack),
+		}
+	}
+	if funcConfig.Concurrency != nil {
+		snapshot.ReservedConcurrency = funcConfig.Concurrency.ReservedConcurrentExecutions
+	}
+	if config.SnapStart != nil {
+		snapshot.SnapStart = &sdktypes.SnapStartConfiguration{
+			ApplyOn:            string(config.SnapStart.ApplyOn),
+			OptimizationStatus: string(config.SnapStart.OptimizationStatus),
+		}
+	}
+	if logging := config.LoggingConfig; logging != nil {
+		snapshot.LoggingConfig = &sdktypes.LoggingConfiguration{
+			LogFormat:           string(logging.LogFormat),
+			ApplicationLogLevel: string(logging.ApplicationLogLevel),
+			SystemLogLevel:      string(logging.SystemLogLevel),
+			LogGroup:            aws.ToString(logging.LogGroup),
+		}
+	}
+	if config.DeadLetterConfig != nil {
+		snapshot.DeadLetterTargetArn = aws.ToString(config.DeadLetterConfig.TargetArn)
+	}
+	if config.TracingConfig != nil {
+		snapshot.TracingMode = string(config.TracingConfig.Mode)
+	}
+	// Tags are left empty if the caller may not list them, which GetFunction reports in TagsError.
+	for key, value := range funcConfig.Tags {
+		snapshot.Tags[key] = value
+	}
+	if funcConfig.TagsError != nil {
+		snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("failed to list tags: %s", aws.ToString(funcConfig.TagsError.Message)))
+	}
+
+	// Provisioned concurrency can only be configured on published versions and aliases.
+	if query.Qualifier != "$LATEST" {
+		pcConfig, err := provisionedConcurrencyClient.GetProvisionedConcurrencyConfig(ctx, &lambda.GetProvisionedConcurrencyConfigInput{
+			FunctionName: aws.String(query.FunctionName),
+			Qualifier:    aws.String(query.Qualifier),
+		})
+		var pcNotFound *lambdatypes.ProvisionedConcurrencyConfigNotFoundException
+		switch {
+		case errors.As(err, &pcNotFound):
+		case err != nil:
+			return nil, fmt.Errorf("failed to get provisioned concurrency config: %w", err)
+		case pcConfig != nil:
+			snapshot.ProvisionedConcurrency = pcConfig.RequestedProvisionedConcurrentExecutions
+		}
+	}
+
+	var notFound *lambdatypes.ResourceNotFoundException
+	invokeConfig, err := eventInvokeConfigClient.GetFunctionEventInvokeConfig(ctx, &lambda.GetFunctionEventInvokeConfigInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	switch {
+	case errors.As(err, &notFound):
+	case err != nil:
+		snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("failed to get event invoke config: %v", err))
+	case invokeConfig != nil:
+		asyncConfig := &sdktypes.AsyncInvokeConfiguration{
+			MaximumRetryAttempts:   invokeConfig.MaximumRetryAttempts,
+			MaximumEventAgeSeconds: invokeConfig.MaximumEventAgeInSeconds,
+		}
+		if destinations := invokeConfig.DestinationConfig; destinations != nil {
+			if destinations.OnSuccess != nil {
+				asyncConfig.OnSuccessDestination = aws.ToString(destinations.OnSuccess.Destination)
+			}
+			if destinations.OnFailure != nil {
+				asyncConfig.OnFailureDestination = aws.ToString(destinations.OnFailure.Destination)
+			}
+		}
+		snapshot.AsyncInvokeConfig = asyncConfig
+	}
+
+	// Function URLs can only be configured on the unqualified function and aliases.
+	if query.Qualifier == "$LATEST" {
+		urlConfig, err := functionURLClient.GetFunctionUrlConfig(ctx, &lambda.GetFunctionUrlConfigInput{
+			FunctionName: aws.String(query.FunctionName),
+		})
+		switch {
+		case errors.As(err, &notFound):
+		case err != nil:
+			snapshot.Warnings = append(snapshot.Warnings, fmt.Sprintf("failed to get function url config: %v", err))
+		case urlConfig != nil:
+			snapshot.FunctionURL = &sdktypes.FunctionURLConfiguration{
+				URL:        aws.ToString(urlConfig.FunctionUrl),
+				AuthType:   string(urlConfig.AuthType),
+				InvokeMode: string(urlConfig.InvokeMode),
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// sortedCopy returns a sorted copy of the values, so that snapshots do not depend on the order of the API.
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// listFunctionVersions returns the configurations of $LATEST and all published versions of a function,
+// in the order of the Lambda API, following the pagination markers.
+func listFunctionVersions(
+	ctx context.Context,
+	versionsClient sdkinterfaces.FunctionVersionsClient,
+	functionName string,
+) ([]lambdatypes.FunctionConfiguration, error) {
+	versions := []lambdatypes.FunctionConfiguration{}
+	var marker *string
+	for {
+		output, err := versionsClient.ListVersionsByFunction(ctx, &lambda.ListVersionsByFunctionInput{
+			FunctionName: aws.String(functionName),
+			Marker:       marker,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list function versions: %w", err)
+		}
+		versions = append(versions, output.Versions...)
+		if output.NextMarker == nil || *output.NextMarker == "" {
+			return versions, nil
+		}
+		marker = output.NextMarker
+	}
+}
+
+// functionArchitecture returns the instruction set architecture of a function configuration,
+// x86_64 if none is set as it is the Lambda default.
+func functionArchitecture(architectures []lambdatypes.Architecture) string {
+	if len(architectures) == 0 {
+		return pricing.ArchitectureX86
+	}
+	return string(architectures[0])
 }
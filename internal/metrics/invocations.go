@@ -0,0 +1,81 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// logsQueryLimit is the largest number of rows the Logs Insights queries return.
+const logsQueryLimit = 10000
+
+// timeSeriesBuckets is the number of buckets time series derived from logs are split into.
+const timeSeriesBuckets = 48
+
+// getInvocationsSum returns the number of invocations of a function qualifier in the
+// query interval. The value is read from the cache if present, otherwise it is
+// fetched from CloudWatch metrics and stored in the cache.
+func getInvocationsSum(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (float64, error) {
+	// cache reduces the number of calls to CloudWatch metrics.
+	// It lives as long as the Go process is running.
+	key := cache.CacheKey{
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		Start:        query.StartTime,
+		End:          query.EndTime,
+	}
+	if invocations, ok := invocationsCache.Get(key); ok {
+		return float64(invocations), nil
+	}
+	invocationsResults, err := cwFetcher.FetchMetric(ctx, query, "Invocations", "Sum")
+	if err != nil {
+		return 0, fmt.Errorf("fetch invocations metric: %w", err)
+	}
+	invocationsSum, err := utils.SumMetricValues(invocationsResults)
+	if err != nil {
+		return 0, fmt.Errorf("parse invocations metric data: %w", err)
+	}
+	invocationsCache.Set(key, int(invocationsSum))
+	return invocationsSum, nil
+}
+
+// getReports returns the REPORT lines of the invocations of a function qualifier in the query interval.
+func getReports(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	query sdktypes.FunctionQuery,
+) ([]logparser.InvocationReport, error) {
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+	queryString := fmt.Sprintf(queries.LambdaReportRecordsQueryWithVersion, escapedQualifier)
+	results, err := logsFetcher.RunQuery(ctx, query, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("run logs insights query: %w", err)
+	}
+	return logparser.ParseReports(results), nil
+}
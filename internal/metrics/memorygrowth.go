@@ -0,0 +1,175 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// minInvocationsForGrowth is the minimum number of invocations an environment
+// needs to have served, to fit the growth of its memory.
+const minInvocationsForGrowth = 5
+
+// Max memory used is the high-water mark of an environment and never decreases on its own,
+// so an increase alone is no sign of a leak. Memory only counts as growing if a line fits
+// its invocations well and it grew by a relevant share over them.
+const (
+	minGrowthRSquared = 0.8
+	minGrowthFraction = 0.1
+)
+
+// GetMemoryGrowth fits the growth of the max memory used over the invocations of every
+// execution environment of an AWS Lambda function over a specified time range and qualifier (version).
+// Environments whose memory steadily increases point to leaks in global state.
+func GetMemoryGrowth(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.MemoryGrowthReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	var memorySizeMB float64
+	if funcConfig.Configuration != nil && funcConfig.Configuration.MemorySize != nil {
+		memorySizeMB = float64(*funcConfig.Configuration.MemorySize)
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+	if len(reports) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only the first %d REPORT lines are analyzed, the memory growth after %s is not fitted",
+			logsQueryLimit, reports[len(reports)-1].Timestamp.Format(time.RFC3339)))
+	}
+
+	result := &sdktypes.MemoryGrowthReturn{
+		MemorySizeMB:         memorySizeMB,
+		Environments:         []sdktypes.EnvironmentMemoryGrowth{},
+		AffectedEnvironments: []sdktypes.EnvironmentMemoryGrowth{},
+		Warnings:             warnings,
+		FunctionName:         query.FunctionName,
+		Qualifier:            query.Qualifier,
+		StartTime:            query.StartTime,
+		EndTime:              query.EndTime,
+	}
+
+	for logStream, envReports := range logparser.GroupByLogStream(reports) {
+		if len(envReports) < minInvocationsForGrowth {
+			continue
+		}
+		growth, err := fitMemoryGrowth(logStream, envReports, memorySizeMB)
+		if err != nil {
+			fmt.Printf("warn: could not fit memory growth of %q: %v\n", logStream, err)
+			continue
+		}
+		result.Environments = append(result.Environments, growth)
+		if growth.MonotonicallyIncreasing {
+			result.AffectedEnvironments = append(result.AffectedEnvironments, growth)
+		}
+	}
+
+	// Environments with the steepest growth come first.
+	for _, envs := range [][]sdktypes.EnvironmentMemoryGrowth{result.Environments, result.AffectedEnvironments} {
+		sort.Slice(envs, func(i, j int) bool {
+			if envs[i].SlopeMBPerInvocation != envs[j].SlopeMBPerInvocation {
+				return envs[i].SlopeMBPerInvocation > envs[j].SlopeMBPerInvocation
+			}
+			return envs[i].LogStream < envs[j].LogStream
+		})
+	}
+
+	return result, nil
+}
+
+// fitMemoryGrowth fits a line through the max memory used of the time ordered invocations
+// of one environment. If the configured memory size is unknown, the one of the REPORT lines is used.
+func fitMemoryGrowth(logStream string, reports []logparser.InvocationReport, memorySizeMB float64) (sdktypes.EnvironmentMemoryGrowth, error) {
+	xs := make([]float64, len(reports))
+	ys := make([]float64, len(reports))
+	monotonic := true
+	for i, report := range reports {
+		xs[i] = float64(i)
+		ys[i] = report.MaxMemoryUsedMB
+		if i > 0 && ys[i] < ys[i-1] {
+			monotonic = false
+		}
+	}
+	slope, _, rSquared, err := utils.LinearRegression(xs, ys)
+	if err != nil {
+		return sdktypes.EnvironmentMemoryGrowth{}, err
+	}
+
+	last := reports[len(reports)-1]
+	growth := sdktypes.EnvironmentMemoryGrowth{
+		LogStream:               logStream,
+		Invocations:             len(reports),
+		FirstMaxMemoryUsedMB:    reports[0].MaxMemoryUsedMB,
+		LastMaxMemoryUsedMB:     last.MaxMemoryUsedMB,
+		SlopeMBPerInvocation:    slope,
+		RSquared:                rSquared,
+		MonotonicallyIncreasing: monotonic && isSteadyGrowth(reports[0].MaxMemoryUsedMB, last.MaxMemoryUsedMB, slope, rSquared),
+	}
+
+	if memorySizeMB == 0 {
+		memorySizeMB = last.MemorySizeMB
+	}
+	if slope > 0 && memorySizeMB > 0 {
+		remaining := (memorySizeMB - last.MaxMemoryUsedMB) / slope
+		if remaining < 0 {
+			remaining = 0
+		}
+		growth.ProjectedInvocationsUntilOOM = &remaining
+	}
+	return growth, nil
+}
+
+// isSteadyGrowth reports whether a positive slope fits the memory well and the memory grew
+// by at least minGrowthFraction, which excludes environments that plateau after a single step.
+func isSteadyGrowth(firstMB, lastMB, slope, rSquared float64) bool {
+	if slope <= 0 || rSquared < minGrowthRSquared || firstMB <= 0 {
+		return false
+	}
+	return (lastMB-firstMB)/firstMB >= minGrowthFraction
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetOutOfMemoryRate calculates the share of invocations of an AWS Lambda function that ran out of memory
+// over a specified time range and qualifier (version).
+// An invocation ran out of memory if its max memory used reached the memory size, or if it ended with
+// Runtime.OutOfMemory or with the runtime process being killed by a signal.
+func GetOutOfMemoryRate(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.OutOfMemoryRateReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+
+	outOfMemory := make(map[string]struct{})
+	for _, report := range reports {
+		if report.MemorySizeMB > 0 && report.MaxMemoryUsedMB >= report.MemorySizeMB {
+			outOfMemory[report.RequestID] = struct{}{}
+		}
+		if report.ErrorType == "Runtime.OutOfMemory" {
+			outOfMemory[report.RequestID] = struct{}{}
+		}
+	}
+
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+	queryString := fmt.Sprintf(queries.LambdaOutOfMemorySignalsWithVersion, escapedQualifier)
+	results, err := logsFetcher.RunQuery(ctx, query, queryString)
+	if err != nil {
+		return nil, fmt.Errorf("run logs insights query: %w", err)
+	}
+	for _, row := range results {
+		if requestID := row["@requestId"]; requestID != "" {
+			outOfMemory[requestID] = struct{}{}
+		}
+	}
+
+	warnings := []string{}
+	if len(reports) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only the first %d REPORT lines are analyzed, out of memory invocations and memory usage after %s are missing",
+			logsQueryLimit, reports[len(reports)-1].Timestamp.Format(time.RFC3339)))
+	}
+	if len(results) >= logsQueryLimit {
+		warnings = append(warnings, fmt.Sprintf(
+			"only %d invocations with out of memory signals are read, the out of memory count is a lower bound", logsQueryLimit))
+	}
+
+	requestIDs := make([]string, 0, len(outOfMemory))
+	for requestID := range outOfMemory {
+		requestIDs = append(requestIDs, requestID)
+	}
+	sort.Strings(requestIDs)
+
+	return &sdktypes.OutOfMemoryRateReturn{
+		OutOfMemoryCount:    len(requestIDs),
+		OutOfMemoryRate:     float64(len(requestIDs)) / invocationsSum,
+		RequestIDs:          requestIDs,
+		MemoryUsageOverTime: memoryUsageOverTime(reports, query),
+		Warnings:            warnings,
+		FunctionName:        query.FunctionName,
+		Qualifier:           query.Qualifier,
+		StartTime:           query.StartTime,
+		EndTime:             query.EndTime,
+	}, nil
+}
+
+// memoryUsageOverTime aggregates the memory usage ratios of the reports into time buckets
+// starting at the start time of the query. Buckets without invocations are left out.
+func memoryUsageOverTime(reports []logparser.InvocationReport, query sdktypes.FunctionQuery) []sdktypes.MemoryUsagePoint {
+	bucketSize := utils.BucketSize(query.StartTime, query.EndTime, timeSeriesBuckets)
+	points := []sdktypes.MemoryUsagePoint{}
+	var sum float64
+	for _, report := range reports {
+		if report.MemorySizeMB == 0 {
+			continue
+		}
+		ratio := report.MaxMemoryUsedMB / report.MemorySizeMB
+		bucket := query.StartTime.Add(report.Timestamp.Sub(query.StartTime) / bucketSize * bucketSize)
+		if len(points) == 0 || !points[len(points)-1].Timestamp.Equal(bucket) {
+			if len(points) > 0 {
+				last := &points[len(points)-1]
+				last.MeanUsageRate = sum / float64(last.Invocations)
+			}
+			points = append(points, sdktypes.MemoryUsagePoint{Timestamp: bucket})
+			sum = 0
+		}
+		last := &points[len(points)-1]
+		last.Invocations++
+		sum += ratio
+		if ratio > last.MaxUsageRate {
+			last.MaxUsageRate = ratio
+		}
+	}
+	if len(points) > 0 {
+		last := &points[len(points)-1]
+		last.MeanUsageRate = sum / float64(last.Invocations)
+	}
+	return points
+}
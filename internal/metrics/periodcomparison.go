@@ -0,0 +1,349 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	defaultComparisonOffset      = sdktypes.ComparePreviousWeek
+	defaultSignificanceLevel     = 0.05
+	defaultComparisonMinRelative = 0.1
+	// periodSettleDelay is how long after its end a period is considered complete, as CloudWatch metrics
+	// and log events can arrive late. Periods ending earlier are cached.
+	periodSettleDelay = time.Hour
+)
+
+// comparisonMetrics are all metrics that can be compared, in the order they are returned.
+var comparisonMetrics = []sdktypes.ComparisonMetric{
+	sdktypes.ComparisonMetricInvocations,
+	sdktypes.ComparisonMetricErrorRate,
+	sdktypes.ComparisonMetricThrottleRate,
+	sdktypes.ComparisonMetricTimeoutRate,
+	sdktypes.ComparisonMetricColdStartRate,
+	sdktypes.ComparisonMetricMeanDuration,
+	sdktypes.ComparisonMetricMedianDuration,
+	sdktypes.ComparisonMetricP95Duration,
+	sdktypes.ComparisonMetricP99Duration,
+	sdktypes.ComparisonMetricMeanMemoryUsageRate,
+	sdktypes.ComparisonMetricP95MemoryUsageRate,
+}
+
+// GetPeriodComparison compares the metrics of an AWS Lambda function over a specified time range and qualifier
+// (version) with the same time range shifted back by the offset. Every metric is calculated with its regular
+// calculator on both periods, and the change is significant if a two-sided z-test rejects equality at the
+// significance level and the relative change reaches the minimum. Metrics without a standard error, e.g.
+// percentiles, are only tested against the minimum relative change.
+//
+// The values of periods that ended more than an hour before now are cached in periodCache, as they do not change
+// anymore, so comparing against the same previous period repeatedly only queries it once.
+func GetPeriodComparison(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	periodCache sdkinterfaces.PeriodCache,
+	query sdktypes.FunctionQuery,
+	options sdktypes.PeriodComparisonOptions,
+	now time.Time,
+) (*sdktypes.PeriodComparisonReturn, error) {
+
+	options, err := normalizeComparisonOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	previous := query
+	previous.StartTime = query.StartTime.Add(-options.Offset)
+	previous.EndTime = query.EndTime.Add(-options.Offset)
+
+	currentValues, currentWarnings, err := periodValues(ctx, logsFetcher, cwFetcher, invocationsCache, periodCache, query, options.Metrics, now)
+	if err != nil {
+		return nil, fmt.Errorf("current period: %w", err)
+	}
+	previousValues, previousWarnings, err := periodValues(ctx, logsFetcher, cwFetcher, invocationsCache, periodCache, previous, options.Metrics, now)
+	if err != nil {
+		return nil, fmt.Errorf("previous period: %w", err)
+	}
+
+	result := &sdktypes.PeriodComparisonReturn{
+		Comparisons:       []sdktypes.MetricComparison{},
+		Warnings:          []string{},
+		Offset:            options.Offset,
+		FunctionName:      query.FunctionName,
+		Qualifier:         query.Qualifier,
+		StartTime:         query.StartTime,
+		EndTime:           query.EndTime,
+		PreviousStartTime: previous.StartTime,
+		PreviousEndTime:   previous.EndTime,
+	}
+	if previous.EndTime.After(query.StartTime) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("the offset of %s is shorter than the time range, the periods overlap", options.Offset))
+	}
+	for _, warning := range currentWarnings {
+		result.Warnings = append(result.Warnings, "current period: "+warning)
+	}
+	for _, warning := range previousWarnings {
+		result.Warnings = append(result.Warnings, "previous period: "+warning)
+	}
+	for _, metric := range options.Metrics {
+		current, currentOk := currentValues[metric]
+		previousValue, previousOk := previousValues[metric]
+		switch {
+		case !currentOk && !previousOk:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: not available in either period", metric))
+		case !currentOk:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: not available in the current period", metric))
+		case !previousOk:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s: not available in the previous period", metric))
+		default:
+			result.Comparisons = append(result.Comparisons, compareValues(metric, current, previousValue, options))
+		}
+	}
+	return result, nil
+}
+
+// normalizeComparisonOptions fills the defaults of the options and returns an error if they are invalid.
+func normalizeComparisonOptions(options sdktypes.PeriodComparisonOptions) (sdktypes.PeriodComparisonOptions, error) {
+	if options.Offset == 0 {
+		options.Offset = defaultComparisonOffset
+	}
+	if len(options.Metrics) == 0 {
+		options.Metrics = comparisonMetrics
+	}
+	if options.SignificanceLevel == 0 {
+		options.SignificanceLevel = defaultSignificanceLevel
+	}
+	if options.MinRelativeChange == 0 {
+		options.MinRelativeChange = defaultComparisonMinRelative
+	}
+	switch {
+	case options.Offset < 0:
+		return options, fmt.Errorf("offset must be positive, got %s", options.Offset)
+	case options.SignificanceLevel < 0 || options.SignificanceLevel >= 1:
+		return options, fmt.Errorf("significance level must be between 0 and 1, got %v", options.SignificanceLevel)
+	case options.MinRelativeChange < 0:
+		return options, fmt.Errorf("min relative change must not be negative, got %v", options.MinRelativeChange)
+	}
+	for _, metric := range options.Metrics {
+		if !slices.Contains(comparisonMetrics, metric) {
+			return options, fmt.Errorf("unknown comparison metric %q", metric)
+		}
+	}
+	return options, nil
+}
+
+// periodValues returns the values of the metrics in the period of the query. Values of periods that are complete
+// are taken from and added to periodCache; the calculators of the other metrics are only run if any of their
+// metrics is requested. Metrics other than the invocations are left out if the period has no invocations,
+// and percentiles if there are too few invocations to calculate them. Metrics calculated from the logs are left
+// out with a warning if their calculator fails, e.g. because the period is beyond the retention of the logs;
+// such periods are not cached.
+func periodValues(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	invocationsCache sdkinterfaces.Cache,
+	periodCache sdkinterfaces.PeriodCache,
+	query sdktypes.FunctionQuery,
+	metrics []sdktypes.ComparisonMetric,
+	now time.Time,
+) (map[sdktypes.ComparisonMetric]sdktypes.PeriodValue, []string, error) {
+	key := cache.CacheKey{
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		Start:        query.StartTime,
+		End:          query.EndTime,
+	}
+	complete := !query.EndTime.After(now.Add(-periodSettleDelay))
+	values := make(map[sdktypes.ComparisonMetric]sdktypes.PeriodValue)
+	if cached, ok := periodCache.Get(key); ok && complete {
+		maps.Copy(values, cached)
+	}
+	// missing returns true if one of the metrics is requested and the calculator they share has not run,
+	// which is the case if the first metric, which every run sets, has no value.
+	missing := func(calculated ...sdktypes.ComparisonMetric) bool {
+		if _, ok := values[calculated[0]]; ok {
+			return false
+		}
+		for _, metric := range calculated {
+			if slices.Contains(metrics, metric) {
+				return true
+			}
+		}
+		return false
+	}
+	warnings := []string{}
+	// logsFailed records a failed calculator of metrics from the logs, unless the context ended.
+	logsFailed := func(name string, err error) error {
+		if ctx.Err() != nil {
+			return err
+		}
+		warnings = append(warnings, fmt.Sprintf("%s could not be calculated from the logs and is left out: %v", name, err))
+		return nil
+	}
+
+	invocations, ok := values[sdktypes.ComparisonMetricInvocations]
+	if !ok {
+		invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		invocations = sdktypes.PeriodValue{Value: invocationsSum, SampleSize: invocationsSum, StdError: stdError(math.Sqrt(invocationsSum))}
+		values[sdktypes.ComparisonMetricInvocations] = invocations
+	}
+	n := invocations.Value
+
+	if n > 0 && missing(sdktypes.ComparisonMetricErrorRate) {
+		errorRate, err := GetErrorRate(ctx, cwFetcher, invocationsCache, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		values[sdktypes.ComparisonMetricErrorRate] = proportionValue(errorRate.ErrorRate, n)
+	}
+	if n > 0 && missing(sdktypes.ComparisonMetricThrottleRate) {
+		throttleRate, err := GetThrottleRate(ctx, cwFetcher, invocationsCache, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Throttled requests are not invocations, so the throttles are a count relative to the invocations rather than
+		// a share of them, and can exceed them.
+		values[sdktypes.ComparisonMetricThrottleRate] = sdktypes.PeriodValue{
+			Value:      throttleRate.ThrottleRate,
+			SampleSize: n,
+			StdError:   stdError(math.Sqrt(throttleRate.ThrottleRate / n)),
+		}
+	}
+	if n > 0 && missing(sdktypes.ComparisonMetricTimeoutRate) {
+		timeoutRate, err := GetTimeoutRate(ctx, cwFetcher, logsFetcher, invocationsCache, query)
+		if err != nil {
+			if err := logsFailed("timeout rate", err); err != nil {
+				return nil, nil, err
+			}
+		} else if !math.IsNaN(timeoutRate.TimeoutRate) {
+			// The rate is NaN if the invocations are not in the logs, e.g. beyond their retention.
+			values[sdktypes.ComparisonMetricTimeoutRate] = proportionValue(timeoutRate.TimeoutRate, n)
+		}
+	}
+	if n > 0 && missing(sdktypes.ComparisonMetricColdStartRate) {
+		coldStartRate, err := GetColdStartRate(ctx, logsFetcher, cwFetcher, invocationsCache, query)
+		if err != nil {
+			if err := logsFailed("cold start rate", err); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			values[sdktypes.ComparisonMetricColdStartRate] = proportionValue(coldStartRate.ColdStartRate, n)
+		}
+	}
+	if n > 0 && missing(sdktypes.ComparisonMetricMeanDuration, sdktypes.ComparisonMetricMedianDuration,
+		sdktypes.ComparisonMetricP95Duration, sdktypes.ComparisonMetricP99Duration) {
+		duration, err := GetDurationStatistics(ctx, logsFetcher, cwFetcher, invocationsCache, query)
+		if err != nil {
+			if err := logsFailed("duration", err); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			values[sdktypes.ComparisonMetricMeanDuration] = meanValue(duration.MeanDuration, duration.Conf95Duration, n)
+			values[sdktypes.ComparisonMetricMedianDuration] = sdktypes.PeriodValue{Value: duration.MedianDuration, SampleSize: n}
+			setPercentile(values, sdktypes.ComparisonMetricP95Duration, duration.P95Duration, n)
+			setPercentile(values, sdktypes.ComparisonMetricP99Duration, duration.P99Duration, n)
+		}
+	}
+	if n > 0 && missing(sdktypes.ComparisonMetricMeanMemoryUsageRate, sdktypes.ComparisonMetricP95MemoryUsageRate) {
+		memory, err := GetMaxMemoryUsageStatistics(ctx, logsFetcher, cwFetcher, invocationsCache, query)
+		if err != nil {
+			if err := logsFailed("memory usage", err); err != nil {
+				return nil, nil, err
+			}
+		} else {
+			values[sdktypes.ComparisonMetricMeanMemoryUsageRate] = meanValue(memory.MeanUsageRate, memory.Conf95UsageRate, n)
+			setPercentile(values, sdktypes.ComparisonMetricP95MemoryUsageRate, memory.P95UsageRate, n)
+		}
+	}
+
+	// Failed calculators may succeed later, e.g. after a throttled query, so the values are not final.
+	if complete && len(warnings) == 0 {
+		periodCache.Set(key, maps.Clone(values))
+	}
+	return values, warnings, nil
+}
+
+// compareValues returns the deltas of a metric between the periods and whether the change is significant.
+func compareValues(
+	metric sdktypes.ComparisonMetric,
+	current, previous sdktypes.PeriodValue,
+	options sdktypes.PeriodComparisonOptions,
+) sdktypes.MetricComparison {
+	comparison := sdktypes.MetricComparison{
+		Metric:        metric,
+		Current:       current,
+		Previous:      previous,
+		AbsoluteDelta: current.Value - previous.Value,
+	}
+	relevant := comparison.AbsoluteDelta != 0
+	if previous.Value != 0 {
+		relativeDelta := comparison.AbsoluteDelta / math.Abs(previous.Value)
+		comparison.RelativeDelta = &relativeDelta
+		relevant = math.Abs(relativeDelta) >= options.MinRelativeChange
+	}
+	if current.StdError == nil || previous.StdError == nil {
+		comparison.Significant = relevant
+		return comparison
+	}
+	pValue := 1.0
+	if se := math.Hypot(*current.StdError, *previous.StdError); se > 0 {
+		pValue = math.Erfc(math.Abs(comparison.AbsoluteDelta) / se / math.Sqrt2)
+	} else if comparison.AbsoluteDelta != 0 {
+		pValue = 0
+	}
+	comparison.PValue = &pValue
+	comparison.Significant = relevant && pValue < options.SignificanceLevel
+	return comparison
+}
+
+// proportionValue returns the value of a rate of n invocations with the standard error of a proportion.
+func proportionValue(rate, n float64) sdktypes.PeriodValue {
+	return sdktypes.PeriodValue{Value: rate, SampleSize: n, StdError: stdError(math.Sqrt(rate * (1 - rate) / n))}
+}
+
+// meanValue returns the value of a mean with the standard error derived from its 95% confidence interval,
+// which is only known from 30 values on.
+func meanValue(mean float64, conf95 *float64, n float64) sdktypes.PeriodValue {
+	value := sdktypes.PeriodValue{Value: mean, SampleSize: n}
+	if conf95 != nil {
+		value.StdError = stdError(*conf95 / 1.96)
+	}
+	return value
+}
+
+// setPercentile sets the value of a percentile if there were enough values to calculate it.
+func setPercentile(values map[sdktypes.ComparisonMetric]sdktypes.PeriodValue, metric sdktypes.ComparisonMetric, percentile *float64, n float64) {
+	if percentile != nil {
+		values[metric] = sdktypes.PeriodValue{Value: *percentile, SampleSize: n}
+	}
+}
+
+func stdError(value float64) *float64 {
+	return &value
+}
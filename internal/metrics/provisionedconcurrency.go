@@ -0,0 +1,242 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/pricing"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetProvisionedConcurrencyStatistics returns the provisioned concurrency config of an AWS Lambda function
+// qualifier (version) together with its utilization and spillover over a specified time range.
+func GetProvisionedConcurrencyStatistics(
+	ctx context.Context,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	pcClient sdkinterfaces.ProvisionedConcurrencyClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.ProvisionedConcurrencyReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	result := &sdktypes.ProvisionedConcurrencyReturn{
+		Invocations:         invocationsSum,
+		UtilizationOverTime: []sdktypes.MetricPoint{},
+		FunctionName:        query.FunctionName,
+		Qualifier:           query.Qualifier,
+		StartTime:           query.StartTime,
+		EndTime:             query.EndTime,
+	}
+
+	config, err := pcClient.GetProvisionedConcurrencyConfig(ctx, &lambda.GetProvisionedConcurrencyConfigInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	var notFound *lambdatypes.ProvisionedConcurrencyConfigNotFoundException
+	switch {
+	case errors.As(err, &notFound):
+		return result, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get provisioned concurrency config: %w", err)
+	}
+	result.Configured = true
+	result.RequestedConcurrency = aws.ToInt32(config.RequestedProvisionedConcurrentExecutions)
+	result.AllocatedConcurrency = aws.ToInt32(config.AllocatedProvisionedConcurrentExecutions)
+	result.AvailableConcurrency = aws.ToInt32(config.AvailableProvisionedConcurrentExecutions)
+	result.Status = string(config.Status)
+
+	period := seriesPeriod(query)
+	maxResults, err := cwFetcher.FetchMetricSeries(ctx, query, "ProvisionedConcurrencyUtilization", "Maximum", period)
+	if err != nil {
+		return nil, fmt.Errorf("fetch provisioned concurrency utilization metric: %w", err)
+	}
+	result.UtilizationOverTime = metricPoints(maxResults)
+	for _, point := range result.UtilizationOverTime {
+		result.MaxUtilization = math.Max(result.MaxUtilization, point.Value)
+	}
+
+	avgResults, err := cwFetcher.FetchMetricSeries(ctx, query, "ProvisionedConcurrencyUtilization", "Average", period)
+	if err != nil {
+		return nil, fmt.Errorf("fetch provisioned concurrency utilization metric: %w", err)
+	}
+	if avgPoints := metricPoints(avgResults); len(avgPoints) > 0 {
+		var sum float64
+		for _, point := range avgPoints {
+			sum += point.Value
+		}
+		result.MeanUtilization = sum / float64(len(avgPoints))
+	}
+
+	for metricName, target := range map[string]*float64{
+		"ProvisionedConcurrencyInvocations":          &result.ProvisionedInvocations,
+		"ProvisionedConcurrencySpilloverInvocations": &result.SpilloverInvocations,
+	} {
+		results, err := cwFetcher.FetchMetric(ctx, query, metricName, "Sum")
+		if err != nil {
+			return nil, fmt.Errorf("fetch %s metric: %w", metricName, err)
+		}
+		sum, err := utils.SumMetricValues(results)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s metric data: %w", metricName, err)
+		}
+		*target = sum
+	}
+	result.SpilloverRate = result.SpilloverInvocations / invocationsSum
+	return result, nil
+}
+
+// GetProvisionedConcurrencyRecommendation derives the provisioned concurrency of an AWS Lambda function
+// qualifier (version) needed to eliminate a target share of its cold starts over a specified time range.
+//
+// The concurrency profile is taken from the REPORT lines: a cold start is eliminated if the number
+// of invocations in flight at its start, including itself, does not exceed the provisioned concurrency.
+// The costs compare the duration charges of the observed invocations on-demand against the
+// provisioned concurrency charges plus the duration of the invocations that would spill over.
+// The REPORT lines are at most 10000, so their durations are scaled to all invocations of the Invocations metric.
+func GetProvisionedConcurrencyRecommendation(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+	targetColdStartReduction float64,
+) (*sdktypes.ProvisionedConcurrencyRecommendationReturn, error) {
+
+	if targetColdStartReduction <= 0 || targetColdStartReduction > 1 {
+		return nil, fmt.Errorf("target cold start reduction must be in (0, 1], got %v", targetColdStartReduction)
+	}
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	var memorySizeMB float64
+	architecture := pricing.ArchitectureX86
+	if funcConfig.Configuration != nil {
+		memorySizeMB = float64(aws.ToInt32(funcConfig.Configuration.MemorySize))
+		architecture = functionArchitecture(funcConfig.Configuration.Architectures)
+	}
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	if memorySizeMB == 0 && len(reports) > 0 {
+		memorySizeMB = reports[0].MemorySizeMB
+	}
+
+	levels := concurrencyLevels(reports)
+	var coldStartLevels []int
+	for i, report := range reports {
+		if report.IsColdStart() {
+			coldStartLevels = append(coldStartLevels, levels[i])
+		}
+	}
+	sort.Ints(coldStartLevels)
+	_, peak := peakConcurrency(reports, query)
+
+	result := &sdktypes.ProvisionedConcurrencyRecommendationReturn{
+		TargetColdStartReduction: targetColdStartReduction,
+		PeakConcurrency:          peak,
+		ColdStarts:               len(coldStartLevels),
+		Invocations:              int(invocationsSum),
+		SampledInvocations:       len(reports),
+		MemorySizeMB:             memorySizeMB,
+		Architecture:             architecture,
+		Warnings:                 []string{},
+		FunctionName:             query.FunctionName,
+		Qualifier:                query.Qualifier,
+		StartTime:                query.StartTime,
+		EndTime:                  query.EndTime,
+	}
+	if len(coldStartLevels) > 0 {
+		idx := int(math.Ceil(targetColdStartReduction*float64(len(coldStartLevels)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		result.RecommendedConcurrency = coldStartLevels[idx]
+		for _, level := range coldStartLevels {
+			if level <= result.RecommendedConcurrency {
+				result.EliminatedColdStarts++
+			}
+		}
+	}
+
+	prices := pricing.ForArchitecture(architecture)
+	var onDemandGBs, provisionedGBs, spilloverGBs float64
+	for i, report := range reports {
+		gbs := pricing.GBSeconds(report.BilledDurationMs, memorySizeMB)
+		onDemandGBs += gbs
+		if levels[i] <= result.RecommendedConcurrency {
+			// Provisioned concurrency bills the exact duration without init.
+			provisionedGBs += pricing.GBSeconds(report.DurationMs, memorySizeMB)
+		} else {
+			spilloverGBs += gbs
+		}
+	}
+	// The REPORT lines are a sample if the query limit is hit or logs are missing,
+	// while the provisioned concurrency is allocated over the whole window.
+	if len(reports) > 0 {
+		scale := invocationsSum / float64(len(reports))
+		onDemandGBs *= scale
+		provisionedGBs *= scale
+		spilloverGBs *= scale
+	}
+	if len(reports) >= logsQueryLimit {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"only the first %d REPORT lines are analyzed, the concurrency after %s is not observed and the costs are scaled to %.0f invocations",
+			logsQueryLimit, reports[len(reports)-1].Timestamp.Format(time.RFC3339), invocationsSum))
+	}
+	windowSeconds := query.EndTime.Sub(query.StartTime).Seconds()
+	allocationGBs := float64(result.RecommendedConcurrency) * memorySizeMB / 1024 * windowSeconds
+
+	result.OnDemandCost = onDemandGBs * prices.PerGBSecond
+	result.ProvisionedCost = allocationGBs*prices.ProvisionedPerGBSecond +
+		provisionedGBs*prices.ProvisionedDurationPerGBSecond +
+		spilloverGBs*prices.PerGBSecond
+	result.Savings = result.OnDemandCost - result.ProvisionedCost
+	return result, nil
+}
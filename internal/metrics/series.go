@@ -0,0 +1,150 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// seriesPeriod returns the CloudWatch period in seconds used for time series of the query interval.
+func seriesPeriod(query sdktypes.FunctionQuery) int32 {
+	return int32(utils.BucketSize(query.StartTime, query.EndTime, timeSeriesBuckets) / time.Second)
+}
+
+// metricPoints converts CloudWatch metric data into a time series sorted by ascending timestamp.
+func metricPoints(results []types.MetricDataResult) []sdktypes.MetricPoint {
+	points := []sdktypes.MetricPoint{}
+	for _, result := range results {
+		for i, value := range result.Values {
+			if i >= len(result.Timestamps) {
+				break
+			}
+			points = append(points, sdktypes.MetricPoint{Timestamp: result.Timestamps[i].UTC(), Value: value})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Timestamp.Before(points[j].Timestamp)
+	})
+	return points
+}
+
+// invocationInterval returns the time an invocation occupied its environment,
+// including the init or restore phase on cold starts.
+func invocationInterval(report logparser.InvocationReport) (time.Time, time.Time) {
+	return initStart(report), report.Timestamp
+}
+
+// concurrencyLevels returns for every report the number of invocations in flight at its start,
+// including itself. With n environments kept warm, the invocations with a level of at most n
+// would have been served by one of them.
+func concurrencyLevels(reports []logparser.InvocationReport) []int {
+	starts := make([]time.Time, len(reports))
+	ends := make([]time.Time, len(reports))
+	for i, report := range reports {
+		starts[i], ends[i] = invocationInterval(report)
+	}
+	sortedStarts := append([]time.Time(nil), starts...)
+	sortedEnds := append([]time.Time(nil), ends...)
+	sort.Slice(sortedStarts, func(i, j int) bool { return sortedStarts[i].Before(sortedStarts[j]) })
+	sort.Slice(sortedEnds, func(i, j int) bool { return sortedEnds[i].Before(sortedEnds[j]) })
+
+	levels := make([]int, len(reports))
+	for i, start := range starts {
+		started := sort.Search(len(sortedStarts), func(j int) bool { return sortedStarts[j].After(start) })
+		ended := sort.Search(len(sortedEnds), func(j int) bool { return sortedEnds[j].After(start) })
+		levels[i] = started - ended
+		// Invocations without a duration end at their start and are not counted above.
+		if !ends[i].After(start) {
+			levels[i]++
+		}
+	}
+	return levels
+}
+
+// peakConcurrency returns the maximum number of invocations in flight per time bucket of the
+// query interval and over the whole interval.
+func peakConcurrency(reports []logparser.InvocationReport, query sdktypes.FunctionQuery) ([]sdktypes.MetricPoint, int) {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, 2*len(reports))
+	for _, report := range reports {
+		start, end := invocationInterval(report)
+		events = append(events, event{at: start, delta: 1}, event{at: end, delta: -1})
+	}
+	// Invocations ending at the same time another one starts do not overlap.
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	bucketSize := utils.BucketSize(query.StartTime, query.EndTime, timeSeriesBuckets)
+	points := []sdktypes.MetricPoint{}
+	var current, peak, next int
+	for bucket := query.StartTime.Truncate(bucketSize); bucket.Before(query.EndTime); bucket = bucket.Add(bucketSize) {
+		bucketEnd := bucket.Add(bucketSize)
+		for next < len(events) && events[next].at.Before(bucket) {
+			current += events[next].delta
+			next++
+		}
+		bucketPeak := current
+		for next < len(events) && events[next].at.Before(bucketEnd) {
+			current += events[next].delta
+			if current > bucketPeak {
+				bucketPeak = current
+			}
+			next++
+		}
+		if bucketPeak > peak {
+			peak = bucketPeak
+		}
+		points = append(points, sdktypes.MetricPoint{Timestamp: bucket, Value: float64(bucketPeak)})
+	}
+	return points, peak
+}
+
+// windowPeriod returns a CloudWatch period in seconds covering the whole query interval,
+// such that statistics like percentiles are calculated over a single period.
+func windowPeriod(query sdktypes.FunctionQuery) int32 {
+	window := query.EndTime.Sub(query.StartTime)
+	minutes := int32((window + time.Minute - 1) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	return minutes * 60
+}
+
+// singleValue returns the largest value of the metric data, or nil if there is none.
+func singleValue(results []types.MetricDataResult) *float64 {
+	var value *float64
+	for _, result := range results {
+		for _, v := range result.Values {
+			if value == nil || v > *value {
+				val := v
+				value = &val
+			}
+		}
+	}
+	return value
+}
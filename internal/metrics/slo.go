@@ -0,0 +1,187 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+	"github.com/dominikhei/serverless-statistics/internal/queries"
+	"github.com/dominikhei/serverless-statistics/internal/slo"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// GetSLOReport evaluates SLO specs of an AWS Lambda function and qualifier (version) over their rolling compliance
+// windows ending at the end time of the query, which is aligned to the bucket size. The start time of the query
+// is ignored. The invocations of every indicator are fetched once, in buckets covering the longest window.
+func GetSLOReport(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	query sdktypes.FunctionQuery,
+	specs []sdktypes.SLOSpec,
+) (*sdktypes.SLOReportReturn, error) {
+
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("at least one slo spec is required")
+	}
+	normalized := make([]sdktypes.SLOSpec, 0, len(specs))
+	var longestWindow time.Duration
+	for _, spec := range specs {
+		spec, err := slo.Normalize(spec)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, spec)
+		longestWindow = max(longestWindow, spec.Window)
+	}
+
+	end := query.EndTime.UTC().Truncate(slo.BucketSize)
+	bucketQuery := query
+	bucketQuery.StartTime = end.Add(-longestWindow).Truncate(slo.BucketSize)
+	bucketQuery.EndTime = end
+	result := &sdktypes.SLOReportReturn{
+		SLOs:         []sdktypes.SLOStatus{},
+		FunctionName: query.FunctionName,
+		Qualifier:    query.Qualifier,
+		StartTime:    bucketQuery.StartTime,
+		EndTime:      end,
+	}
+
+	fetched := make(map[string][]slo.Bucket)
+	for _, spec := range normalized {
+		key := string(spec.Indicator)
+		if spec.Indicator == sdktypes.SLOIndicatorLatency {
+			key = fmt.Sprintf("%s:%g", key, spec.LatencyThresholdMs)
+		}
+		buckets, ok := fetched[key]
+		if !ok {
+			var err error
+			buckets, err = sloBuckets(ctx, logsFetcher, cwFetcher, bucketQuery, spec)
+			if err != nil {
+				return nil, fmt.Errorf("slo %q: %w", spec.Name, err)
+			}
+			fetched[key] = buckets
+		}
+		status := slo.Evaluate(spec, buckets, end)
+		for _, alert := range status.Alerts {
+			if alert.Firing {
+				result.FiringAlerts++
+			}
+		}
+		result.SLOs = append(result.SLOs, status)
+	}
+	return result, nil
+}
+
+// sloBuckets counts the good and total invocations of the indicator of the spec per bucket. Invocations
+// are read from the Invocations and Errors metrics for the success indicator, and from the REPORT lines otherwise.
+func sloBuckets(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	query sdktypes.FunctionQuery,
+	spec sdktypes.SLOSpec,
+) ([]slo.Bucket, error) {
+	period := int32(slo.BucketSize / time.Second)
+	minutes := int(slo.BucketSize / time.Minute)
+	escapedQualifier := strings.ReplaceAll(query.Qualifier, "$", "\\$")
+
+	counts := make(map[time.Time]*slo.Bucket)
+	bucket := func(start time.Time) *slo.Bucket {
+		start = start.UTC().Truncate(slo.BucketSize)
+		if counts[start] == nil {
+			counts[start] = &slo.Bucket{Start: start}
+		}
+		return counts[start]
+	}
+
+	switch spec.Indicator {
+	case sdktypes.SLOIndicatorSuccess:
+		invocationsResults, err := cwFetcher.FetchMetricSeries(ctx, query, "Invocations", "Sum", period)
+		if err != nil {
+			return nil, fmt.Errorf("fetch invocations metric: %w", err)
+		}
+		errorsResults, err := cwFetcher.FetchMetricSeries(ctx, query, "Errors", "Sum", period)
+		if err != nil {
+			return nil, fmt.Errorf("fetch errors metric: %w", err)
+		}
+		for _, point := range metricPoints(invocationsResults) {
+			b := bucket(point.Timestamp)
+			b.Total += point.Value
+			b.Good += point.Value
+		}
+		for _, point := range metricPoints(errorsResults) {
+			b := bucket(point.Timestamp)
+			b.Good = max(b.Good-point.Value, 0)
+		}
+	default:
+		rows, err := logsFetcher.RunQuery(ctx, query, fmt.Sprintf(queries.LambdaTimeoutsPerBinWithVersion, escapedQualifier, minutes))
+		if err != nil {
+			return nil, fmt.Errorf("run logs insights query: %w", err)
+		}
+		bad := "timeouts"
+		if spec.Indicator == sdktypes.SLOIndicatorLatency {
+			bad = "slowInvocations"
+			slowRows, err := logsFetcher.RunQuery(ctx, query,
+				fmt.Sprintf(queries.LambdaSlowInvocationsPerBinWithVersion, escapedQualifier, spec.LatencyThresholdMs, minutes))
+			if err != nil {
+				return nil, fmt.Errorf("run logs insights query: %w", err)
+			}
+			rows = append(rows, slowRows...)
+		}
+		for _, row := range rows {
+			start, err := logparser.ParseTimestamp(row["bucket"])
+			if err != nil {
+				return nil, fmt.Errorf("parse bucket from logs: %w", err)
+			}
+			invocations, err := parseCount(row, "invocations")
+			if err != nil {
+				return nil, err
+			}
+			badInvocations, err := parseCount(row, bad)
+			if err != nil {
+				return nil, err
+			}
+			b := bucket(start)
+			b.Total += invocations
+			b.Good += invocations - badInvocations
+			b.Good = max(b.Good, 0)
+		}
+	}
+
+	buckets := make([]slo.Bucket, 0, len(counts))
+	for _, b := range counts {
+		buckets = append(buckets, *b)
+	}
+	return buckets, nil
+}
+
+// parseCount parses a count of a Logs Insights result row, which is 0 if the field is missing.
+func parseCount(row map[string]string, field string) (float64, error) {
+	if row[field] == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(row[field], 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s from logs: %w", field, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// summarize calculates the summary statistics of vals.
+// It returns nil for an empty slice, so callers can omit the statistics.
+func summarize(vals []float64) *sdktypes.SummaryStatistics {
+	stats, err := utils.CalcSummaryStats(vals)
+	if err != nil {
+		return nil
+	}
+	return &sdktypes.SummaryStatistics{
+		Min:    stats.Min,
+		Max:    stats.Max,
+		Median: stats.Median,
+		Mean:   stats.Mean,
+		P95:    stats.P95,
+		P99:    stats.P99,
+		Conf95: stats.ConfInt95,
+		Count:  len(vals),
+	}
+}
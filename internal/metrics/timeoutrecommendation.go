@@ -0,0 +1,171 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	sdkinterfaces "github.com/dominikhei/serverless-statistics/internal/interfaces"
+	"github.com/dominikhei/serverless-statistics/internal/utils"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	// timeoutHeadroom is the factor between the p99.9 duration and the recommended timeout.
+	timeoutHeadroom = 1.5
+	// timeoutCloseThreshold is the share of the timeout from which on the p99.9 duration is too close to it.
+	timeoutCloseThreshold = 0.8
+	// timeoutTooHighFactor is the factor between the timeout and the recommended timeout from which on
+	// the timeout is too high.
+	timeoutTooHighFactor = 3
+	// maxTimeoutSeconds is the largest timeout Lambda allows.
+	maxTimeoutSeconds = 900
+)
+
+// GetTimeoutRecommendation compares the duration tail (p99, p99.9 and max) of the invocations of an AWS Lambda
+// function over a specified time range and qualifier (version) with its configured timeout.
+// The timeout is too close if the p99.9 duration reaches 80% of it, and too high if it is at least three times
+// the recommended timeout, which is the p99.9 duration plus 50% headroom. The estimated timeout rate under the
+// recommended timeout assumes that invocations which timed out hang and would time out again.
+func GetTimeoutRecommendation(
+	ctx context.Context,
+	logsFetcher sdkinterfaces.LogsInsightsFetcher,
+	cwFetcher sdkinterfaces.CloudWatchFetcher,
+	lambdaClient sdkinterfaces.LambdaClient,
+	invocationsCache sdkinterfaces.Cache,
+	query sdktypes.FunctionQuery,
+) (*sdktypes.TimeoutRecommendationReturn, error) {
+
+	invocationsSum, err := getInvocationsSum(ctx, cwFetcher, invocationsCache, query)
+	if err != nil {
+		return nil, err
+	}
+	if invocationsSum == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	funcConfig, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(query.FunctionName),
+		Qualifier:    aws.String(query.Qualifier),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function configuration: %w", err)
+	}
+	if funcConfig.Configuration == nil || funcConfig.Configuration.Timeout == nil {
+		return nil, fmt.Errorf("function configuration of %s has no timeout", query.FunctionName)
+	}
+	timeout := *funcConfig.Configuration.Timeout
+
+	reports, err := getReports(ctx, logsFetcher, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+	}
+
+	result := &sdktypes.TimeoutRecommendationReturn{
+		TimeoutSeconds: timeout,
+		Invocations:    len(reports),
+		FunctionName:   query.FunctionName,
+		Qualifier:      query.Qualifier,
+		StartTime:      query.StartTime,
+		EndTime:        query.EndTime,
+	}
+	// Durations of invocations that timed out are cut off at the timeout, so only completed ones form the tail.
+	completed := []float64{}
+	for _, report := range reports {
+		if report.Status == "timeout" {
+			result.TimedOutInvocations++
+		} else {
+			completed = append(completed, report.DurationMs)
+		}
+	}
+	result.TimeoutRate = float64(result.TimedOutInvocations) / float64(result.Invocations)
+
+	if len(completed) == 0 {
+		result.Assessment = sdktypes.TimeoutAssessmentTooClose
+		result.RecommendedTimeoutSeconds = timeout
+		result.EstimatedTimeoutRate = result.TimeoutRate
+		result.Justification = fmt.Sprintf(
+			"all %d invocations timed out, so the duration tail is unknown; check whether they hang before raising the timeout",
+			result.Invocations)
+		return result, nil
+	}
+
+	sort.Float64s(completed)
+	result.P99DurationMs = utils.Quantile(0.99, completed)
+	result.P999DurationMs = utils.Quantile(0.999, completed)
+	result.MaxDurationMs = completed[len(completed)-1]
+
+	recommended := recommendedTimeout(result.P999DurationMs)
+	timeoutMs := float64(timeout) * 1000
+	switch {
+	case result.P999DurationMs >= timeoutCloseThreshold*timeoutMs:
+		result.Assessment = sdktypes.TimeoutAssessmentTooClose
+		result.Justification = fmt.Sprintf(
+			"p99.9 duration of %.0f ms is %.0f%% of the timeout of %d s, slow invocations are cut off; %d s leaves 50%% headroom above p99.9",
+			result.P999DurationMs, result.P999DurationMs/timeoutMs*100, timeout, recommended)
+	case float64(timeout) >= timeoutTooHighFactor*float64(recommended):
+		result.Assessment = sdktypes.TimeoutAssessmentTooHigh
+		result.Justification = fmt.Sprintf(
+			"timeout of %d s is %.0fx the p99.9 duration of %.0f ms, hung invocations are billed up to the timeout; %d s leaves 50%% headroom above p99.9",
+			timeout, timeoutMs/result.P999DurationMs, result.P999DurationMs, recommended)
+	default:
+		result.Assessment = sdktypes.TimeoutAssessmentAppropriate
+		recommended = timeout
+		result.Justification = fmt.Sprintf(
+			"p99.9 duration of %.0f ms leaves enough headroom below the timeout of %d s", result.P999DurationMs, timeout)
+	}
+	result.RecommendedTimeoutSeconds = recommended
+
+	timedOut := result.TimedOutInvocations
+	for _, duration := range completed {
+		if duration > float64(recommended)*1000 {
+			timedOut++
+		}
+	}
+	result.EstimatedTimeoutRate = float64(timedOut) / float64(result.Invocations)
+	if recommended < timeout {
+		result.EstimatedBilledSecondsSaved = float64(result.TimedOutInvocations) * float64(timeout-recommended)
+	}
+
+	if result.TimedOutInvocations > 0 && result.Assessment != sdktypes.TimeoutAssessmentAppropriate {
+		result.Justification += "; invocations that timed out are assumed to hang and time out under the recommended timeout too"
+	}
+	if len(completed) < 1000 {
+		result.Justification += fmt.Sprintf("; p99.9 is based on only %d completed invocations", len(completed))
+	}
+	return result, nil
+}
+
+// recommendedTimeout returns the p99.9 duration plus headroom, rounded up to whole seconds
+// and bounded by the limits of Lambda.
+func recommendedTimeout(p999DurationMs float64) int32 {
+	seconds := int32(math.Ceil(p999DurationMs * timeoutHeadroom / 1000))
+	if seconds < 1 {
+		return 1
+	}
+	if seconds > maxTimeoutSeconds {
+		return maxTimeoutSeconds
+	}
+	return seconds
+}
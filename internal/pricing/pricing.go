@@ -0,0 +1,61 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pricing holds the AWS Lambda list prices used for cost estimates.
+// The prices are the ones of us-east-1 without free tier and volume discounts,
+// so estimates are meant for comparisons rather than for billing.
+package pricing
+
+// Architectures as reported by the Lambda API.
+const (
+	ArchitectureX86   = "x86_64"
+	ArchitectureArm64 = "arm64"
+)
+
+// Prices holds the prices in USD of one architecture.
+type Prices struct {
+	PerMillionRequests             float64
+	PerGBSecond                    float64 // On-demand duration
+	ProvisionedPerGBSecond         float64 // Provisioned concurrency, charged while it is configured
+	ProvisionedDurationPerGBSecond float64 // Duration of invocations served by provisioned concurrency
+}
+
+var prices = map[string]Prices{
+	ArchitectureX86: {
+		PerMillionRequests:             0.20,
+		PerGBSecond:                    0.0000166667,
+		ProvisionedPerGBSecond:         0.0000041667,
+		ProvisionedDurationPerGBSecond: 0.0000097222,
+	},
+	ArchitectureArm64: {
+		PerMillionRequests:             0.20,
+		PerGBSecond:                    0.0000133334,
+		ProvisionedPerGBSecond:         0.0000033334,
+		ProvisionedDurationPerGBSecond: 0.0000077778,
+	},
+}
+
+// ForArchitecture returns the prices of an architecture.
+// Unknown or empty architectures fall back to x86_64, the Lambda default.
+func ForArchitecture(architecture string) Prices {
+	if p, ok := prices[architecture]; ok {
+		return p
+	}
+	return prices[ArchitectureX86]
+}
+
+// GBSeconds converts a duration in milliseconds at a memory size in MB into GB-seconds.
+func GBSeconds(durationMs, memorySizeMB float64) float64 {
+	return durationMs / 1000 * memorySizeMB / 1024
+}
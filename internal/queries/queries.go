@@ -31,17 +31,61 @@ fields @timestamp, @message
 | parse @message "Duration: * ms" as durationMs
 `
 
+// LambdaColdStartRateWithVersion counts the invocations and cold starts from the REPORT lines
+// of the text log format and the platform.report records of the JSON log format.
 const LambdaColdStartRateWithVersion = `
-filter @type = "REPORT" and @logStream like /\[%s\]/
-| parse @message /REPORT RequestId: (?<requestId>[a-f0-9-]+)/
+filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| parse @message /REPORT RequestId: (?<textRequestId>[a-f0-9-]+)/
+| parse @message /"requestId":\s*"(?<jsonRequestId>[a-f0-9-]+)"/
+| parse @message /(?<coldStartSignal>Init Duration|Restore Duration|"initDurationMs"|"restoreDurationMs")/
+| fields coalesce(textRequestId, jsonRequestId) as requestId
 | stats
     count_distinct(requestId) as totalInvocations,
-    sum(strcontains(@message, "Init Duration")) as coldStartLines
+    count(coldStartSignal) as coldStartLines
+`
+
+// LambdaErrorSignalsPerRequestWithVersion counts the error signals of every invocation that
+// has at least one of them. Platform signals are taken from REPORT and runtime lines,
+// unhandled exceptions from the runtime specific markers and logged errors from the log level.
+// The status of the invocation is only logged in the JSON log format, and for crashes in REPORT lines.
+const LambdaErrorSignalsPerRequestWithVersion = `
+filter @logStream like /\[%s\]/ and ispresent(@requestId)
+| parse @message /(?<timeoutSignal>Status: timeout)/
+| parse @message /(?<outOfMemorySignal>Runtime\.OutOfMemory)/
+| parse @message /(?<exitErrorSignal>Runtime\.ExitError)/
+| parse @message /(?<unhandledSignal>Invoke Error|Traceback \(most recent call last\))/
+| parse @message /(?<errorLogSignal>\[ERROR\]|\tERROR\t)/
+| parse @message /(?<failedStatusSignal>Status: error|"status":\s*"error"|"status":\s*"failure"|"status":\s*"timeout")/
+| parse @message /(?<successStatusSignal>"status":\s*"success")/
+| stats
+    count(timeoutSignal) as timeoutLines,
+    count(outOfMemorySignal) as outOfMemoryLines,
+    count(exitErrorSignal) as exitErrorLines,
+    count(unhandledSignal) as unhandledErrorLines,
+    count(errorLogSignal) as errorLogLines,
+    count(failedStatusSignal) as failedStatusLines,
+    count(successStatusSignal) as successStatusLines
+    by @requestId
+| filter timeoutLines > 0 or outOfMemoryLines > 0 or exitErrorLines > 0 or unhandledErrorLines > 0 or errorLogLines > 0
+| limit 10000
+`
+
+// LambdaLoggedRecordsPerRequestWithVersion counts how often the given key of an event record occurs
+// in the log lines of every invocation. Records are only found if the function logs the event it receives.
+const LambdaLoggedRecordsPerRequestWithVersion = `
+filter @logStream like /\[%s\]/ and ispresent(@requestId) and @message like "%s"
+| fields (strlen(@message) - strlen(replace(@message, "%s", ""))) / %d as recordKeys
+| stats sum(recordKeys) as records by @requestId
+| limit 10000
 `
 
-const LambdaErrorCountWithVersion = `
-filter @message like /(?i)(ERROR)/ and @logStream like /\[%s\]/
-| stats count_distinct(@requestId) as errorCount
+// LambdaInitFailuresWithVersion counts failed init phases from INIT_REPORT lines of the text log format
+// and platform.initReport records of the JSON log format.
+const LambdaInitFailuresWithVersion = `
+filter @logStream like /\[%s\]/
+    and ((@message like /INIT_REPORT/ and @message like /Status: (error|timeout)/)
+    or (@message like /"type":\s*"platform\.initReport"/ and @message like /"status":\s*"(error|failure|timeout)"/))
+| stats count() as initFailures
 `
 
 const LambdaUniqueRequestsWithVersion = `
@@ -65,9 +109,78 @@ filter @type = "REPORT" and @logStream like /\[%s\]/
 | stats sum(@duration) as totalDuration, sum(@billedDuration) as totalBilledDuration
 `
 
+// LambdaColdStartDurationQueryWithVersion returns the init duration of on-demand cold starts
+// and the restore duration of SnapStart cold starts as coldStartDurationMs, from REPORT lines
+// and platform.report records.
 const LambdaColdStartDurationQueryWithVersion = `
 fields @timestamp, @message
-| filter @type = "REPORT" and @message like /Init Duration/ and @logStream like /\[%s\]/
-| parse @message "Init Duration: * ms" as coldStartDurationMs
+| filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| filter @message like /(Init|Restore) Duration|"(init|restore)DurationMs"/
+| parse @message /Init Duration: (?<initDurationMs>[\d.]+) ms/
+| parse @message /\tRestore Duration: (?<restoreDurationMs>[\d.]+) ms/
+| parse @message /"initDurationMs":\s*(?<jsonInitDurationMs>[\d.]+)/
+| parse @message /"restoreDurationMs":\s*(?<jsonRestoreDurationMs>[\d.]+)/
+| fields coalesce(initDurationMs, restoreDurationMs, jsonInitDurationMs, jsonRestoreDurationMs) as coldStartDurationMs
 | filter ispresent(coldStartDurationMs)
 `
+
+// LambdaReportRecordsQueryWithVersion returns the REPORT lines of the text log format and the
+// platform.report records of the JSON log format, which Logs Insights does not assign a @type to.
+const LambdaReportRecordsQueryWithVersion = `
+fields @timestamp, @logStream, @requestId, @message
+| filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| sort @timestamp asc
+| limit 10000
+`
+
+const LambdaOutOfMemorySignalsWithVersion = `
+filter @logStream like /\[%s\]/ and (@message like /Runtime\.OutOfMemory/ or @message like /signal: killed/)
+| stats count() as signalLines by @requestId
+| limit 10000
+`
+
+// LambdaInitRecordsQueryWithVersion returns the REPORT lines together with the lines the platform
+// writes when it initializes or restores an execution environment, in text and in JSON log format.
+const LambdaInitRecordsQueryWithVersion = `
+fields @timestamp, @logStream, @requestId, @message
+| filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /INIT_START|INIT_REPORT|RESTORE_START|RESTORE_REPORT|"platform\.(initStart|initReport|restoreStart|restoreReport|report)"/)
+| sort @timestamp asc
+| limit 10000
+`
+
+// LambdaTimeoutsPerBinWithVersion counts the invocations and the timed out invocations
+// per bin of the given number of minutes, from REPORT lines and platform.report records.
+const LambdaTimeoutsPerBinWithVersion = `
+filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| parse @message /(?<timeoutSignal>Status: timeout|"status":\s*"timeout")/
+| stats count(*) as invocations, count(timeoutSignal) as timeouts by bin(%dm) as bucket
+| limit 10000
+`
+
+// LambdaSlowInvocationsPerBinWithVersion counts the invocations longer than the given duration in ms
+// per bin of the given number of minutes, from REPORT lines and platform.report records.
+const LambdaSlowInvocationsPerBinWithVersion = `
+filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| parse @message /"durationMs":\s*(?<jsonDurationMs>[\d.]+)/
+| filter coalesce(@duration, jsonDurationMs) > %g
+| stats count(*) as slowInvocations by bin(%dm) as bucket
+| limit 10000
+`
+
+// LambdaColdStartsPerBinWithVersion counts the on-demand and SnapStart cold starts
+// per bin of the given number of minutes, from REPORT lines and platform.report records.
+const LambdaColdStartsPerBinWithVersion = `
+filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| parse @message /(?<coldStartSignal>Init Duration|Restore Duration|"initDurationMs"|"restoreDurationMs")/
+| stats count(coldStartSignal) as coldStarts by bin(%dm) as bucket
+| limit 10000
+`
+
+// LambdaMaxMemoryUsedPerBinWithVersion returns the max memory used in MB per bin of the given number of minutes,
+// from REPORT lines and platform.report records.
+const LambdaMaxMemoryUsedPerBinWithVersion = `
+filter @logStream like /\[%s\]/ and (@type = "REPORT" or @message like /"type":\s*"platform\.report"/)
+| parse @message /"maxMemoryUsedMB":\s*(?<jsonMaxMemoryUsedMB>[\d.]+)/
+| stats max(coalesce(@maxMemoryUsed / 1000 / 1000, jsonMaxMemoryUsedMB)) as maxMemoryUsedMB by bin(%dm) as bucket
+| limit 10000
+`
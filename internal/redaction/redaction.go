@@ -0,0 +1,167 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redaction keeps secrets in environment variables out of the returns of the SDK,
+// which end up in reports and logs whenever they are serialized.
+package redaction
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"path"
+	"strings"
+	"sync"
+
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// DefaultNamePatterns are the glob patterns of names of environment variables that hold secrets.
+// They match whole words at the end of the name, so that e.g. TOKENIZER_MODEL is not a secret.
+var DefaultNamePatterns = []string{
+	"SECRET", "*_SECRET",
+	"SECRET_KEY", "*_SECRET_KEY",
+	"TOKEN", "*_TOKEN",
+	"PASSWORD", "*_PASSWORD",
+	"PASSWD", "*_PASSWD",
+	"API_KEY", "*_API_KEY",
+	"APIKEY", "*_APIKEY",
+	"PRIVATE_KEY", "*_PRIVATE_KEY",
+	"*_ACCESS_KEY",
+	"CREDENTIALS", "*_CREDENTIALS",
+	"CONNECTION_STRING", "*_CONNECTION_STRING",
+}
+
+const (
+	// RedactedValue replaces the values of redacted secrets.
+	RedactedValue = "[REDACTED]"
+	// hashPrefix marks values replaced by their hash.
+	hashPrefix = "hmac-sha256:"
+	// processKeySize is the size of the random key of the process in bytes.
+	processKeySize = 32
+	// minSecretLength is the minimum length of values that can be detected as secrets by their entropy.
+	minSecretLength = 20
+	// Thresholds in bits per character from which on values are considered random. Hex strings have
+	// at most 4 bits per character, so they need a lower threshold than base64 strings.
+	hexEntropyThreshold    = 3.0
+	base64EntropyThreshold = 4.5
+)
+
+// Redact applies the policy to the environment variables. It returns the values to report,
+// which are redacted or hashed for secrets unless they are revealed, and the visibility of every variable.
+func Redact(variables map[string]string, policy sdktypes.RedactionPolicy) (map[string]string, map[string]sdktypes.EnvironmentVariableVisibility) {
+	values := make(map[string]string, len(variables))
+	visibility := make(map[string]sdktypes.EnvironmentVariableVisibility, len(variables))
+	for name, value := range variables {
+		switch {
+		case !IsSecret(name, value, policy) || policy.RevealSecrets:
+			values[name] = value
+			visibility[name] = sdktypes.EnvironmentVariableRevealed
+		case policy.HashSecrets:
+			values[name] = Hash(value, policy.HashKey)
+			visibility[name] = sdktypes.EnvironmentVariableHashed
+		default:
+			values[name] = RedactedValue
+			visibility[name] = sdktypes.EnvironmentVariableRedacted
+		}
+	}
+	return values, visibility
+}
+
+// IsSecret returns true if the variable is not allowlisted and its name matches a default or policy
+// pattern, or, unless disabled, its value looks like a random key or token.
+func IsSecret(name, value string, policy sdktypes.RedactionPolicy) bool {
+	for _, allowed := range policy.Allowlist {
+		if strings.EqualFold(name, allowed) {
+			return false
+		}
+	}
+	upper := strings.ToUpper(name)
+	for _, patterns := range [][]string{DefaultNamePatterns, policy.NamePatterns} {
+		for _, pattern := range patterns {
+			if matched, err := path.Match(strings.ToUpper(pattern), upper); err == nil && matched {
+				return true
+			}
+		}
+	}
+	return !policy.DisableEntropyDetection && looksRandom(value)
+}
+
+// processKey is the key of the hashes if the policy has none, generated once per process.
+var processKey = sync.OnceValue(func() []byte {
+	key := make([]byte, processKeySize)
+	if _, err := rand.Read(key); err != nil {
+		panic("redaction: generate hash key: " + err.Error())
+	}
+	return key
+})
+
+// Hash returns the HMAC-SHA256 of a value with the key, or with a random key of the process if the key is empty,
+// so that changes of secrets can be detected without revealing them. Unlike a plain hash, the HMAC cannot be
+// brute-forced for short or guessable secrets without the key. Hashes are only comparable with the same key.
+func Hash(value string, key []byte) string {
+	if len(key) == 0 {
+		key = processKey()
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Entropy returns the Shannon entropy of the characters of a value in bits per character.
+func Entropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	var length int
+	for _, r := range value {
+		counts[r]++
+		length++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(length)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// looksRandom returns true if the value is a long hex or base64 string with high entropy.
+// Values with other characters, e.g. URLs, paths or sentences, are never considered random.
+func looksRandom(value string) bool {
+	if len(value) < minSecretLength {
+		return false
+	}
+	switch {
+	case isCharset(value, "0123456789abcdefABCDEF"):
+		return Entropy(value) >= hexEntropyThreshold
+	case isCharset(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/=_-"):
+		return Entropy(value) >= base64EntropyThreshold
+	default:
+		return false
+	}
+}
+
+// isCharset returns true if all characters of the value are in the charset.
+func isCharset(value, charset string) bool {
+	for _, r := range value {
+		if !strings.ContainsRune(charset, r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slo evaluates service level objectives over per-bucket counts of good and total invocations:
+// the attainment and error budget over the rolling compliance window, and multi-window burn rate alerts.
+package slo
+
+import (
+	"fmt"
+	"time"
+
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+const (
+	// BucketSize is the resolution the invocations are counted in. It is the shortest default alert window,
+	// and CloudWatch keeps metrics in this resolution for 63 days.
+	BucketSize = 5 * time.Minute
+	// DefaultWindow is the compliance window of specs without one.
+	DefaultWindow = 30 * 24 * time.Hour
+	// MaxWindow is the longest compliance window, as Logs Insights returns at most 10000 buckets.
+	MaxWindow = 10000 * BucketSize
+)
+
+// Bucket holds the good and total invocations that started in [Start, Start+BucketSize).
+type Bucket struct {
+	Start time.Time
+	Good  float64
+	Total float64
+}
+
+// DefaultBurnRateAlerts returns the two paging alerts of the multi-window, multi-burn-rate practice for a 30 day
+// window: 2% of the budget consumed within 1 hour (burn rate 14.4, reset after 5 minutes) and 5% within 6 hours
+// (burn rate 6, reset after 30 minutes).
+func DefaultBurnRateAlerts() []sdktypes.BurnRateAlert {
+	return []sdktypes.BurnRateAlert{
+		{Name: "fast-burn", LongWindow: time.Hour, ShortWindow: 5 * time.Minute, Threshold: 14.4},
+		{Name: "slow-burn", LongWindow: 6 * time.Hour, ShortWindow: 30 * time.Minute, Threshold: 6},
+	}
+}
+
+// Normalize fills the defaults of a spec and returns an error if it cannot be evaluated.
+func Normalize(spec sdktypes.SLOSpec) (sdktypes.SLOSpec, error) {
+	if spec.Window == 0 {
+		spec.Window = DefaultWindow
+	}
+	if spec.Alerts == nil {
+		spec.Alerts = DefaultBurnRateAlerts()
+	}
+	switch {
+	case spec.Indicator != sdktypes.SLOIndicatorSuccess && spec.Indicator != sdktypes.SLOIndicatorNoTimeout &&
+		spec.Indicator != sdktypes.SLOIndicatorLatency:
+		return spec, fmt.Errorf("slo %q: unknown indicator %q", spec.Name, spec.Indicator)
+	case spec.Objective <= 0 || spec.Objective >= 1:
+		return spec, fmt.Errorf("slo %q: objective must be between 0 and 1, got %v", spec.Name, spec.Objective)
+	case spec.Indicator == sdktypes.SLOIndicatorLatency && spec.LatencyThresholdMs <= 0:
+		return spec, fmt.Errorf("slo %q: latency threshold must be positive", spec.Name)
+	case spec.Window < BucketSize || spec.Window > MaxWindow:
+		return spec, fmt.Errorf("slo %q: window must be between %s and %s", spec.Name, BucketSize, MaxWindow)
+	}
+	for _, alert := range spec.Alerts {
+		if alert.ShortWindow <= 0 || alert.LongWindow < alert.ShortWindow || alert.LongWindow > spec.Window {
+			return spec, fmt.Errorf("slo %q: alert %q needs a short window within its long window within the slo window",
+				spec.Name, alert.Name)
+		}
+	}
+	return spec, nil
+}
+
+// Evaluate calculates the status of a normalized spec from the buckets of the windows ending at end,
+// which must be aligned to BucketSize. Windows that are not a multiple of BucketSize are extended to one.
+func Evaluate(spec sdktypes.SLOSpec, buckets []Bucket, end time.Time) sdktypes.SLOStatus {
+	good, total := sum(buckets, end, spec.Window)
+	status := sdktypes.SLOStatus{
+		Name:                 spec.Name,
+		Indicator:            spec.Indicator,
+		Objective:            spec.Objective,
+		Window:               spec.Window,
+		GoodInvocations:      good,
+		TotalInvocations:     total,
+		Met:                  true,
+		ErrorBudget:          (1 - spec.Objective) * total,
+		ErrorBudgetRemaining: 1,
+		Alerts:               []sdktypes.BurnRateAlertStatus{},
+	}
+	if total > 0 {
+		attainment := good / total
+		status.Attainment = &attainment
+		status.Met = attainment >= spec.Objective
+		status.ErrorBudgetRemaining = 1 - (total-good)/status.ErrorBudget
+	}
+	for _, alert := range spec.Alerts {
+		alertStatus := sdktypes.BurnRateAlertStatus{
+			BurnRateAlert: alert,
+			LongBurnRate:  burnRate(buckets, end, alert.LongWindow, spec.Objective),
+			ShortBurnRate: burnRate(buckets, end, alert.ShortWindow, spec.Objective),
+		}
+		alertStatus.Firing = alertStatus.LongBurnRate != nil && alertStatus.ShortBurnRate != nil &&
+			*alertStatus.LongBurnRate > alert.Threshold && *alertStatus.ShortBurnRate > alert.Threshold
+		status.Alerts = append(status.Alerts, alertStatus)
+	}
+	return status
+}
+
+// burnRate returns how many times faster than allowed the error budget burned in the window,
+// or nil without invocations in it.
+func burnRate(buckets []Bucket, end time.Time, window time.Duration, objective float64) *float64 {
+	good, total := sum(buckets, end, window)
+	if total == 0 {
+		return nil
+	}
+	rate := (total - good) / total / (1 - objective)
+	return &rate
+}
+
+// sum adds up the buckets that start within the window ending at end.
+func sum(buckets []Bucket, end time.Time, window time.Duration) (float64, float64) {
+	start := end.Add(-window).Truncate(BucketSize)
+	var good, total float64
+	for _, bucket := range buckets {
+		if bucket.Start.Before(start) || !bucket.Start.Before(end) {
+			continue
+		}
+		good += bucket.Good
+		total += bucket.Total
+	}
+	return good, total
+}
@@ -21,6 +21,7 @@ import (
 	"math"
 	"slices"
 	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -33,6 +34,16 @@ import (
 	sdktypes "github.com/dominikhei/serverless-statistics/types"
 )
 
+const (
+	// MADScale scales the MAD to estimate the standard deviation of normally distributed values.
+	MADScale = 1.4826
+	// relativeNoiseFloor is the smallest standard deviation relative to the expected value, so flat series
+	// do not turn every small deviation into a significant one.
+	relativeNoiseFloor = 0.01
+	// MinSigma keeps scores and costs finite if a series is flat at zero.
+	MinSigma = 1e-9
+)
+
 // summaryStatistics holds common descriptive statistics for a sample set of float64 values.
 // P95, P99, and ConfInt95 are pointers because they may be nil if sample size is insufficient.
 type summaryStatistics struct {
@@ -77,8 +88,8 @@ func ToLoadOptions(opts sdktypes.ConfigOptions) ([]func(*config.LoadOptions) err
 	return loadOptions, nil
 }
 
-// mean calculates the arithmetic mean of a slice of float64 values
-func mean(vals []float64) float64 {
+// Mean calculates the arithmetic mean of a slice of float64 values, 0 if there are none.
+func Mean(vals []float64) float64 {
 	if len(vals) == 0 {
 		return 0
 	}
@@ -95,7 +106,7 @@ func stdDev(vals []float64) float64 {
 		return 0
 	}
 
-	m := mean(vals)
+	m := Mean(vals)
 	sumSquares := 0.0
 	for _, v := range vals {
 		diff := v - m
@@ -107,8 +118,8 @@ func stdDev(vals []float64) float64 {
 	return math.Sqrt(sumSquares / float64(len(vals)))
 }
 
-// quantile calculates the quantile (0.0 to 1.0) from a sorted slice
-func quantile(p float64, sorted []float64) float64 {
+// Quantile calculates the quantile (0.0 to 1.0) from a sorted slice
+func Quantile(p float64, sorted []float64) float64 {
 	if len(sorted) == 0 {
 		return 0
 	}
@@ -132,8 +143,8 @@ func CalcSummaryStats(vals []float64) (summaryStatistics, error) {
 	copy(sorted, vals)
 	sort.Float64s(sorted)
 
-	meanVal := mean(vals)
-	medianVal := quantile(0.5, sorted)
+	meanVal := Mean(vals)
+	medianVal := Quantile(0.5, sorted)
 	stddevVal := stdDev(vals)
 	min := slices.Min(vals)
 	max := slices.Max(vals)
@@ -141,12 +152,12 @@ func CalcSummaryStats(vals []float64) (summaryStatistics, error) {
 	var p95, p99, confInt95 *float64
 
 	if len(vals) >= 20 {
-		val := quantile(0.95, sorted)
+		val := Quantile(0.95, sorted)
 		p95 = &val
 	}
 
 	if len(vals) >= 100 {
-		val := quantile(0.99, sorted)
+		val := Quantile(0.99, sorted)
 		p99 = &val
 	}
 
@@ -166,6 +177,79 @@ func CalcSummaryStats(vals []float64) (summaryStatistics, error) {
 	}, nil
 }
 
+// LinearRegression fits a line y = slope*x + intercept through the points using least squares.
+// rSquared is the coefficient of determination of the fit, it is 1 if all y values are equal.
+func LinearRegression(xs, ys []float64) (slope, intercept, rSquared float64, err error) {
+	if len(xs) != len(ys) {
+		return 0, 0, 0, errors.New("xs and ys must have the same length")
+	}
+	if len(xs) < 2 {
+		return 0, 0, 0, errors.New("at least two points are required")
+	}
+	meanX, meanY := Mean(xs), Mean(ys)
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return 0, 0, 0, errors.New("all x values are equal")
+	}
+	slope = sxy / sxx
+	intercept = meanY - slope*meanX
+	if syy == 0 {
+		return slope, intercept, 1, nil
+	}
+	rSquared = (sxy * sxy) / (sxx * syy)
+	return slope, intercept, rSquared, nil
+}
+
+// Median returns the median of the values, the mean of the two middle values for an even number of values.
+// The values do not need to be sorted and are not modified.
+func Median(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), vals...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// MedianAbsoluteDeviation returns the median of the absolute deviations of the values from their median.
+// Multiplied by 1.4826 it estimates the standard deviation of normally distributed values robustly to outliers.
+func MedianAbsoluteDeviation(vals []float64) float64 {
+	median := Median(vals)
+	deviations := make([]float64, len(vals))
+	for i, v := range vals {
+		deviations[i] = math.Abs(v - median)
+	}
+	return Median(deviations)
+}
+
+// NoiseFloor raises an estimated standard deviation to 1% of the expected value, and at least to MinSigma.
+func NoiseFloor(sigma, expected float64) float64 {
+	return math.Max(sigma, math.Max(relativeNoiseFloor*math.Abs(expected), MinSigma))
+}
+
+// BucketPeriod returns the smallest gap between two points of a series sorted by time,
+// the width of the buckets of the series. It is 0 for less than two points.
+func BucketPeriod(series []sdktypes.MetricPoint) time.Duration {
+	var period time.Duration
+	for i := 1; i < len(series); i++ {
+		gap := series[i].Timestamp.Sub(series[i-1].Timestamp)
+		if gap > 0 && (period == 0 || gap < period) {
+			period = gap
+		}
+	}
+	return period
+}
+
 // FunctionExists checks if an AWS Lambda function with the given name exists in the AWS account.
 // Returns true if the function exists, false if not found, or an error on other failures.
 func FunctionExists(ctx context.Context, client sdkinterfaces.LambdaClient, functionName string) (bool, error) {
@@ -210,3 +294,30 @@ func SumMetricValues(results []cwTypes.MetricDataResult) (float64, error) {
 	}
 	return sum, nil
 }
+
+// BucketSize returns the width of buckets that split the interval from start to end into
+// roughly n buckets. The width is rounded up to full minutes, as this is the highest
+// resolution of Lambda metrics in CloudWatch.
+func BucketSize(start, end time.Time, n int) time.Duration {
+	if n <= 0 || !end.After(start) {
+		return time.Minute
+	}
+	size := end.Sub(start) / time.Duration(n)
+	if size < time.Minute {
+		return time.Minute
+	}
+	return ((size + time.Minute - 1) / time.Minute) * time.Minute
+}
+
+// lastModifiedLayout is the format of the LastModified field of the Lambda API.
+const lastModifiedLayout = "2006-01-02T15:04:05.000-0700"
+
+// ParseLastModified parses the LastModified timestamp of a function configuration,
+// e.g. "2025-01-01T12:00:00.000+0000".
+func ParseLastModified(value string) (time.Time, error) {
+	ts, err := time.Parse(lastModifiedLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse last modified %q: %w", value, err)
+	}
+	return ts.UTC(), nil
+}
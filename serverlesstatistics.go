@@ -55,12 +55,19 @@ import (
 	"log"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/dominikhei/serverless-statistics/internal/anomaly"
+	"github.com/dominikhei/serverless-statistics/internal/audit"
 	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/changepoint"
 	"github.com/dominikhei/serverless-statistics/internal/clientmanager"
 	cloudwatchfetcher "github.com/dominikhei/serverless-statistics/internal/cloudwatch"
+	"github.com/dominikhei/serverless-statistics/internal/forecast"
 	logsinsightsfetcher "github.com/dominikhei/serverless-statistics/internal/logsinsights"
 	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	"github.com/dominikhei/serverless-statistics/internal/slo"
 	"github.com/dominikhei/serverless-statistics/internal/utils"
 	sdktypes "github.com/dominikhei/serverless-statistics/types"
 )
@@ -69,7 +76,10 @@ type ServerlessStats struct {
 	cloudwatchFetcher *cloudwatchfetcher.Fetcher
 	logsFetcher       *logsinsightsfetcher.Fetcher
 	lambdaClient      *lambda.Client
+	cloudTrailClient  *cloudtrail.Client
+	codeDeployClient  *codedeploy.Client
 	invocationsCache  *cache.Cache
+	periodCache       *cache.ResultCache[map[sdktypes.ComparisonMetric]sdktypes.PeriodValue]
 }
 
 // ServerlessStats holds clients and caches to fetch AWS Lambda statistics.
@@ -98,7 +108,10 @@ func New(ctx context.Context, opts sdktypes.ConfigOptions) *ServerlessStats {
 		cloudwatchFetcher: cloudwatchfetcher.New(clients),
 		logsFetcher:       logsinsightsfetcher.New(clients),
 		lambdaClient:      clients.LambdaClient,
+		cloudTrailClient:  clients.CloudTrailClient,
+		codeDeployClient:  clients.CodeDeployClient,
 		invocationsCache:  cache.NewCache(),
+		periodCache:       cache.NewResultCache[map[sdktypes.ComparisonMetric]sdktypes.PeriodValue](),
 	}
 }
 
@@ -662,9 +675,18 @@ func (a *ServerlessStats) GetColdStartDurationStatistics(
 //   - version: (Optional) Lambda version. Defaults to "$LATEST" if empty.
 //
 // Returns:
-//   - *sdktypes.BaseStatisticsReturn: Struct containing the function's configuration details.
+//   - *sdktypes.BaseStatisticsReturn: Struct containing a snapshot of the function's configuration, e.g. memory,
+//     timeout, runtime, architectures, layers, VPC, concurrency, SnapStart, logging, destinations, tracing,
+//     tags and the function URL.
 //   - error: If the function or version does not exist or retrieval fails.
 //
+// Notes:
+//   - Provisioned concurrency is only read for published versions, the function URL only for "$LATEST".
+//   - Tags, the event invoke config and the function URL are left empty with a warning if they cannot be read.
+//   - The snapshot can be serialized to JSON and compared with snapshots of other versions or points in time.
+//   - Environment variables with secret-like names (e.g. *_SECRET, *_TOKEN, PASSWORD) or random-looking values
+//     are replaced by "[REDACTED]". Use GetFunctionConfigurationWithPolicy to configure the redaction.
+//
 // Example:
 //
 //	configs, err := serverlessstatistics.GetFunctionConfiguration(ctx, "my-function", "v1")
@@ -676,6 +698,44 @@ func (a *ServerlessStats) GetFunctionConfiguration(
 	ctx context.Context,
 	functionName string,
 	version string,
+) (*sdktypes.BaseStatisticsReturn, error) {
+	return a.GetFunctionConfigurationWithPolicy(ctx, functionName, version, sdktypes.RedactionPolicy{})
+}
+
+// GetFunctionConfigurationWithPolicy returns the configuration details for a given AWS Lambda function
+// and version as GetFunctionConfiguration, with the secrets in the environment variables reported
+// according to the redaction policy.
+//
+// Input Parameters:
+//   - ctx: Context for cancellation and timeout.
+//   - functionName: Name of the Lambda function to retrieve configuration for.
+//   - version: (Optional) Lambda version. Defaults to "$LATEST" if empty.
+//   - redactionPolicy: Policy for redacting secrets in the environment variables. The zero value
+//     redacts variables with secret-like names (e.g. *_SECRET, *_TOKEN, PASSWORD) or random-looking values.
+//
+// Returns:
+//   - *sdktypes.BaseStatisticsReturn: Struct containing a snapshot of the function's configuration.
+//   - error: If the function or version does not exist or retrieval fails.
+//
+// Notes:
+//   - Secrets are replaced by "[REDACTED]", or by their HMAC-SHA256 hash if HashSecrets is set, so that changes
+//     stay visible. The hash is keyed with HashKey, or with a random key of the process if it is empty, so hashes
+//     of snapshots taken in different processes are only comparable with the same HashKey.
+//   - Secrets are only revealed if RevealSecrets is set explicitly. EnvironmentVariableVisibility reports
+//     which of these applies to every variable.
+//
+// Example:
+//
+//	configs, err := serverlessstatistics.GetFunctionConfigurationWithPolicy(ctx, "my-function", "v1",
+//		sdktypes.RedactionPolicy{HashSecrets: true, HashKey: key})
+//	if err != nil {
+//		log.Fatalf("failed to get function configuration: %v", err)
+//	}
+func (a *ServerlessStats) GetFunctionConfigurationWithPolicy(
+	ctx context.Context,
+	functionName string,
+	version string,
+	redactionPolicy sdktypes.RedactionPolicy,
 ) (*sdktypes.BaseStatisticsReturn, error) {
 	if version == "" {
 		version = "$LATEST"
@@ -701,5 +761,1731 @@ func (a *ServerlessStats) GetFunctionConfiguration(
 		return nil, fmt.Errorf("version %q does not exist", version)
 	}
 
-	return metrics.GetFunctionConfiguration(ctx, a.lambdaClient, query)
+	return metrics.GetFunctionConfiguration(ctx, a.lambdaClient, a.lambdaClient, a.lambdaClient, a.lambdaClient, query, redactionPolicy)
+}
+
+// CompareErrorCategories compares the error categories of a baseline and a target for a given
+// AWS Lambda function. The comparison can either be done between two versions (e.g. the previously
+// deployed and the newly deployed version) or between two time windows of the same version.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - baselineVersion: (Optional) Lambda version of the baseline. If empty, defaults to "$LATEST".
+//   - baselineStart: Start of the baseline time window.
+//   - baselineEnd: End of the baseline time window.
+//   - targetVersion: (Optional) Lambda version of the target. If empty, defaults to "$LATEST".
+//   - targetStart: Start of the target time window.
+//   - targetEnd: End of the target time window.
+//
+// Returns:
+//   - *sdktypes.ErrorCategoryDiffReturn: Struct containing new, resolved, increased and decreased error
+//     categories. Changes are normalized by the invocations of the baseline and the target.
+//   - error: Returned if the function or a version does not exist, if either side has no invocations,
+//     or if log/metric queries fail.
+//
+// Notes:
+//   - Error categories are extracted the same way as in GetErrorCategoryStatistics.
+//   - HasNewErrors can be used as a post-deploy gate.
+//
+// Example:
+//
+//	diff, err := serverlessstatistics.CompareErrorCategories(ctx, "my-function",
+//		"1", time.Now().Add(-2*time.Hour), time.Now(),
+//		"2", time.Now().Add(-2*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to compare error categories: %v", err)
+//	}
+//	for _, change := range diff.New {
+//		fmt.Printf("New error category: %s (%.4f per invocation)\n", change.ErrorCategory, change.TargetRate)
+//	}
+func (a *ServerlessStats) CompareErrorCategories(
+	ctx context.Context,
+	functionName string,
+	baselineVersion string,
+	baselineStart, baselineEnd time.Time,
+	targetVersion string,
+	targetStart, targetEnd time.Time,
+) (*sdktypes.ErrorCategoryDiffReturn, error) {
+	if baselineVersion == "" {
+		baselineVersion = "$LATEST"
+	}
+	if targetVersion == "" {
+		targetVersion = "$LATEST"
+	}
+	baseline := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    baselineVersion,
+		StartTime:    baselineStart,
+		EndTime:      baselineEnd,
+	}
+	target := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    targetVersion,
+		StartTime:    targetStart,
+		EndTime:      targetEnd,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, baselineVersion); err != nil {
+		return nil, err
+	}
+	if targetVersion != baselineVersion {
+		if err := a.checkFunctionAndVersion(ctx, functionName, targetVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	return metrics.GetErrorCategoryDiff(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, baseline, target)
+}
+
+// checkFunctionAndVersion returns an error if the function or the version
+// of the function does not exist.
+func (a *ServerlessStats) checkFunctionAndVersion(ctx context.Context, functionName, version string) error {
+	exists, err := utils.FunctionExists(ctx, a.lambdaClient, functionName)
+	if err != nil {
+		return fmt.Errorf("checking if function exists: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("lambda function %q does not exist", functionName)
+	}
+
+	exists, err = utils.QualifierExists(ctx, a.lambdaClient, functionName, version)
+	if err != nil {
+		return fmt.Errorf("checking if version exists: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("version %q does not exist", version)
+	}
+	return nil
+}
+
+// GetErrorClassification returns a breakdown of the failed invocations of a given AWS Lambda
+// function and version within the specified time range. Each error class is reported
+// with its own count and rate.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.ErrorClassificationReturn: Struct containing function, handled, unhandled, runtime exit,
+//     out of memory, init phase, timeout and logged-only error counts and rates.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - Invocations are classified from the REPORT and platform records, e.g. `Status: timeout`,
+//     `Runtime.ExitError` and `Runtime.OutOfMemory`, and `INIT_REPORT` lines or `platform.initReport`
+//     records with status error or timeout.
+//   - An invocation that shows several signals is assigned to the most severe class only.
+//   - LoggedOnlyErrors only counts invocations that succeeded. Their status is only logged in the JSON log format,
+//     in the text format handled errors are assumed to be among the invocations that logged errors.
+//   - FunctionErrors is the CloudWatch Errors metric and overlaps with the other classes.
+//   - At most 10000 invocations with error signals are classified, a warning is returned if the limit is hit.
+//
+// Example:
+//
+//	classes, err := serverlessstatistics.GetErrorClassification(ctx, "my-function", "v1", time.Now().Add(-1*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get error classification: %v", err)
+//	}
+//	fmt.Printf("Out of memory rate: %.2f%%\n", classes.OutOfMemoryErrors.Rate * 100)
+func (a *ServerlessStats) GetErrorClassification(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.ErrorClassificationReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetErrorClassification(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, query)
+}
+
+// GetOutOfMemoryRate returns the share of invocations that ran out of memory for a given
+// AWS Lambda function and version within the specified time range, together with the
+// request IDs of the affected invocations.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.OutOfMemoryRateReturn: Struct containing the out of memory count and rate, the affected
+//     request IDs and the memory usage over time.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - An invocation ran out of memory if its max memory used equals the memory size, or if it ended
+//     with `Runtime.OutOfMemory` or a signal-killed runtime exit.
+//   - The memory usage over time shows how close invocations get to the limit, which can reveal a leak
+//     before the function crashes.
+//   - At most 10000 REPORT lines are analyzed, the oldest ones. A warning is returned if the limit is hit.
+//
+// Example:
+//
+//	oomReturn, err := serverlessstatistics.GetOutOfMemoryRate(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get out of memory rate: %v", err)
+//	}
+//	fmt.Printf("Out of memory rate: %.2f%%\n", oomReturn.OutOfMemoryRate * 100)
+func (a *ServerlessStats) GetOutOfMemoryRate(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.OutOfMemoryRateReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetOutOfMemoryRate(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, query)
+}
+
+// GetMemoryGrowth analyzes how the max memory used grows over the invocations of every execution
+// environment of a given AWS Lambda function and version within the specified time range.
+// Each log stream corresponds to one execution environment.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.MemoryGrowthReturn: Struct containing the memory growth slope per invocation of every
+//     environment, the environments with steadily increasing memory and the projected number of
+//     invocations until they run out of memory at the current memory size.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - Only environments that served at least 5 invocations in the time window are analyzed.
+//   - Steadily increasing memory points to leaks in global state. Memory that plateaus after
+//     a single step is not flagged, as max memory used is a high-water mark.
+//   - At most 10000 REPORT lines are analyzed, a warning is returned if the limit is hit.
+//
+// Example:
+//
+//	growthReturn, err := serverlessstatistics.GetMemoryGrowth(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get memory growth: %v", err)
+//	}
+//	for _, env := range growthReturn.AffectedEnvironments {
+//		fmt.Printf("%s grows %.2f MB per invocation\n", env.LogStream, env.SlopeMBPerInvocation)
+//	}
+func (a *ServerlessStats) GetMemoryGrowth(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.MemoryGrowthReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetMemoryGrowth(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.invocationsCache, query)
+}
+
+// GetEnvironmentLifecycleStatistics analyzes the lifecycle of the execution environments of a given
+// AWS Lambda function and version within the specified time range.
+// Each log stream corresponds to one execution environment.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.EnvironmentLifecycleReturn: Struct containing the distribution of invocations served and lifetime
+//     per environment, the idle gaps between invocations, the share of time spent in init,
+//     the number of concurrently alive environments over time and the details of every environment.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - Environments that were created before startTime or recycled after endTime are cut at the window borders.
+//   - An environment counts as alive from the start of its init phase until the end of its last invocation.
+//   - At most 10000 REPORT lines are analyzed, a warning is returned if the limit is hit.
+//
+// Example:
+//
+//	lifecycleReturn, err := serverlessstatistics.GetEnvironmentLifecycleStatistics(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get environment lifecycle statistics: %v", err)
+//	}
+//	fmt.Printf("Environments: %d, peak concurrent: %d\n", lifecycleReturn.EnvironmentCount, lifecycleReturn.PeakConcurrentEnvironments)
+func (a *ServerlessStats) GetEnvironmentLifecycleStatistics(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.EnvironmentLifecycleReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetEnvironmentLifecycleStatistics(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, query)
+}
+
+// GetColdStartCauses classifies every cold start of a given AWS Lambda function and version
+// within the specified time range by its root cause.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - idleThreshold: (Optional) Time since the last invocation of the replaced environment after which a cold start counts as idle recycle.
+//     If zero, defaults to 10 minutes.
+//
+// Returns:
+//   - *sdktypes.ColdStartCausesReturn: Struct containing the number and share of cold starts caused by deployments,
+//     scale-outs, idle recycles and replacements of failed environments, as well as the cause of every cold start.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - Deployments are only detected if the version was last modified within the time window.
+//   - Cold starts without an environment that retired earlier in the time window are reported as unknown.
+//   - At most 10000 REPORT lines are analyzed, a warning is returned if the limit is hit.
+//   - Scale-outs can be reduced with provisioned concurrency, idle recycles with keep-warm invocations
+//     and error replacements by fixing the failing invocations.
+//
+// Example:
+//
+//	causesReturn, err := serverlessstatistics.GetColdStartCauses(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now(), 0)
+//	if err != nil {
+//		log.Fatalf("failed to get cold start causes: %v", err)
+//	}
+//	fmt.Printf("Scale-out: %.2f%%, Idle recycle: %.2f%%\n", causesReturn.ScaleOut.Rate*100, causesReturn.IdleRecycle.Rate*100)
+func (a *ServerlessStats) GetColdStartCauses(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	idleThreshold time.Duration,
+) (*sdktypes.ColdStartCausesReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetColdStartCauses(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.invocationsCache, query, idleThreshold)
+}
+
+// GetColdStartsByInitType splits the cold starts of a given AWS Lambda function and version within the
+// specified time range by the init type of the execution environments: on-demand, provisioned concurrency and SnapStart.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.ColdStartsByInitTypeReturn: Struct containing per init type the number of environments,
+//     the invocations they served, the cold start rate and the init or restore duration statistics.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - The initializationType is only logged in the JSON log format. For the text format it is derived
+//     from RESTORE records, Init Duration and Restore Duration in REPORT lines and INIT_START records.
+//   - Environments initialized before startTime can not be assigned to a type and are only counted.
+//
+// Example:
+//
+//	initTypeReturn, err := serverlessstatistics.GetColdStartsByInitType(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get cold starts by init type: %v", err)
+//	}
+//	fmt.Printf("SnapStart cold start rate: %.2f%%\n", initTypeReturn.SnapStart.ColdStartRate*100)
+func (a *ServerlessStats) GetColdStartsByInitType(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.ColdStartsByInitTypeReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetColdStartsByInitType(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, query)
+}
+
+// GetEndToEndLatencyStatistics calculates the latency callers of a given AWS Lambda function and version
+// experience within the specified time range. On cold starts the init or restore duration is added to the duration.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.EndToEndLatencyReturn: Struct containing latency statistics of all, warm and cold invocations,
+//     as well as how much of the p99 latency is caused by cold starts.
+//   - error: Returned if the function or version does not exist, or if metric/log queries fail.
+//
+// Notes:
+//   - The p99 breakdown requires at least 100 invocations, like all p99 statistics.
+//   - At most 10000 REPORT lines are analyzed, a warning is returned if the limit is hit.
+//
+// Example:
+//
+//	latencyReturn, err := serverlessstatistics.GetEndToEndLatencyStatistics(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get end-to-end latency statistics: %v", err)
+//	}
+//	if latencyReturn.ColdStartShareOfP99Tail != nil {
+//		fmt.Printf("Cold starts in the p99 tail: %.2f%%\n", *latencyReturn.ColdStartShareOfP99Tail*100)
+//	}
+func (a *ServerlessStats) GetEndToEndLatencyStatistics(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.EndToEndLatencyReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetEndToEndLatencyStatistics(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, query)
+}
+
+// GetProvisionedConcurrencyStatistics returns the provisioned concurrency config of a given AWS Lambda function
+// and version together with its utilization and spillover within the specified time range.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: Lambda version with provisioned concurrency. If empty, defaults to "$LATEST",
+//     which can not have provisioned concurrency.
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.ProvisionedConcurrencyReturn: Struct containing the requested, allocated and available
+//     provisioned concurrency, its utilization over time and the invocations served by it or spilled over.
+//   - error: Returned if the function or version does not exist, or if metric queries or API calls fail.
+//
+// Notes:
+//   - If the version has no provisioned concurrency, Configured is false and no metrics are fetched.
+//
+// Example:
+//
+//	pcReturn, err := serverlessstatistics.GetProvisionedConcurrencyStatistics(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get provisioned concurrency statistics: %v", err)
+//	}
+//	fmt.Printf("Max utilization: %.2f%%, spillover: %.2f%%\n", pcReturn.MaxUtilization*100, pcReturn.SpilloverRate*100)
+func (a *ServerlessStats) GetProvisionedConcurrencyStatistics(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.ProvisionedConcurrencyReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetProvisionedConcurrencyStatistics(ctx, a.cloudwatchFetcher, a.lambdaClient, a.invocationsCache, query)
+}
+
+// GetProvisionedConcurrencyRecommendation derives the provisioned concurrency a given AWS Lambda function and version
+// needs to eliminate a target share of its cold starts, based on the concurrency observed within the specified time range.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - targetColdStartReduction: Share of cold starts to eliminate, between 0 (exclusive) and 1, e.g. 0.95.
+//
+// Returns:
+//   - *sdktypes.ProvisionedConcurrencyRecommendationReturn: Struct containing the recommended provisioned concurrency,
+//     the cold starts it eliminates and the estimated cost with and without it for the time window.
+//   - error: Returned if the function or version does not exist, the target is out of range, or if metric/log queries fail.
+//
+// Notes:
+//   - Costs are estimates based on us-east-1 list prices and ignore request charges, which are the same in both cases.
+//   - The recommendation assumes the observed traffic pattern repeats.
+//   - At most 10000 REPORT lines are read; their durations are scaled to all invocations and a warning is returned.
+//
+// Example:
+//
+//	recommendation, err := serverlessstatistics.GetProvisionedConcurrencyRecommendation(ctx, "my-function", "v1", time.Now().Add(-7*24*time.Hour), time.Now(), 0.95)
+//	if err != nil {
+//		log.Fatalf("failed to get provisioned concurrency recommendation: %v", err)
+//	}
+//	fmt.Printf("Provision %d environments, savings: $%.2f\n", recommendation.RecommendedConcurrency, recommendation.Savings)
+func (a *ServerlessStats) GetProvisionedConcurrencyRecommendation(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	targetColdStartReduction float64,
+) (*sdktypes.ProvisionedConcurrencyRecommendationReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetProvisionedConcurrencyRecommendation(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.invocationsCache, query, targetColdStartReduction)
+}
+
+// GetConcurrencyProfile analyzes the concurrency of a given AWS Lambda function and version within the
+// specified time range against its reserved concurrency or the unreserved concurrency of the account,
+// and correlates throttles with saturation of that limit.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.ConcurrencyProfileReturn: Struct containing the peak concurrency over time, the reserved, account
+//     and unreserved limits, the headroom to the applicable limit and every period with throttles,
+//     flagged if the limit was saturated.
+//   - error: Returned if the function or version does not exist, or if metric/log queries or API calls fail.
+//
+// Notes:
+//   - If ConcurrentExecutions has no data for periods with invocations, the concurrency is estimated
+//     from the REPORT lines, which requires the time window to be within log retention.
+//   - Throttles that happen without saturation point to burst limits or to other functions exhausting the account pool.
+//
+// Example:
+//
+//	profile, err := serverlessstatistics.GetConcurrencyProfile(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get concurrency profile: %v", err)
+//	}
+//	fmt.Printf("Peak: %.0f of %d, throttles while saturated: %.2f%%\n", profile.PeakConcurrency, profile.ConcurrencyLimit, profile.SaturatedThrottleShare*100)
+func (a *ServerlessStats) GetConcurrencyProfile(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.ConcurrencyProfileReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetConcurrencyProfile(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.invocationsCache, query)
+}
+
+// GetAsyncInvocationStatistics calculates the health of the asynchronous invocations of a given AWS Lambda
+// function and version within the specified time range, e.g. of invocations by S3, SNS or EventBridge.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.AsyncInvocationStatisticsReturn: Struct containing the received and dropped events, failed deliveries to
+//     the dead-letter queue and destinations, event age percentiles, the retry and max-age settings and warnings.
+//   - error: Returned if the function or version does not exist, if metric queries or API calls fail,
+//     or a NoInvocationsError if the function received no asynchronous events.
+//
+// Notes:
+//   - A warning is added if the p99 or max event age reaches 80% of the maximum event age,
+//     as events older than that are dropped.
+//
+// Example:
+//
+//	asyncReturn, err := serverlessstatistics.GetAsyncInvocationStatistics(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get async invocation statistics: %v", err)
+//	}
+//	fmt.Printf("Drop rate: %.2f%%\n", asyncReturn.DropRate*100)
+//	for _, warning := range asyncReturn.Warnings {
+//		fmt.Println(warning)
+//	}
+func (a *ServerlessStats) GetAsyncInvocationStatistics(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.AsyncInvocationStatisticsReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetAsyncInvocationStatistics(ctx, a.cloudwatchFetcher, a.lambdaClient, a.lambdaClient, query)
+}
+
+// GetEventSourceMappingStatistics lists the event source mappings of a given AWS Lambda function and version,
+// e.g. of SQS queues, Kinesis and DynamoDB streams or Kafka topics, and calculates their lag and batch
+// statistics within the specified time range.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.EventSourceMappingStatisticsReturn: Struct containing the settings, lag over time and warnings of
+//     every mapping, the batch durations and the number of invocations and errors of the function.
+//   - error: Returned if the function or version does not exist, or if metric queries or API calls fail.
+//
+// Notes:
+//   - The lag is IteratorAge for streams, OffsetLag for Kafka and ApproximateAgeOfOldestMessage of the queue for SQS.
+//   - IteratorAge and OffsetLag are emitted per function, with several mappings of the same source type
+//     the lag is their maximum and a warning is added.
+//   - Records per invocation are counted in the logged events, so the function has to log the event it receives.
+//     They are only calculated if no other mapping has the same source type and log lines with records exist.
+//   - Failures retrying whole batches are only warned about for SQS, Kinesis, DynamoDB and Kafka mappings,
+//     the source types that support ReportBatchItemFailures.
+//
+// Example:
+//
+//	esmReturn, err := serverlessstatistics.GetEventSourceMappingStatistics(ctx, "my-function", "v1", time.Now().Add(-24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get event source mapping statistics: %v", err)
+//	}
+//	for _, mapping := range esmReturn.Mappings {
+//		fmt.Printf("%s falling behind: %t\n", mapping.EventSourceArn, mapping.FallingBehind)
+//	}
+func (a *ServerlessStats) GetEventSourceMappingStatistics(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.EventSourceMappingStatisticsReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetEventSourceMappingStatistics(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.lambdaClient, a.invocationsCache, query)
+}
+
+// GetTimeoutRecommendation compares the duration tail of a given AWS Lambda function and version within the
+// specified time range with its configured timeout and recommends a timeout.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.TimeoutRecommendationReturn: Struct containing the p99, p99.9 and max duration, the assessment of the
+//     configured timeout, the recommended timeout with a justification and the estimated timeout rate under it.
+//   - error: Returned if the function or version does not exist, if log queries or API calls fail,
+//     or a NoInvocationsError if the function was not invoked.
+//
+// Notes:
+//   - The recommended timeout is the p99.9 duration plus 50% headroom, rounded up to whole seconds.
+//   - Invocations that timed out are assumed to hang, so they count as timeouts under the recommended timeout too.
+//
+// Example:
+//
+//	timeoutReturn, err := serverlessstatistics.GetTimeoutRecommendation(ctx, "my-function", "v1", time.Now().Add(-7*24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get timeout recommendation: %v", err)
+//	}
+//	fmt.Printf("%s: %d s -> %d s (%s)\n", timeoutReturn.Assessment, timeoutReturn.TimeoutSeconds,
+//		timeoutReturn.RecommendedTimeoutSeconds, timeoutReturn.Justification)
+func (a *ServerlessStats) GetTimeoutRecommendation(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+) (*sdktypes.TimeoutRecommendationReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetTimeoutRecommendation(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.invocationsCache, query)
+}
+
+// GetArchitectureMigrationEstimate projects the cost of the invocations of a given AWS Lambda function and version
+// within the specified time range on the other architecture, i.e. arm64 (Graviton) for x86_64 functions and vice versa.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - performanceRatio: Duration on the target architecture relative to the current one, e.g. 0.9 if the function
+//     runs 10% faster on it. If 0, the same duration is assumed.
+//
+// Returns:
+//   - *sdktypes.ArchitectureMigrationEstimateReturn: Struct containing the current and target architecture,
+//     the performance ratio used, the current and projected cost and the savings.
+//   - error: Returned if the function or version does not exist, if the performance ratio is negative,
+//     if log queries or API calls fail, or a NoInvocationsError if the function was not invoked.
+//
+// Notes:
+//   - If another version runs on the target architecture and both versions have at least 30 invocations
+//     in the time range, the ratio of their median durations replaces the given performance ratio.
+//   - The billed duration is summed from at most 10000 REPORT lines and scaled to all invocations.
+//   - Costs are based on us-east-1 list prices without free tier.
+//
+// Example:
+//
+//	estimate, err := serverlessstatistics.GetArchitectureMigrationEstimate(ctx, "my-function", "", time.Now().Add(-30*24*time.Hour), time.Now(), 0.9)
+//	if err != nil {
+//		log.Fatalf("failed to get architecture migration estimate: %v", err)
+//	}
+//	fmt.Printf("Savings on %s: $%.2f (%.0f%%)\n", estimate.TargetArchitecture, estimate.Savings, estimate.SavingsRate*100)
+func (a *ServerlessStats) GetArchitectureMigrationEstimate(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	performanceRatio float64,
+) (*sdktypes.ArchitectureMigrationEstimateReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetArchitectureMigrationEstimate(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.lambdaClient, a.invocationsCache, query, performanceRatio)
+}
+
+// GetArchitectureMigrationRanking ranks x86_64 functions by the savings of a migration to arm64 (Graviton)
+// within the specified time range.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionNames: (Optional) The functions to rank. If empty, all functions of the account in the region are ranked.
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - performanceRatio: Duration on arm64 relative to x86_64. If 0, the same duration is assumed.
+//
+// Returns:
+//   - *sdktypes.ArchitectureMigrationRankingReturn: Struct containing the estimate of the $LATEST version of every
+//     x86_64 function with invocations, ordered by descending savings.
+//   - error: Returned if a given function does not exist, or if listing the functions fails.
+//
+// Notes:
+//   - Every function is estimated as in GetArchitectureMigrationEstimate, which queries the logs of every function.
+//   - Functions whose estimate fails are left out and reported in the warnings.
+//
+// Example:
+//
+//	ranking, err := serverlessstatistics.GetArchitectureMigrationRanking(ctx, nil, time.Now().Add(-30*24*time.Hour), time.Now(), 0)
+//	if err != nil {
+//		log.Fatalf("failed to rank architecture migrations: %v", err)
+//	}
+//	for _, candidate := range ranking.Candidates {
+//		fmt.Printf("%s: $%.2f\n", candidate.FunctionName, candidate.Savings)
+//	}
+func (a *ServerlessStats) GetArchitectureMigrationRanking(
+	ctx context.Context,
+	functionNames []string,
+	startTime, endTime time.Time,
+	performanceRatio float64,
+) (*sdktypes.ArchitectureMigrationRankingReturn, error) {
+	for _, functionName := range functionNames {
+		if err := a.checkFunctionAndVersion(ctx, functionName, "$LATEST"); err != nil {
+			return nil, err
+		}
+	}
+
+	return metrics.GetArchitectureMigrationRanking(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.lambdaClient, a.lambdaClient,
+		a.invocationsCache, functionNames, startTime, endTime, performanceRatio)
+}
+
+// GetConfigurationDrift compares the configuration of two versions of a given AWS Lambda function
+// and correlates the changes with the metrics before and after the target version was last modified.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - baselineVersion: (Optional) Version to compare against, e.g. the previously deployed version. If empty, defaults to "$LATEST".
+//   - targetVersion: (Optional) Version to compare, e.g. the newly deployed version. If empty, defaults to "$LATEST".
+//   - window: Length of the windows before and after the change to compare the metrics in. If 0, metrics are not compared.
+//
+// Returns:
+//   - *sdktypes.ConfigurationDriftReturn: Struct containing the changes of memory, timeout, runtime, environment
+//     variable names, layers, VPC and concurrency settings, and the shift of invocations, error rate, throttle rate
+//     and mean duration from the baseline before the change to the target after it.
+//   - error: Returned if the function or a version does not exist, or if API calls or metric queries fail.
+//
+// Notes:
+//   - Values of environment variables are compared by their hashes and never reported.
+//   - The change time is the last modification of the target version. A configuration only records its last
+//     modification, so all changes are correlated with the same metric shift.
+//
+// Example:
+//
+//	drift, err := serverlessstatistics.GetConfigurationDrift(ctx, "my-function", "1", "2", time.Hour)
+//	if err != nil {
+//		log.Fatalf("failed to get configuration drift: %v", err)
+//	}
+//	for _, change := range drift.Changes {
+//		fmt.Printf("%s %s: %s -> %s\n", change.Kind, change.Field, change.Baseline, change.Target)
+//	}
+func (a *ServerlessStats) GetConfigurationDrift(
+	ctx context.Context,
+	functionName string,
+	baselineVersion string,
+	targetVersion string,
+	window time.Duration,
+) (*sdktypes.ConfigurationDriftReturn, error) {
+	// Secrets are hashed instead of redacted, such that changes of them can be detected.
+	redactionPolicy := sdktypes.RedactionPolicy{HashSecrets: true}
+	baseline, err := a.GetFunctionConfigurationWithPolicy(ctx, functionName, baselineVersion, redactionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("baseline: %w", err)
+	}
+	target, err := a.GetFunctionConfigurationWithPolicy(ctx, functionName, targetVersion, redactionPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+
+	return metrics.GetConfigurationDrift(ctx, a.cloudwatchFetcher, a.invocationsCache, baseline, target, window)
+}
+
+// CompareConfigurationSnapshots compares two configuration snapshots of the same AWS Lambda function,
+// e.g. snapshots of the same version taken at two points in time with GetFunctionConfiguration,
+// and correlates the changes with the metrics before and after the target snapshot was last modified.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - baseline: The earlier snapshot.
+//   - target: The later snapshot.
+//   - window: Length of the windows before and after the change to compare the metrics in. If 0, metrics are not compared.
+//
+// Returns:
+//   - *sdktypes.ConfigurationDriftReturn: Struct containing the changes and the metric shift as in GetConfigurationDrift.
+//   - error: Returned if the snapshots are of different functions, or if metric queries fail.
+//
+// Notes:
+//   - Changes of redacted environment variables cannot be detected. Take the snapshots with
+//     GetFunctionConfigurationWithPolicy, HashSecrets and the same HashKey to compare secrets without revealing them.
+//
+// Example:
+//
+//	drift, err := serverlessstatistics.CompareConfigurationSnapshots(ctx, yesterday, today, time.Hour)
+//	if err != nil {
+//		log.Fatalf("failed to compare configuration snapshots: %v", err)
+//	}
+//	fmt.Printf("Drift: %t\n", drift.HasDrift)
+func (a *ServerlessStats) CompareConfigurationSnapshots(
+	ctx context.Context,
+	baseline *sdktypes.BaseStatisticsReturn,
+	target *sdktypes.BaseStatisticsReturn,
+	window time.Duration,
+) (*sdktypes.ConfigurationDriftReturn, error) {
+	return metrics.GetConfigurationDrift(ctx, a.cloudwatchFetcher, a.invocationsCache, baseline, target, window)
+}
+
+// GetDeploymentTimeline returns the code deployments of a given AWS Lambda function that were active
+// within the specified time range.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//
+// Returns:
+//   - *sdktypes.DeploymentTimelineReturn: Struct containing the deployments in ascending order, starting with
+//     the one active at the start time, with their code hash, the first published version with that code,
+//     the source they were read from and, for CloudTrail events, the identity that deployed them.
+//   - error: Returned if the function does not exist, or if the versions cannot be listed.
+//
+// Notes:
+//   - Deployments are read from ListVersionsByFunction and the last modification of $LATEST, and from
+//     UpdateFunctionCode events in CloudTrail if the credentials allow cloudtrail:LookupEvents.
+//     CloudTrail keeps these events for 90 days.
+//   - Without published versions or CloudTrail events only the last modification of $LATEST is known,
+//     which can also be a configuration update.
+//
+// Example:
+//
+//	timeline, err := serverlessstatistics.GetDeploymentTimeline(ctx, "my-function", time.Now().Add(-7*24*time.Hour), time.Now())
+//	if err != nil {
+//		log.Fatalf("failed to get deployment timeline: %v", err)
+//	}
+//	for _, deployment := range timeline.Deployments {
+//		fmt.Printf("%s: %s (%s)\n", deployment.Time, deployment.CodeSha256, deployment.Source)
+//	}
+func (a *ServerlessStats) GetDeploymentTimeline(
+	ctx context.Context,
+	functionName string,
+	startTime, endTime time.Time,
+) (*sdktypes.DeploymentTimelineReturn, error) {
+	if err := a.checkFunctionAndVersion(ctx, functionName, "$LATEST"); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetDeploymentTimeline(ctx, a.lambdaClient, a.cloudTrailClient, functionName, startTime, endTime)
+}
+
+// SplitByDeployments calculates a metric of the $LATEST version of a given AWS Lambda function separately
+// for every code revision deployed within the specified time range. $LATEST mixes the code of all updates
+// within a window, which this separates without requiring published versions.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - stats: The ServerlessStats instance to read the deployments with.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - metric: Any method of ServerlessStats that takes a function name, version and time range,
+//     e.g. stats.GetErrorRate or stats.GetDurationStatistics.
+//
+// Returns:
+//   - *sdktypes.DeploymentSplitReturn[T]: Struct containing a segment per code revision with the deployment,
+//     its part of the time range and the result of the metric.
+//   - error: Returned if the function does not exist, or if the metric fails for a segment with invocations.
+//
+// Notes:
+//   - The deployments are read as in GetDeploymentTimeline.
+//   - Segments without invocations have NoInvocations set instead of failing the split.
+//   - CloudWatch metrics have a resolution of one minute, so metrics read from them are only split accurately
+//     at minute boundaries.
+//
+// Example:
+//
+//	split, err := serverlessstatistics.SplitByDeployments(ctx, stats, "my-function",
+//		time.Now().Add(-24*time.Hour), time.Now(), stats.GetErrorRate)
+//	if err != nil {
+//		log.Fatalf("failed to split error rate by deployments: %v", err)
+//	}
+//	for _, segment := range split.Segments {
+//		if !segment.NoInvocations {
+//			fmt.Printf("%s - %s: %.2f%%\n", segment.StartTime, segment.EndTime, segment.Result.ErrorRate*100)
+//		}
+//	}
+func SplitByDeployments[T any](
+	ctx context.Context,
+	stats *ServerlessStats,
+	functionName string,
+	startTime, endTime time.Time,
+	metric func(ctx context.Context, functionName string, version string, startTime, endTime time.Time) (T, error),
+) (*sdktypes.DeploymentSplitReturn[T], error) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    "$LATEST",
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := stats.checkFunctionAndVersion(ctx, functionName, "$LATEST"); err != nil {
+		return nil, err
+	}
+
+	return metrics.SplitByDeployments(ctx, stats.lambdaClient, stats.cloudTrailClient, query,
+		func(ctx context.Context, query sdktypes.FunctionQuery) (T, error) {
+			return metric(ctx, query.FunctionName, query.Qualifier, query.StartTime, query.EndTime)
+		})
+}
+
+// AuditFunction evaluates audit rules against the configuration of a given AWS Lambda function and version
+// and the metrics observed within the specified time range, and reports every violation with its severity.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to audit.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - options: The rules to evaluate, the default rules with the settings of the options if nil,
+//     and whether the function is critical.
+//
+// Returns:
+//   - *sdktypes.AuditReportReturn: Struct containing the findings ordered by severity, their counts per severity,
+//     the highest severity and the rules that passed.
+//   - error: Returned if the function or version does not exist, or if API calls, metric or log queries fail.
+//
+// Notes:
+//   - The default rules flag deprecated and soon deprecated runtimes, over-provisioned memory, the maximum timeout,
+//     asynchronous invocations without a dead-letter queue or on-failure destination, critical functions without
+//     reserved concurrency and disabled X-Ray tracing.
+//   - Custom rules are sdktypes.AuditRule values with a Check function and can be appended to DefaultAuditRules().
+//   - Runtime deprecation dates and thresholds of the default rules can be overridden with sdktypes.AuditSettings,
+//     e.g. read from a JSON file with LoadAuditSettings, without writing rules.
+//   - Functions without invocations in the time range are audited too, rules on the memory usage then pass.
+//
+// Example:
+//
+//	rules := append(serverlessstatistics.DefaultAuditRules(), sdktypes.AuditRule{
+//		ID:       "arm64",
+//		Severity: sdktypes.AuditSeverityLow,
+//		Check: func(subject sdktypes.AuditSubject) []string {
+//			if subject.Configuration.Architectures[0] != "arm64" {
+//				return []string{"function does not run on arm64"}
+//			}
+//			return nil
+//		},
+//	})
+//	report, err := serverlessstatistics.AuditFunction(ctx, "my-function", "", time.Now().Add(-7*24*time.Hour), time.Now(),
+//		sdktypes.AuditOptions{Rules: rules, Critical: true})
+//	if err != nil {
+//		log.Fatalf("failed to audit function: %v", err)
+//	}
+//	for _, finding := range report.Findings {
+//		fmt.Printf("[%s] %s: %s\n", finding.Severity, finding.RuleID, finding.Message)
+//	}
+func (a *ServerlessStats) AuditFunction(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	options sdktypes.AuditOptions,
+) (*sdktypes.AuditReportReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetAuditReport(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.lambdaClient, a.lambdaClient,
+		a.lambdaClient, a.invocationsCache, query, options, time.Now())
+}
+
+// DefaultAuditRules returns the rules AuditFunction evaluates if no rules and settings are configured.
+// Custom rules can be appended to them, or they can be filtered by their ID.
+func DefaultAuditRules() []sdktypes.AuditRule {
+	return audit.DefaultRules()
+}
+
+// AuditRules returns the default audit rules with the data of the settings, e.g. to append custom rules
+// to them. An error is returned if a deprecation date or threshold of the settings is invalid.
+func AuditRules(settings sdktypes.AuditSettings) ([]sdktypes.AuditRule, error) {
+	return audit.Rules(settings)
+}
+
+// LoadAuditSettings reads the settings of the default audit rules from a JSON file, e.g.
+//
+//	{"runtimeDeprecations": {"nodejs22.x": "2027-04-30"}, "deprecationWarningDays": 90, "overProvisionedUsageRate": 0.3}
+func LoadAuditSettings(path string) (sdktypes.AuditSettings, error) {
+	return audit.LoadSettings(path)
+}
+
+// RuntimeDeprecations returns the built-in runtime deprecation dates the default audit rules compare against.
+func RuntimeDeprecations() map[string]time.Time {
+	return audit.RuntimeDeprecations()
+}
+
+// AnalyzeCanary compares the canary version of a given AWS Lambda function with the baseline version
+// within the specified time range and decides whether the rollout can proceed or must be rolled back.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - alias: (Optional) Alias the traffic is shifted on. Required if a version is empty.
+//   - baselineVersion: (Optional) Version the traffic is shifted from. If empty, the primary version of the alias.
+//   - canaryVersion: (Optional) Version the traffic is shifted to. If empty, the additional version of the alias.
+//   - startTime: Start of the time window to analyze (should be within log retention).
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - thresholds: The largest degradations of the canary that still pass, see DefaultCanaryThresholds.
+//
+// Returns:
+//   - *sdktypes.CanaryAnalysisReturn: Struct containing the verdict, the checks of the error rate, p99 duration
+//     and timeout rate with their reasons, the statistics of both versions and the weight of the canary.
+//   - error: Returned if the function, alias or a version does not exist, the alias does not route traffic
+//     to exactly one additional version, or if metric or log queries fail.
+//
+// Notes:
+//   - The verdict is ROLLBACK if any check fails, INCONCLUSIVE if no check fails but one lacks invocations
+//     to be decided, and PROCEED otherwise.
+//   - Rates are compared by their absolute difference, the p99 duration by its relative difference.
+//   - The error rate is read from CloudWatch metrics, with an alias only from the invocations through the alias,
+//     split by the executed version. The p99 duration and timeout rate are read from the REPORT lines in the logs.
+//     Every check reports its source and is decided on the invocations of its source.
+//   - The p99 duration requires at least 100 invocations of each version.
+//
+// Example:
+//
+//	analysis, err := serverlessstatistics.AnalyzeCanary(ctx, "my-function", "live", "", "",
+//		time.Now().Add(-30*time.Minute), time.Now(), serverlessstatistics.DefaultCanaryThresholds())
+//	if err != nil {
+//		log.Fatalf("failed to analyze canary: %v", err)
+//	}
+//	for _, check := range analysis.Checks {
+//		fmt.Printf("%s %s: %s\n", check.Metric, check.Status, check.Reason)
+//	}
+//	fmt.Printf("Verdict: %s\n", analysis.Verdict)
+func (a *ServerlessStats) AnalyzeCanary(
+	ctx context.Context,
+	functionName string,
+	alias string,
+	baselineVersion, canaryVersion string,
+	startTime, endTime time.Time,
+	thresholds sdktypes.CanaryThresholds,
+) (*sdktypes.CanaryAnalysisReturn, error) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkCanaryVersions(ctx, functionName, alias, baselineVersion, canaryVersion); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetCanaryAnalysis(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, query,
+		alias, baselineVersion, canaryVersion, thresholds)
+}
+
+// checkCanaryVersions returns an error if the function, or one of the given versions or the alias does not exist.
+func (a *ServerlessStats) checkCanaryVersions(ctx context.Context, functionName, alias string, versions ...string) error {
+	if alias != "" {
+		versions = append(versions, alias)
+	}
+	checked := false
+	for _, version := range versions {
+		if version == "" {
+			continue
+		}
+		if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+			return err
+		}
+		checked = true
+	}
+	if !checked {
+		return a.checkFunctionAndVersion(ctx, functionName, "$LATEST")
+	}
+	return nil
+}
+
+// DefaultCanaryThresholds returns thresholds that tolerate one percentage point more errors, a 20% higher
+// p99 duration and half a percentage point more timeouts, decided on at least 100 invocations per version.
+func DefaultCanaryThresholds() sdktypes.CanaryThresholds {
+	return metrics.DefaultCanaryThresholds()
+}
+
+// CanaryHookHandler returns a handler for CodeDeploy BeforeAllowTraffic and AfterAllowTraffic hooks that analyzes
+// the canary over the configured window before the invocation and reports the outcome to CodeDeploy.
+//
+// Input Parameters:
+//   - config: The function, alias and versions to analyze, the window, the thresholds and whether
+//     inconclusive analyses let the deployment proceed.
+//
+// Returns:
+//   - func(ctx, sdktypes.CodeDeployHookEvent) error: Handler to start the hook function with. It returns an error
+//     if the analysis or reporting the status fails.
+//
+// Notes:
+//   - A PROCEED verdict is reported as Succeeded, a ROLLBACK verdict as Failed, which makes CodeDeploy roll back.
+//     INCONCLUSIVE verdicts are reported as Failed unless ProceedOnInconclusive is set.
+//   - BeforeAllowTraffic (PreTraffic) hooks run before the canary receives traffic, so their verdict is
+//     INCONCLUSIVE and they fail unless ProceedOnInconclusive is set.
+//   - Failed analyses are reported as Failed, so the deployment does not wait for the hook to time out.
+//   - The alias has no routing config while the hooks run, so versions that are not configured are read
+//     from the CurrentVersion and TargetVersion in the AppSpec of the deployment.
+//   - The hook function needs codedeploy:GetDeployment and codedeploy:PutLifecycleEventHookExecutionStatus
+//     in addition to the permissions of AnalyzeCanary.
+//
+// Example:
+//
+//	stats := serverlessstatistics.New(ctx, sdktypes.ConfigOptions{})
+//	lambda.Start(stats.CanaryHookHandler(sdktypes.CanaryHookConfig{
+//		FunctionName: "my-function",
+//		Alias:        "live",
+//		Window:       10 * time.Minute,
+//		Thresholds:   serverlessstatistics.DefaultCanaryThresholds(),
+//	}))
+func (a *ServerlessStats) CanaryHookHandler(config sdktypes.CanaryHookConfig) func(ctx context.Context, event sdktypes.CodeDeployHookEvent) error {
+	return func(ctx context.Context, event sdktypes.CodeDeployHookEvent) error {
+		_, err := metrics.HandleCanaryHook(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.codeDeployClient,
+			config, event, time.Now())
+		return err
+	}
+}
+
+// EvaluateSLOs evaluates declarative SLO specs of a given AWS Lambda function and version over their rolling
+// compliance windows ending at the specified time, and reports the remaining error budget and burn rate alerts.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to evaluate.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - endTime: End of the compliance windows (typically time.Now()), aligned down to 5 minutes.
+//   - specs: The SLOs to evaluate, each with an indicator, an objective, and optionally a window and alerts.
+//
+// Returns:
+//   - *sdktypes.SLOReportReturn: Struct containing per SLO the good and total invocations, the attainment,
+//     whether the objective is met, the remaining error budget and the burn rates of every alert with
+//     whether it would fire, and the number of firing alerts.
+//   - error: Returned if the function or version does not exist, a spec is invalid, or if metric or log queries fail.
+//
+// Notes:
+//   - The success indicator counts invocations without errors from CloudWatch metrics, the noTimeout and latency
+//     indicators count invocations from the REPORT lines in the logs. A latency SLO like "p99 under 800 ms" is
+//     declared as 99% of invocations at most 800 ms long.
+//   - The compliance window defaults to 30 days and can be at most about 34 days. Invocations are counted
+//     in 5 minute buckets, windows are extended to full buckets.
+//   - An alert fires if the burn rate exceeds its threshold over both its long and its short window. The default
+//     alerts (DefaultBurnRateAlerts) are 14.4 over 1h/5m and 6 over 6h/30m, which assume a 30 day window.
+//   - Without invocations in the window, the attainment is nil, the objective is met and no alert fires.
+//
+// Example:
+//
+//	report, err := serverlessstatistics.EvaluateSLOs(ctx, "my-function", "", time.Now(), []sdktypes.SLOSpec{
+//		{Name: "availability", Indicator: sdktypes.SLOIndicatorSuccess, Objective: 0.999},
+//		{Name: "latency", Indicator: sdktypes.SLOIndicatorLatency, Objective: 0.99, LatencyThresholdMs: 800},
+//	})
+//	if err != nil {
+//		log.Fatalf("failed to evaluate slos: %v", err)
+//	}
+//	for _, status := range report.SLOs {
+//		fmt.Printf("%s: %.1f%% of the error budget left\n", status.Name, status.ErrorBudgetRemaining*100)
+//		for _, alert := range status.Alerts {
+//			if alert.Firing {
+//				fmt.Printf("  %s is firing\n", alert.Name)
+//			}
+//		}
+//	}
+func (a *ServerlessStats) EvaluateSLOs(
+	ctx context.Context,
+	functionName string,
+	version string,
+	endTime time.Time,
+	specs []sdktypes.SLOSpec,
+) (*sdktypes.SLOReportReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetSLOReport(ctx, a.logsFetcher, a.cloudwatchFetcher, query, specs)
+}
+
+// DefaultBurnRateAlerts returns the alerts EvaluateSLOs evaluates for specs without alerts:
+// a burn rate of 14.4 over 1 hour and 5 minutes, and of 6 over 6 hours and 30 minutes.
+func DefaultBurnRateAlerts() []sdktypes.BurnRateAlert {
+	return slo.DefaultBurnRateAlerts()
+}
+
+// GetAnomalies fetches the error rate, p99 duration and cold starts of a given AWS Lambda function and version
+// per bucket within the specified time range and flags error rate spikes, duration regressions and cold start
+// surges in them.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze, including the history the baseline is built from.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - options: The detection method, direction, threshold and bucket size, see sdktypes.AnomalyOptions.
+//
+// Returns:
+//   - *sdktypes.AnomalyReportReturn: Struct containing the anomalies of all metrics ordered by start, each with
+//     its start and end, severity, metric and the value and expected value at its peak, the detection per metric
+//     and the analyzed series.
+//   - error: Returned if the function or version does not exist, the options are invalid,
+//     or if metric or log queries fail.
+//
+// Notes:
+//   - By default every hourly bucket is compared with the same hour in the previous 4 weeks (seasonal-mad), so the
+//     time range needs to start at least 3 weeks before the first bucket to evaluate. A warning is returned for
+//     metrics without enough history.
+//   - Buckets without invocations are left out of the error rate series. The noise of the error rate is at least
+//     the Poisson noise of the expected errors in the invocations of the bucket, so single errors after a period
+//     without errors are no anomaly.
+//   - Cold starts are read from the logs, which are limited by the retention of the log group.
+//   - Series of any other source can be analyzed offline with DetectAnomalies.
+//
+// Example:
+//
+//	report, err := serverlessstatistics.GetAnomalies(ctx, "my-function", "", time.Now().Add(-5*7*24*time.Hour), time.Now(),
+//		sdktypes.AnomalyOptions{})
+//	if err != nil {
+//		log.Fatalf("failed to detect anomalies: %v", err)
+//	}
+//	for _, anomaly := range report.Anomalies {
+//		fmt.Printf("[%s] %s %s - %s: %.2f instead of %.2f\n", anomaly.Severity, anomaly.Metric,
+//			anomaly.Start, anomaly.End, anomaly.Value, anomaly.Expected)
+//	}
+func (a *ServerlessStats) GetAnomalies(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	options sdktypes.AnomalyOptions,
+) (*sdktypes.AnomalyReportReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetAnomalies(ctx, a.logsFetcher, a.cloudwatchFetcher, query, options)
+}
+
+// DetectAnomalies detects anomalies in a series the caller provides, without any AWS calls.
+//
+// Input Parameters:
+//   - metric: Name of the metric, set on the anomalies.
+//   - series: Points of the series in buckets of equal width, in any order.
+//   - options: The detection method, direction and threshold, see sdktypes.AnomalyOptions.
+//
+// Returns:
+//   - *sdktypes.AnomalyDetectionReturn: Struct containing the anomalies ordered by start,
+//     and the number of evaluated points and of points without enough history.
+//   - error: Returned if the options are invalid.
+//
+// Notes:
+//   - seasonal-mad compares every point with the median of the points at the same time in previous seasons and
+//     scores it by its distance in MADs, so outliers in the history do not distort the baseline.
+//   - ewma smooths the series and flags points where the EWMA leaves the control limits estimated from the
+//     first WarmupPoints points, which detects sustained shifts earlier than single outliers.
+//   - Consecutive anomalous points are merged into one anomaly ending after the last bucket.
+//   - Flat baselines only get a noise floor of 1% of the expected value, so for rates whose baseline is zero
+//     set MinDeviation to the smallest change that matters.
+//
+// Example:
+//
+//	detection, err := serverlessstatistics.DetectAnomalies("queueDepth", points, sdktypes.AnomalyOptions{
+//		Method:    sdktypes.AnomalyMethodEWMA,
+//		Direction: sdktypes.AnomalyDirectionBoth,
+//	})
+//	if err != nil {
+//		log.Fatalf("failed to detect anomalies: %v", err)
+//	}
+//	fmt.Printf("%d anomalies in %d points\n", len(detection.Anomalies), detection.EvaluatedPoints)
+func DetectAnomalies(metric string, series []sdktypes.MetricPoint, options sdktypes.AnomalyOptions) (*sdktypes.AnomalyDetectionReturn, error) {
+	options, err := anomaly.Normalize(options)
+	if err != nil {
+		return nil, err
+	}
+	return anomaly.Detect(metric, series, options), nil
+}
+
+// GetChangePoints fetches the error rate, p99 duration and max memory used of a given AWS Lambda function and
+// version per bucket within the specified time range, pinpoints when their mean shifted, and annotates every
+// change with the nearest deployment, telling which release introduced a regression.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the time window to analyze.
+//   - endTime: End of the time window to analyze (typically time.Now()).
+//   - options: The penalty, minimum segment length and relative change, the attribution window and the bucket
+//     size, see sdktypes.ChangePointOptions.
+//
+// Returns:
+//   - *sdktypes.ChangePointReportReturn: Struct containing the change points of all metrics ordered by time, each
+//     with the means before and after, its direction and the nearest deployment, the detection per metric,
+//     the deployments and the analyzed series.
+//   - error: Returned if the function or version does not exist, the options are invalid,
+//     or if API calls, metric or log queries fail.
+//
+// Notes:
+//   - Change points are found with PELT, which minimizes the squared deviations from the segment means plus a BIC
+//     penalty per change point. The series are scaled by the noise, estimated robustly from the differences
+//     of neighbouring buckets, so the penalty does not depend on the unit of the metric.
+//   - Deployments are read as in GetDeploymentTimeline. A change is attributed to the nearest deployment if they
+//     are at most the attribution window (two buckets by default) apart.
+//   - Strong daily patterns show up as change points in hourly buckets; use daily buckets for such metrics.
+//   - The max memory used is read from the logs and is limited by their retention.
+//
+// Example:
+//
+//	report, err := serverlessstatistics.GetChangePoints(ctx, "my-function", "", time.Now().Add(-14*24*time.Hour), time.Now(),
+//		sdktypes.ChangePointOptions{MinRelativeChange: 0.1})
+//	if err != nil {
+//		log.Fatalf("failed to detect change points: %v", err)
+//	}
+//	for _, change := range report.ChangePoints {
+//		if change.Direction == sdktypes.AnomalyDirectionUp && change.Attributed {
+//			fmt.Printf("%s increased from %.2f to %.2f after deploying %s\n", change.Metric,
+//				change.MeanBefore, change.MeanAfter, change.NearestDeployment.CodeSha256)
+//		}
+//	}
+func (a *ServerlessStats) GetChangePoints(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	options sdktypes.ChangePointOptions,
+) (*sdktypes.ChangePointReportReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetChangePoints(ctx, a.logsFetcher, a.cloudwatchFetcher, a.lambdaClient, a.cloudTrailClient, query, options)
+}
+
+// DetectChangePoints finds the change points of a series the caller provides and annotates them with the nearest
+// of the given deployments, e.g. from GetDeploymentTimeline, without any AWS calls.
+//
+// Input Parameters:
+//   - metric: Name of the metric, set on the change points.
+//   - series: Points of the series in buckets of equal width, in any order.
+//   - deployments: (Optional) Deployments to annotate the change points with.
+//   - options: The penalty, minimum segment length and relative change and the attribution window.
+//
+// Returns:
+//   - *sdktypes.ChangePointDetectionReturn: Struct containing the change points ordered by time and the estimated
+//     standard deviation of the noise.
+//   - error: Returned if the options are invalid.
+//
+// Example:
+//
+//	detection, err := serverlessstatistics.DetectChangePoints("queueDepth", points, timeline.Deployments,
+//		sdktypes.ChangePointOptions{})
+//	if err != nil {
+//		log.Fatalf("failed to detect change points: %v", err)
+//	}
+//	fmt.Printf("%d change points\n", len(detection.ChangePoints))
+func DetectChangePoints(
+	metric string,
+	series []sdktypes.MetricPoint,
+	deployments []sdktypes.Deployment,
+	options sdktypes.ChangePointOptions,
+) (*sdktypes.ChangePointDetectionReturn, error) {
+	options, err := changepoint.Normalize(options)
+	if err != nil {
+		return nil, err
+	}
+	return changepoint.Detect(metric, series, deployments, options), nil
+}
+
+// ForecastCapacity forecasts the daily invocations, GB-seconds and peak concurrency of a given AWS Lambda function
+// and version from their history within the specified time range, predicts when the function or the account
+// will hit its concurrency limit, and estimates the cost of the next calendar month for capacity planning.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the history to forecast from.
+//   - endTime: End of the history (typically time.Now()). Only full UTC days from the day of startTime on are used.
+//   - options: The model, horizon, confidence of the prediction intervals, season length and smoothing,
+//     see sdktypes.ForecastOptions.
+//
+// Returns:
+//   - *sdktypes.CapacityForecastReturn: Struct containing the forecast with prediction intervals and the trend per
+//     day of every metric, when the peak concurrency is expected to reach the reserved (or unreserved) and the
+//     account concurrency limit, the cost estimate of the next month and the daily history.
+//   - error: Returned if the function or version does not exist, the options are invalid, the time range
+//     covers less than two full days, or if API calls or metric queries fail.
+//
+// Notes:
+//   - The linear model extrapolates a least squares trend line. The holt-winters model additionally follows a weekly
+//     seasonality and adapts to recent changes of the level and trend; it needs at least two weeks of history
+//     and falls back to the linear model with a warning otherwise.
+//   - The cost is the list price of us-east-1 at the current memory size and architecture, without free tier. Its
+//     bounds sum the daily bounds and are therefore wide.
+//   - Limits are only reported as reached if that happens within the horizon (30 days by default).
+//
+// Example:
+//
+//	capacity, err := serverlessstatistics.ForecastCapacity(ctx, "my-function", "", time.Now().Add(-90*24*time.Hour), time.Now(),
+//		sdktypes.ForecastOptions{Model: sdktypes.ForecastModelHoltWinters, Horizon: 90 * 24 * time.Hour})
+//	if err != nil {
+//		log.Fatalf("failed to forecast capacity: %v", err)
+//	}
+//	for _, limit := range capacity.Limits {
+//		if limit.ExpectedAt != nil {
+//			fmt.Printf("%s concurrency limit of %d expected to be reached on %s\n", limit.Limit, limit.Concurrency, limit.ExpectedAt.Format(time.DateOnly))
+//		}
+//	}
+//	fmt.Printf("Next month: $%.2f ($%.2f - $%.2f)\n", capacity.NextMonthCost.Cost, capacity.NextMonthCost.CostLower, capacity.NextMonthCost.CostUpper)
+func (a *ServerlessStats) ForecastCapacity(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	options sdktypes.ForecastOptions,
+) (*sdktypes.CapacityForecastReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetCapacityForecast(ctx, a.cloudwatchFetcher, a.lambdaClient, a.lambdaClient, query, options)
+}
+
+// ForecastSeries forecasts a series the caller provides, e.g. a series of another report, without any AWS calls.
+//
+// Input Parameters:
+//   - metric: Name of the metric, set on the forecast.
+//   - series: Points of the series in buckets of equal width without gaps, in any order.
+//   - options: The model, horizon, confidence of the prediction intervals, season length and smoothing.
+//
+// Returns:
+//   - *sdktypes.SeriesForecast: Struct containing the forecast points from the bucket after the last point
+//     up to the horizon with their prediction intervals, the model used and the trend per bucket.
+//   - error: Returned if the options are invalid, the season length is not a multiple of the bucket width,
+//     or if the series has less than two points.
+//
+// Example:
+//
+//	forecast, err := serverlessstatistics.ForecastSeries("queueDepth", points, sdktypes.ForecastOptions{Horizon: 7 * 24 * time.Hour})
+//	if err != nil {
+//		log.Fatalf("failed to forecast: %v", err)
+//	}
+//	fmt.Printf("In a week: %.0f (%.0f - %.0f)\n", forecast.Forecast[len(forecast.Forecast)-1].Value,
+//		forecast.Forecast[len(forecast.Forecast)-1].Lower, forecast.Forecast[len(forecast.Forecast)-1].Upper)
+func ForecastSeries(metric string, series []sdktypes.MetricPoint, options sdktypes.ForecastOptions) (*sdktypes.SeriesForecast, error) {
+	options, err := forecast.Normalize(options)
+	if err != nil {
+		return nil, err
+	}
+	return forecast.Forecast(metric, series, options)
+}
+
+// ComparePeriods compares the metrics of a given AWS Lambda function and version within the specified time range
+// with the same time range shifted back by a day, a week or a custom offset, e.g. the error rate of this week
+// with last week or the p95 duration of today with the same day last week.
+//
+// Input Parameters:
+//   - ctx: Context for timeout and cancellation handling.
+//   - functionName: The name of the AWS Lambda function to analyze.
+//   - version: (Optional) Lambda version. If empty, defaults to "$LATEST".
+//   - startTime: Start of the current period.
+//   - endTime: End of the current period (typically time.Now()).
+//   - options: The offset (sdktypes.ComparePreviousDay, sdktypes.ComparePreviousWeek or any duration), the metrics
+//     to compare and the significance level and minimum relative change, see sdktypes.PeriodComparisonOptions.
+//
+// Returns:
+//   - *sdktypes.PeriodComparisonReturn: Struct containing the value of every metric in both periods with the
+//     absolute and relative delta, the p-value and whether the change is significant, and both time ranges.
+//   - error: Returned if the function or version does not exist, the options are invalid,
+//     or if metric queries fail.
+//
+// Notes:
+//   - Every metric is calculated with the same calculator as its own method, e.g. GetErrorRate, so the values match.
+//   - Rates and means are tested with a two-sided z-test from their standard errors; a change is significant if
+//     the p-value is below the significance level and the relative change reaches the minimum (10% by default).
+//     Percentiles have no standard error and are significant if only the relative change reaches the minimum.
+//   - Periods that ended more than an hour ago do not change anymore and are cached for the lifetime of the
+//     ServerlessStats instance, so repeated comparisons only query the previous period once.
+//   - Metrics other than the invocations are only compared if both periods have invocations; log based metrics
+//     require both periods to be within log retention. If they fail for a period, e.g. beyond the retention,
+//     they are left out with a warning and the period is not cached.
+//
+// Example:
+//
+//	comparison, err := serverlessstatistics.ComparePeriods(ctx, "my-function", "", time.Now().Add(-7*24*time.Hour), time.Now(),
+//		sdktypes.PeriodComparisonOptions{Metrics: []sdktypes.ComparisonMetric{sdktypes.ComparisonMetricErrorRate}})
+//	if err != nil {
+//		log.Fatalf("failed to compare periods: %v", err)
+//	}
+//	for _, change := range comparison.Comparisons {
+//		if change.Significant {
+//			fmt.Printf("%s changed from %.4f to %.4f\n", change.Metric, change.Previous.Value, change.Current.Value)
+//		}
+//	}
+func (a *ServerlessStats) ComparePeriods(
+	ctx context.Context,
+	functionName string,
+	version string,
+	startTime, endTime time.Time,
+	options sdktypes.PeriodComparisonOptions,
+) (*sdktypes.PeriodComparisonReturn, error) {
+	if version == "" {
+		version = "$LATEST"
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: functionName,
+		Qualifier:    version,
+		StartTime:    startTime,
+		EndTime:      endTime,
+	}
+
+	if err := a.checkFunctionAndVersion(ctx, functionName, version); err != nil {
+		return nil, err
+	}
+
+	return metrics.GetPeriodComparison(ctx, a.logsFetcher, a.cloudwatchFetcher, a.invocationsCache, a.periodCache, query, options, time.Now())
 }
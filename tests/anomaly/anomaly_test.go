@@ -0,0 +1,182 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/anomaly"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+var start = time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+
+// weeklySeries returns hourly points for the given weeks with a daily pattern and a little noise,
+// and applies override to the value of every hour.
+func weeklySeries(weeks int, override func(hour int, value float64) float64) []sdktypes.MetricPoint {
+	points := []sdktypes.MetricPoint{}
+	for hour := 0; hour < weeks*7*24; hour++ {
+		value := 100.0
+		if hourOfDay := hour % 24; hourOfDay >= 8 && hourOfDay < 20 {
+			value = 200
+		}
+		value += float64((hour*7)%5) - 2
+		points = append(points, sdktypes.MetricPoint{Timestamp: start.Add(time.Duration(hour) * time.Hour), Value: override(hour, value)})
+	}
+	return points
+}
+
+func normalize(t *testing.T, options sdktypes.AnomalyOptions) sdktypes.AnomalyOptions {
+	t.Helper()
+	options, err := anomaly.Normalize(options)
+	require.NoError(t, err)
+	return options
+}
+
+func TestDetect_SeasonalSpike(t *testing.T) {
+	spikeHour := 4*7*24 + 10
+	series := weeklySeries(5, func(hour int, value float64) float64 {
+		if hour == spikeHour || hour == spikeHour+1 {
+			return 600
+		}
+		return value
+	})
+
+	detection := anomaly.Detect("p99DurationMs", series, normalize(t, sdktypes.AnomalyOptions{}))
+
+	require.Equal(t, 3*7*24, detection.SkippedPoints)
+	require.Equal(t, 2*7*24, detection.EvaluatedPoints)
+	require.Len(t, detection.Anomalies, 1)
+	anomalous := detection.Anomalies[0]
+	require.Equal(t, "p99DurationMs", anomalous.Metric)
+	require.Equal(t, start.Add(time.Duration(spikeHour)*time.Hour), anomalous.Start)
+	require.Equal(t, start.Add(time.Duration(spikeHour+2)*time.Hour), anomalous.End)
+	require.Equal(t, 2, anomalous.Points)
+	require.Equal(t, sdktypes.AnomalySeverityHigh, anomalous.Severity)
+	require.Equal(t, sdktypes.AnomalyDirectionUp, anomalous.Direction)
+	require.Equal(t, 600.0, anomalous.Value)
+	require.InDelta(t, 200, anomalous.Expected, 3)
+}
+
+func TestDetect_SeasonalPatternIsNotAnomalous(t *testing.T) {
+	series := weeklySeries(5, func(hour int, value float64) float64 { return value })
+
+	detection := anomaly.Detect("errorRate", series, normalize(t, sdktypes.AnomalyOptions{}))
+
+	require.Empty(t, detection.Anomalies)
+}
+
+func TestDetect_Direction(t *testing.T) {
+	dipHour := 4*7*24 + 12
+	series := weeklySeries(5, func(hour int, value float64) float64 {
+		if hour == dipHour {
+			return 0
+		}
+		return value
+	})
+
+	up := anomaly.Detect("invocations", series, normalize(t, sdktypes.AnomalyOptions{}))
+	both := anomaly.Detect("invocations", series, normalize(t, sdktypes.AnomalyOptions{Direction: sdktypes.AnomalyDirectionBoth}))
+
+	require.Empty(t, up.Anomalies)
+	require.Len(t, both.Anomalies, 1)
+	require.Equal(t, sdktypes.AnomalyDirectionDown, both.Anomalies[0].Direction)
+}
+
+func TestDetect_MinDeviation(t *testing.T) {
+	spikeHour := 4*7*24 + 10
+	series := weeklySeries(5, func(hour int, value float64) float64 {
+		if hour == spikeHour {
+			return value + 30
+		}
+		return value
+	})
+
+	detection := anomaly.Detect("p99DurationMs", series, normalize(t, sdktypes.AnomalyOptions{}))
+	suppressed := anomaly.Detect("p99DurationMs", series, normalize(t, sdktypes.AnomalyOptions{MinDeviation: 50}))
+
+	require.Len(t, detection.Anomalies, 1)
+	require.Empty(t, suppressed.Anomalies)
+}
+
+func TestDetectRate_ZeroBaseline(t *testing.T) {
+	singleErrorHour := 4*7*24 + 10
+	burstHour := 4*7*24 + 14
+	rates := weeklySeries(5, func(hour int, value float64) float64 {
+		switch hour {
+		case singleErrorHour:
+			return 0.001
+		case burstHour:
+			return 0.01
+		}
+		return 0
+	})
+	invocations := weeklySeries(5, func(hour int, value float64) float64 { return 1000 })
+
+	detection := anomaly.Detect("errorRate", rates, normalize(t, sdktypes.AnomalyOptions{}))
+	rateDetection := anomaly.DetectRate("errorRate", rates, invocations, normalize(t, sdktypes.AnomalyOptions{}))
+
+	// Without the sample sizes the flat baseline turns a single error into an anomaly.
+	require.Len(t, detection.Anomalies, 2)
+	require.Len(t, rateDetection.Anomalies, 1)
+	require.Equal(t, start.Add(time.Duration(burstHour)*time.Hour), rateDetection.Anomalies[0].Start)
+	require.InDelta(t, 10, rateDetection.Anomalies[0].Score, 1e-9)
+}
+
+func TestDetect_EWMAShift(t *testing.T) {
+	series := []sdktypes.MetricPoint{}
+	for i := 0; i < 60; i++ {
+		value := 10 + float64(i%3)*0.5
+		if i >= 40 {
+			value += 3
+		}
+		series = append(series, sdktypes.MetricPoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: value})
+	}
+
+	detection := anomaly.Detect("coldStarts", series, normalize(t, sdktypes.AnomalyOptions{Method: sdktypes.AnomalyMethodEWMA}))
+
+	require.Equal(t, 24, detection.SkippedPoints)
+	require.Len(t, detection.Anomalies, 1)
+	// The EWMA needs a few points to leave the control limits after the shift.
+	require.WithinRange(t, detection.Anomalies[0].Start, start.Add(40*time.Minute), start.Add(43*time.Minute))
+	require.Equal(t, start.Add(60*time.Minute), detection.Anomalies[0].End)
+}
+
+func TestDetect_EWMAStable(t *testing.T) {
+	series := []sdktypes.MetricPoint{}
+	for i := 0; i < 60; i++ {
+		series = append(series, sdktypes.MetricPoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: 10 + float64(i%3)*0.5})
+	}
+
+	detection := anomaly.Detect("coldStarts", series, normalize(t, sdktypes.AnomalyOptions{Method: sdktypes.AnomalyMethodEWMA}))
+
+	require.Empty(t, detection.Anomalies)
+	require.Equal(t, 36, detection.EvaluatedPoints)
+}
+
+func TestNormalize_InvalidOptions(t *testing.T) {
+	for _, options := range []sdktypes.AnomalyOptions{
+		{Method: "prophet"},
+		{Direction: "sideways"},
+		{Threshold: -1},
+		{Smoothing: 1.5},
+	} {
+		_, err := anomaly.Normalize(options)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/audit"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// compliantSubject returns a subject that passes all default rules.
+func compliantSubject() sdktypes.AuditSubject {
+	return sdktypes.AuditSubject{
+		Configuration: &sdktypes.BaseStatisticsReturn{
+			Runtime:             "python3.12",
+			MemorySizeMB:        aws.Int32(512),
+			TimeoutSeconds:      aws.Int32(30),
+			ReservedConcurrency: aws.Int32(10),
+			TracingMode:         "Active",
+			DeadLetterTargetArn: "arn:aws:sqs:us-east-1:123456789012:dlq",
+		},
+		MemoryUsage:         &sdktypes.MemoryUsagePercentilesReturn{MaxUsageRate: 0.6},
+		AsyncEventsReceived: 100,
+		Critical:            true,
+		Now:                 time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestDefaultRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(subject *sdktypes.AuditSubject)
+		wantRule string
+	}{
+		{name: "deprecated runtime", modify: func(s *sdktypes.AuditSubject) { s.Configuration.Runtime = "python3.8" }, wantRule: "runtime-deprecated"},
+		{name: "soon deprecated runtime", modify: func(s *sdktypes.AuditSubject) { s.Configuration.Runtime = "nodejs18.x" }, wantRule: "runtime-deprecation-soon"},
+		{name: "over-provisioned memory", modify: func(s *sdktypes.AuditSubject) { s.MemoryUsage.MaxUsageRate = 0.1 }, wantRule: "memory-over-provisioned"},
+		{name: "maximum timeout", modify: func(s *sdktypes.AuditSubject) { s.Configuration.TimeoutSeconds = aws.Int32(900) }, wantRule: "timeout-at-maximum"},
+		{name: "async without failure destination", modify: func(s *sdktypes.AuditSubject) { s.Configuration.DeadLetterTargetArn = "" }, wantRule: "async-without-failure-destination"},
+		{name: "critical without reserved concurrency", modify: func(s *sdktypes.AuditSubject) { s.Configuration.ReservedConcurrency = nil }, wantRule: "critical-without-reserved-concurrency"},
+		{name: "tracing disabled", modify: func(s *sdktypes.AuditSubject) { s.Configuration.TracingMode = "PassThrough" }, wantRule: "tracing-disabled"},
+	}
+
+	report := &sdktypes.AuditReportReturn{}
+	audit.Evaluate(compliantSubject(), audit.DefaultRules(), report)
+	require.Empty(t, report.Findings)
+	require.Empty(t, report.HighestSeverity)
+	require.Len(t, report.PassedRules, len(audit.DefaultRules()))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject := compliantSubject()
+			tt.modify(&subject)
+			report := &sdktypes.AuditReportReturn{}
+			audit.Evaluate(subject, audit.DefaultRules(), report)
+			require.Len(t, report.Findings, 1)
+			require.Equal(t, tt.wantRule, report.Findings[0].RuleID)
+			require.NotContains(t, report.PassedRules, tt.wantRule)
+		})
+	}
+}
+
+func TestDefaultRules_NotApplicable(t *testing.T) {
+	subject := compliantSubject()
+	subject.Configuration.DeadLetterTargetArn = ""
+	subject.Configuration.AsyncInvokeConfig = &sdktypes.AsyncInvokeConfiguration{OnFailureDestination: "arn:aws:sqs:us-east-1:123456789012:failures"}
+	subject.Configuration.ReservedConcurrency = nil
+	subject.Critical = false
+	subject.MemoryUsage = nil
+	subject.Configuration.Runtime = "custom-runtime"
+
+	report := &sdktypes.AuditReportReturn{}
+	audit.Evaluate(subject, audit.DefaultRules(), report)
+	require.Empty(t, report.Findings)
+}
+
+func TestEvaluate_OrderAndCustomRules(t *testing.T) {
+	subject := compliantSubject()
+	subject.Configuration.TracingMode = ""
+	subject.Configuration.Runtime = "go1.x"
+	rules := append(audit.DefaultRules(), sdktypes.AuditRule{
+		ID:       "no-env-vars",
+		Severity: sdktypes.AuditSeverityMedium,
+		Check: func(subject sdktypes.AuditSubject) []string {
+			return []string{"first", "second"}
+		},
+	})
+
+	report := &sdktypes.AuditReportReturn{}
+	audit.Evaluate(subject, rules, report)
+	require.Equal(t, []sdktypes.AuditFinding{
+		{RuleID: "runtime-deprecated", Severity: sdktypes.AuditSeverityHigh, Message: "runtime go1.x is deprecated since 2024-01-08"},
+		{RuleID: "no-env-vars", Severity: sdktypes.AuditSeverityMedium, Message: "first"},
+		{RuleID: "no-env-vars", Severity: sdktypes.AuditSeverityMedium, Message: "second"},
+		{RuleID: "tracing-disabled", Severity: sdktypes.AuditSeverityLow, Message: "X-Ray active tracing is disabled"},
+	}, report.Findings)
+	require.Equal(t, sdktypes.AuditSeverityHigh, report.HighestSeverity)
+	require.Equal(t, map[sdktypes.AuditSeverity]int{
+		sdktypes.AuditSeverityHigh:   1,
+		sdktypes.AuditSeverityMedium: 2,
+		sdktypes.AuditSeverityLow:    1,
+	}, report.FindingCounts)
+}
+
+func TestRuntimeDeprecation(t *testing.T) {
+	deprecation, ok := audit.RuntimeDeprecation("nodejs16.x")
+	require.True(t, ok)
+	require.Equal(t, time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), deprecation)
+
+	deprecation, ok = audit.RuntimeDeprecation("ruby3.3")
+	require.True(t, ok)
+	require.Equal(t, time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC), deprecation)
+
+	_, ok = audit.RuntimeDeprecation("custom-runtime")
+	require.False(t, ok)
+}
+
+func TestRules_Settings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"runtimeDeprecations": {"python3.12": "2025-08-01"},
+		"deprecationWarningDays": 30,
+		"overProvisionedUsageRate": 0.7
+	}`), 0o600))
+	settings, err := audit.LoadSettings(path)
+	require.NoError(t, err)
+
+	rules, err := audit.Rules(settings)
+	require.NoError(t, err)
+	report := &sdktypes.AuditReportReturn{}
+	audit.Evaluate(compliantSubject(), rules, report)
+	require.Equal(t, []sdktypes.AuditFinding{
+		{RuleID: "memory-over-provisioned", Severity: sdktypes.AuditSeverityMedium,
+			Message: "max memory used is 60% of 512 MB; memory also scales CPU, so check the duration when lowering it"},
+	}, report.Findings)
+
+	// The overridden deprecation is 61 days ahead, outside of the warning period of 30 days.
+	settings.DeprecationWarningDays = 90
+	rules, err = audit.Rules(settings)
+	require.NoError(t, err)
+	report = &sdktypes.AuditReportReturn{}
+	audit.Evaluate(compliantSubject(), rules, report)
+	require.Len(t, report.Findings, 2)
+	require.Equal(t, "runtime python3.12 will be deprecated on 2025-08-01", report.Findings[0].Message)
+}
+
+func TestRules_InvalidSettings(t *testing.T) {
+	_, err := audit.Rules(sdktypes.AuditSettings{RuntimeDeprecations: map[string]string{"nodejs22.x": "30.04.2027"}})
+	require.ErrorContains(t, err, "invalid deprecation date")
+
+	_, err = audit.Rules(sdktypes.AuditSettings{OverProvisionedUsageRate: 1.5})
+	require.ErrorContains(t, err, "over-provisioned usage rate")
+
+	_, err = audit.LoadSettings(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "failed to read audit settings")
+}
@@ -83,3 +83,32 @@ func TestCacheConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestResultCacheSetGet(t *testing.T) {
+	c := cache.NewResultCache[map[string]float64]()
+	key := cache.CacheKey{
+		FunctionName: "myFunc",
+		Qualifier:    "v1",
+		Start:        time.Unix(1000, 0),
+		End:          time.Unix(2000, 0),
+	}
+
+	if _, ok := c.Get(key); ok {
+		t.Error("expected Get to return false for non-existing key")
+	}
+
+	c.Set(key, map[string]float64{"errorRate": 0.01})
+
+	value, ok := c.Get(key)
+	if !ok {
+		t.Error("expected Get to return true for existing key")
+	}
+	if value["errorRate"] != 0.01 {
+		t.Errorf("expected error rate 0.01, got %v", value["errorRate"])
+	}
+	other := key
+	other.End = time.Unix(3000, 0)
+	if _, ok := c.Get(other); ok {
+		t.Error("expected Get to return false for a different time range")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/changepoint"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+var start = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// levelSeries returns hourly points at the given levels, each for the given number of hours, with a little noise.
+func levelSeries(hours int, levels ...float64) []sdktypes.MetricPoint {
+	points := []sdktypes.MetricPoint{}
+	for _, level := range levels {
+		for i := 0; i < hours; i++ {
+			hour := len(points)
+			points = append(points, sdktypes.MetricPoint{
+				Timestamp: start.Add(time.Duration(hour) * time.Hour),
+				Value:     level + float64((hour*7)%5) - 2,
+			})
+		}
+	}
+	return points
+}
+
+func normalize(t *testing.T, options sdktypes.ChangePointOptions) sdktypes.ChangePointOptions {
+	t.Helper()
+	options, err := changepoint.Normalize(options)
+	require.NoError(t, err)
+	return options
+}
+
+func TestDetect_StepAttributedToDeployment(t *testing.T) {
+	deployments := []sdktypes.Deployment{
+		{Time: start.Add(-10 * 24 * time.Hour), CodeSha256: "old"},
+		{Time: start.Add(47*time.Hour + 30*time.Minute), CodeSha256: "regression", Version: "7"},
+	}
+
+	detection := changepoint.Detect("p99DurationMs", levelSeries(48, 300, 450), deployments, normalize(t, sdktypes.ChangePointOptions{}))
+
+	require.Len(t, detection.ChangePoints, 1)
+	change := detection.ChangePoints[0]
+	require.Equal(t, start.Add(48*time.Hour), change.Time)
+	require.Equal(t, sdktypes.AnomalyDirectionUp, change.Direction)
+	require.InDelta(t, 300, change.MeanBefore, 1)
+	require.InDelta(t, 450, change.MeanAfter, 1)
+	require.InDelta(t, 0.5, *change.RelativeChange, 0.01)
+	require.NotNil(t, change.NearestDeployment)
+	require.Equal(t, "regression", change.NearestDeployment.CodeSha256)
+	require.Equal(t, 30*time.Minute, change.DeploymentDistance)
+	require.True(t, change.Attributed)
+}
+
+func TestDetect_NoChange(t *testing.T) {
+	detection := changepoint.Detect("errorRate", levelSeries(96, 100), nil, normalize(t, sdktypes.ChangePointOptions{}))
+
+	require.Empty(t, detection.ChangePoints)
+	require.Greater(t, detection.NoiseStdDev, 0.0)
+}
+
+func TestDetect_UpAndDown(t *testing.T) {
+	detection := changepoint.Detect("maxMemoryUsedMB", levelSeries(24, 200, 400, 200), nil, normalize(t, sdktypes.ChangePointOptions{}))
+
+	require.Len(t, detection.ChangePoints, 2)
+	require.Equal(t, start.Add(24*time.Hour), detection.ChangePoints[0].Time)
+	require.Equal(t, sdktypes.AnomalyDirectionUp, detection.ChangePoints[0].Direction)
+	require.Equal(t, start.Add(48*time.Hour), detection.ChangePoints[1].Time)
+	require.Equal(t, sdktypes.AnomalyDirectionDown, detection.ChangePoints[1].Direction)
+	require.Nil(t, detection.ChangePoints[0].NearestDeployment)
+	require.False(t, detection.ChangePoints[0].Attributed)
+}
+
+func TestDetect_MinRelativeChange(t *testing.T) {
+	series := levelSeries(24, 1000, 1030, 1600)
+
+	all := changepoint.Detect("p99DurationMs", series, nil, normalize(t, sdktypes.ChangePointOptions{}))
+	large := changepoint.Detect("p99DurationMs", series, nil, normalize(t, sdktypes.ChangePointOptions{MinRelativeChange: 0.1}))
+
+	require.Len(t, all.ChangePoints, 2)
+	require.Len(t, large.ChangePoints, 1)
+	require.Equal(t, start.Add(48*time.Hour), large.ChangePoints[0].Time)
+	require.InDelta(t, 1015, large.ChangePoints[0].MeanBefore, 1)
+}
+
+func TestDetect_DeploymentOutsideAttributionWindow(t *testing.T) {
+	deployments := []sdktypes.Deployment{{Time: start.Add(40 * time.Hour), CodeSha256: "early"}}
+
+	detection := changepoint.Detect("p99DurationMs", levelSeries(48, 300, 450), deployments, normalize(t, sdktypes.ChangePointOptions{}))
+
+	require.Len(t, detection.ChangePoints, 1)
+	require.Equal(t, "early", detection.ChangePoints[0].NearestDeployment.CodeSha256)
+	require.Equal(t, 8*time.Hour, detection.ChangePoints[0].DeploymentDistance)
+	require.False(t, detection.ChangePoints[0].Attributed)
+}
+
+func TestNormalize_InvalidOptions(t *testing.T) {
+	_, err := changepoint.Normalize(sdktypes.ChangePointOptions{Penalty: -1})
+	require.Error(t, err)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/forecast"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+var start = time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+const day = 24 * time.Hour
+
+// dailySeries returns the given number of daily points with the values of value per day.
+func dailySeries(days int, value func(day int) float64) []sdktypes.MetricPoint {
+	points := []sdktypes.MetricPoint{}
+	for i := 0; i < days; i++ {
+		points = append(points, sdktypes.MetricPoint{Timestamp: start.Add(time.Duration(i) * day), Value: value(i)})
+	}
+	return points
+}
+
+// weekly returns a value with a weekly pattern, lower on weekends, a trend and a little noise.
+func weekly(i int) float64 {
+	value := 1000 + 5*float64(i) + float64((i*7)%5) - 2
+	if weekday := i % 7; weekday == 5 || weekday == 6 {
+		value -= 400
+	}
+	return value
+}
+
+func normalize(t *testing.T, options sdktypes.ForecastOptions) sdktypes.ForecastOptions {
+	t.Helper()
+	options, err := forecast.Normalize(options)
+	require.NoError(t, err)
+	return options
+}
+
+func TestForecast_LinearTrend(t *testing.T) {
+	series := dailySeries(30, func(i int) float64 { return 100 + 10*float64(i) })
+
+	result, err := forecast.Forecast("invocations", series, normalize(t, sdktypes.ForecastOptions{Horizon: 7 * day}))
+
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.ForecastModelLinear, result.Model)
+	require.InDelta(t, 10, result.Trend, 1e-9)
+	require.InDelta(t, 0, result.ResidualStdDev, 1e-9)
+	require.Len(t, result.Forecast, 7)
+	require.Equal(t, start.Add(30*day), result.Forecast[0].Timestamp)
+	require.InDelta(t, 400, result.Forecast[0].Value, 1e-9)
+	require.InDelta(t, 460, result.Forecast[6].Value, 1e-9)
+	require.InDelta(t, result.Forecast[6].Value, result.Forecast[6].Upper, 1e-9)
+}
+
+func TestForecast_LinearIntervalsWiden(t *testing.T) {
+	series := dailySeries(30, func(i int) float64 { return 100 + 10*float64(i) + float64((i*7)%5) - 2 })
+
+	result, err := forecast.Forecast("invocations", series, normalize(t, sdktypes.ForecastOptions{}))
+
+	require.NoError(t, err)
+	require.Len(t, result.Forecast, 30)
+	require.Greater(t, result.ResidualStdDev, 0.0)
+	first, last := result.Forecast[0], result.Forecast[29]
+	require.Greater(t, last.Upper-last.Lower, first.Upper-first.Lower)
+	require.Less(t, first.Lower, 400.0)
+	require.Greater(t, first.Upper, 400.0)
+}
+
+func TestForecast_HoltWintersWeeklySeasonality(t *testing.T) {
+	series := dailySeries(8*7, weekly)
+
+	result, err := forecast.Forecast("invocations", series, normalize(t, sdktypes.ForecastOptions{
+		Model:   sdktypes.ForecastModelHoltWinters,
+		Horizon: 14 * day,
+	}))
+
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.ForecastModelHoltWinters, result.Model)
+	require.InDelta(t, 5, result.Trend, 1)
+	for h, point := range result.Forecast {
+		expected := weekly(8*7 + h)
+		require.InDelta(t, expected, point.Value, 15, "day %d", h)
+		require.Less(t, point.Lower, expected)
+		require.Greater(t, point.Upper, expected)
+	}
+	linear, err := forecast.Forecast("invocations", series, normalize(t, sdktypes.ForecastOptions{Horizon: 14 * day}))
+	require.NoError(t, err)
+	require.Greater(t, linear.ResidualStdDev, 5*result.ResidualStdDev)
+}
+
+func TestForecast_HoltWintersFallsBackToLinear(t *testing.T) {
+	series := dailySeries(10, weekly)
+
+	result, err := forecast.Forecast("invocations", series, normalize(t, sdktypes.ForecastOptions{Model: sdktypes.ForecastModelHoltWinters}))
+
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.ForecastModelLinear, result.Model)
+}
+
+func TestForecast_NonNegative(t *testing.T) {
+	series := dailySeries(10, func(i int) float64 { return 100 - 10*float64(i) })
+
+	result, err := forecast.Forecast("peakConcurrency", series, normalize(t, sdktypes.ForecastOptions{Horizon: 5 * day}))
+
+	require.NoError(t, err)
+	for _, point := range result.Forecast {
+		require.Equal(t, 0.0, point.Value)
+		require.Equal(t, 0.0, point.Lower)
+	}
+}
+
+func TestForecast_InvalidInput(t *testing.T) {
+	_, err := forecast.Forecast("invocations", dailySeries(1, weekly), normalize(t, sdktypes.ForecastOptions{}))
+	require.Error(t, err)
+
+	_, err = forecast.Forecast("invocations", dailySeries(30, weekly), normalize(t, sdktypes.ForecastOptions{
+		Model:        sdktypes.ForecastModelHoltWinters,
+		SeasonLength: 36 * time.Hour,
+	}))
+	require.Error(t, err)
+}
+
+func TestNormalize_InvalidOptions(t *testing.T) {
+	for _, options := range []sdktypes.ForecastOptions{
+		{Model: "arima"},
+		{Horizon: -day},
+		{Confidence: 1},
+		{SmoothingTrend: 1.5},
+	} {
+		_, err := forecast.Normalize(options)
+		require.Error(t, err)
+	}
+}
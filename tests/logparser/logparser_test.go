@@ -0,0 +1,237 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/logparser"
+)
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       string
+		wantDuration  float64
+		wantBilled    float64
+		wantMemory    float64
+		wantMaxMemory float64
+		wantInit      *float64
+		wantRestore   *float64
+		wantStatus    string
+		wantErrorType string
+		wantErr       bool
+	}{
+		{
+			name:          "warm start",
+			message:       "REPORT RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3\tDuration: 12.34 ms\tBilled Duration: 13 ms\tMemory Size: 128 MB\tMax Memory Used: 18 MB\t",
+			wantDuration:  12.34,
+			wantBilled:    13,
+			wantMemory:    128,
+			wantMaxMemory: 18,
+		},
+		{
+			name:          "cold start",
+			message:       "REPORT RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3\tDuration: 100.00 ms\tBilled Duration: 100 ms\tMemory Size: 256 MB\tMax Memory Used: 64 MB\tInit Duration: 250.50 ms\t",
+			wantDuration:  100,
+			wantBilled:    100,
+			wantMemory:    256,
+			wantMaxMemory: 64,
+			wantInit:      ptr(250.5),
+		},
+		{
+			name:          "snap start restore",
+			message:       "REPORT RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3\tDuration: 20.00 ms\tBilled Duration: 166 ms\tMemory Size: 512 MB\tMax Memory Used: 100 MB\tRestore Duration: 300.00 ms\tBilled Restore Duration: 145 ms\t",
+			wantDuration:  20,
+			wantBilled:    166,
+			wantMemory:    512,
+			wantMaxMemory: 100,
+			wantRestore:   ptr(300),
+		},
+		{
+			name:          "out of memory",
+			message:       "REPORT RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3\tDuration: 500.00 ms\tBilled Duration: 500 ms\tMemory Size: 128 MB\tMax Memory Used: 128 MB\tStatus: error\tError Type: Runtime.OutOfMemory\t",
+			wantDuration:  500,
+			wantBilled:    500,
+			wantMemory:    128,
+			wantMaxMemory: 128,
+			wantStatus:    "error",
+			wantErrorType: "Runtime.OutOfMemory",
+		},
+		{
+			name:          "timeout",
+			message:       "REPORT RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3\tDuration: 3000.00 ms\tBilled Duration: 3000 ms\tMemory Size: 128 MB\tMax Memory Used: 60 MB\tStatus: timeout",
+			wantDuration:  3000,
+			wantBilled:    3000,
+			wantMemory:    128,
+			wantMaxMemory: 60,
+			wantStatus:    "timeout",
+		},
+		{
+			name:          "json log format",
+			message:       `{"time":"2025-01-01T12:00:00.000Z","type":"platform.report","record":{"requestId":"3604209a-e9a3-11e6-939a-754dd98c7be3","metrics":{"durationMs":20.5,"billedDurationMs":21,"memorySizeMB":512,"maxMemoryUsedMB":100,"restoreDurationMs":300},"status":"success"}}`,
+			wantDuration:  20.5,
+			wantBilled:    21,
+			wantMemory:    512,
+			wantMaxMemory: 100,
+			wantRestore:   ptr(300),
+		},
+		{
+			name:          "json log format error",
+			message:       `{"time":"2025-01-01T12:00:00.000Z","type":"platform.report","record":{"requestId":"3604209a-e9a3-11e6-939a-754dd98c7be3","metrics":{"durationMs":5,"billedDurationMs":5,"memorySizeMB":128,"maxMemoryUsedMB":60,"initDurationMs":150},"status":"error","errorType":"Runtime.ExitError"}}`,
+			wantDuration:  5,
+			wantBilled:    5,
+			wantMemory:    128,
+			wantMaxMemory: 60,
+			wantInit:      ptr(150),
+			wantStatus:    "error",
+			wantErrorType: "Runtime.ExitError",
+		},
+		{
+			name:    "json record of other type",
+			message: `{"time":"2025-01-01T12:00:00.000Z","type":"platform.start","record":{"requestId":"3604209a-e9a3-11e6-939a-754dd98c7be3"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "no report line",
+			message: "START RequestId: 3604209a-e9a3-11e6-939a-754dd98c7be3 Version: $LATEST",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := logparser.ParseReport(map[string]string{
+				"@timestamp": "2025-01-01 12:00:00.000",
+				"@logStream": "2025/01/01/[$LATEST]abc",
+				"@message":   tt.message,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "3604209a-e9a3-11e6-939a-754dd98c7be3", report.RequestID)
+			require.Equal(t, "2025/01/01/[$LATEST]abc", report.LogStream)
+			require.Equal(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), report.Timestamp)
+			require.Equal(t, tt.wantDuration, report.DurationMs)
+			require.Equal(t, tt.wantBilled, report.BilledDurationMs)
+			require.Equal(t, tt.wantMemory, report.MemorySizeMB)
+			require.Equal(t, tt.wantMaxMemory, report.MaxMemoryUsedMB)
+			require.Equal(t, tt.wantInit, report.InitDurationMs)
+			require.Equal(t, tt.wantRestore, report.RestoreDurationMs)
+			require.Equal(t, tt.wantStatus, report.Status)
+			require.Equal(t, tt.wantErrorType, report.ErrorType)
+			require.Equal(t, tt.wantInit != nil || tt.wantRestore != nil, report.IsColdStart())
+		})
+	}
+}
+
+func TestParseReports_SortsAndSkipsInvalid(t *testing.T) {
+	rows := []map[string]string{
+		{"@timestamp": "2025-01-01 12:00:02.000", "@logStream": "b", "@message": "REPORT RequestId: 2\tDuration: 1.00 ms\t"},
+		{"@timestamp": "2025-01-01 12:00:01.000", "@logStream": "a", "@message": "REPORT RequestId: 1\tDuration: 1.00 ms\t"},
+		{"@timestamp": "invalid", "@logStream": "a", "@message": "REPORT RequestId: 3\tDuration: 1.00 ms\t"},
+		{"@timestamp": "2025-01-01 12:00:03.000", "@logStream": "a", "@message": "REPORT RequestId: 4\tDuration: 1.00 ms\t"},
+	}
+
+	reports := logparser.ParseReports(rows)
+	require.Len(t, reports, 3)
+	require.Equal(t, "1", reports[0].RequestID)
+	require.Equal(t, "2", reports[1].RequestID)
+	require.Equal(t, "4", reports[2].RequestID)
+
+	groups := logparser.GroupByLogStream(reports)
+	require.Len(t, groups, 2)
+	require.Len(t, groups["a"], 2)
+	require.Equal(t, "1", groups["a"][0].RequestID)
+	require.Equal(t, "4", groups["a"][1].RequestID)
+}
+
+func TestParseInitEvent(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		wantOk       bool
+		wantInitType string
+		wantDuration *float64
+	}{
+		{
+			name:    "text init start",
+			message: "INIT_START Runtime Version: python:3.12.v30\tRuntime Version ARN: arn:aws:lambda:eu-central-1::runtime:abc",
+			wantOk:  true,
+		},
+		{
+			name:         "text init report",
+			message:      "INIT_REPORT Init Duration: 999.50 ms\tPhase: init\tStatus: error\tError Type: Runtime.ExitError",
+			wantOk:       true,
+			wantDuration: ptr(999.5),
+		},
+		{
+			name:         "text restore report",
+			message:      "RESTORE_REPORT Restore Duration: 300.00 ms",
+			wantOk:       true,
+			wantInitType: "snap-start",
+			wantDuration: ptr(300),
+		},
+		{
+			name:         "json init start",
+			message:      `{"time":"2025-01-01T12:00:00.000Z","type":"platform.initStart","record":{"initializationType":"provisioned-concurrency","phase":"init"}}`,
+			wantOk:       true,
+			wantInitType: "provisioned-concurrency",
+		},
+		{
+			name:         "json init report",
+			message:      `{"time":"2025-01-01T12:00:00.000Z","type":"platform.initReport","record":{"initializationType":"on-demand","phase":"init","metrics":{"durationMs":120.5}}}`,
+			wantOk:       true,
+			wantInitType: "on-demand",
+			wantDuration: ptr(120.5),
+		},
+		{
+			name:    "report line",
+			message: "REPORT RequestId: 1\tDuration: 1.00 ms\t",
+		},
+		{
+			name:    "json report",
+			message: `{"time":"2025-01-01T12:00:00.000Z","type":"platform.report","record":{"requestId":"1"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, ok, err := logparser.ParseInitEvent(map[string]string{
+				"@timestamp": "2025-01-01 12:00:00.000",
+				"@logStream": "a",
+				"@message":   tt.message,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantOk, ok)
+			if !ok {
+				return
+			}
+			require.Equal(t, "a", event.LogStream)
+			require.Equal(t, time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), event.Timestamp)
+			require.Equal(t, tt.wantInitType, event.InitType)
+			require.Equal(t, tt.wantDuration, event.DurationMs)
+		})
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
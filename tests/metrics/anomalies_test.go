@@ -0,0 +1,87 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetAnomalies_ErrorRateSpike(t *testing.T) {
+	start := time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+	hours := 5 * 7 * 24
+	spike := start.Add(time.Duration(hours-5) * time.Hour)
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			result := types.MetricDataResult{}
+			for hour := 0; hour < hours; hour++ {
+				ts := start.Add(time.Duration(hour) * time.Hour)
+				value := 1000.0
+				switch {
+				case metricName == "Errors" && ts.Equal(spike):
+					value = 200
+				case metricName == "Errors":
+					value = float64(10 + hour%3)
+				case metricName == "Duration":
+					value = float64(300 + hour%5)
+				}
+				// No invocations in the first hour, which is left out of the error rate.
+				if hour == 0 && metricName == "Invocations" {
+					value = 0
+				}
+				result.Timestamps = append(result.Timestamps, ts)
+				result.Values = append(result.Values, value)
+			}
+			return []types.MetricDataResult{result}, nil
+		},
+	}
+	logs := &mockLogsFetcher{results: []map[string]string{
+		{"bucket": "2025-06-08 10:00:00.000", "coldStarts": "3"},
+	}}
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: start, EndTime: start.Add(time.Duration(hours) * time.Hour)}
+
+	report, err := metrics.GetAnomalies(context.Background(), logs, cw, query, sdktypes.AnomalyOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, report.Series["errorRate"], hours-1)
+	require.Len(t, report.Series["p99DurationMs"], hours)
+	require.Len(t, report.Series["coldStarts"], 1)
+	require.Len(t, report.Detections, 3)
+	require.Len(t, report.Anomalies, 1)
+	require.Equal(t, "errorRate", report.Anomalies[0].Metric)
+	require.Equal(t, spike, report.Anomalies[0].Start)
+	require.Equal(t, spike.Add(time.Hour), report.Anomalies[0].End)
+	require.InDelta(t, 0.2, report.Anomalies[0].Value, 1e-9)
+	require.Equal(t, sdktypes.AnomalySeverityHigh, report.Anomalies[0].Severity)
+	// A single cold start bucket has no history to be compared with.
+	require.Len(t, report.Warnings, 1)
+	require.Contains(t, report.Warnings[0], "coldStarts")
+}
+
+func TestGetAnomalies_InvalidBucketSize(t *testing.T) {
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST"}
+
+	_, err := metrics.GetAnomalies(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, query,
+		sdktypes.AnomalyOptions{BucketSize: 90 * time.Second})
+
+	require.Error(t, err)
+}
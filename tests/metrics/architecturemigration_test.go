@@ -0,0 +1,208 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// architectureLambdaClient returns a mock whose functions have 1024 MB of memory and the given architectures.
+func architectureLambdaClient(architectures map[string]lambdatypes.Architecture) *mockLambdaClient {
+	return &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{
+					MemorySize:    aws.Int32(1024),
+					Architectures: []lambdatypes.Architecture{architectures[*params.FunctionName]},
+				},
+			}, nil
+		},
+	}
+}
+
+// durationLogs returns count REPORT rows with the given duration per function, keyed by name:qualifier.
+func durationLogs(count int, durations map[string]float64) *mockLogsFetcher {
+	return &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			duration, ok := durations[fq.FunctionName+":"+fq.Qualifier]
+			if !ok {
+				return []map[string]string{}, nil
+			}
+			rows := []map[string]string{}
+			for i := 0; i < count; i++ {
+				rows = append(rows, reportRow(fq.StartTime.Add(time.Duration(i)*time.Minute), "stream-a", fmt.Sprintf("req-%d", i), duration, 1024, 256, ""))
+			}
+			return rows, nil
+		},
+	}
+}
+
+// invocationsFetcher returns a mock with the given invocations per function, keyed by name:qualifier.
+func invocationsFetcher(invocations map[string]float64) *mockCWFetcher {
+	return &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			return []types.MetricDataResult{{Values: []float64{invocations[query.FunctionName+":"+query.Qualifier]}}}, nil
+		},
+	}
+}
+
+func TestGetArchitectureMigrationEstimate_ConfiguredRatio(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+	lambdaClient := architectureLambdaClient(map[string]lambdatypes.Architecture{"my-fn": lambdatypes.ArchitectureX8664})
+
+	result, err := metrics.GetArchitectureMigrationEstimate(context.Background(), durationLogs(10, map[string]float64{"my-fn:$LATEST": 1000}),
+		invocationsFetcher(map[string]float64{"my-fn:$LATEST": 10}), lambdaClient, &mockFunctionVersionsClient{}, cache.NewCache(), query, 0.8)
+	require.NoError(t, err)
+	require.Equal(t, "x86_64", result.Architecture)
+	require.Equal(t, "arm64", result.TargetArchitecture)
+	require.Equal(t, 0.8, result.PerformanceRatio)
+	require.Empty(t, result.MeasuredVersion)
+	// Every invocation is billed 1001 ms, which is 801 ms on arm64 after rounding up.
+	require.Equal(t, 10010.0, result.BilledDurationMs)
+	require.InDelta(t, 10.01*0.0000166667+10*0.2/1e6, result.CurrentCost, 1e-12)
+	require.InDelta(t, 8.01*0.0000133334+10*0.2/1e6, result.ProjectedCost, 1e-12)
+	require.InDelta(t, result.CurrentCost-result.ProjectedCost, result.Savings, 1e-12)
+	require.Greater(t, result.SavingsRate, 0.3)
+}
+
+func TestGetArchitectureMigrationEstimate_MeasuredRatio(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+	lambdaClient := architectureLambdaClient(map[string]lambdatypes.Architecture{"my-fn": lambdatypes.ArchitectureX8664})
+	versionsClient := &mockFunctionVersionsClient{
+		versions: []lambdatypes.FunctionConfiguration{
+			{Version: aws.String("$LATEST"), Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureX8664}},
+			{Version: aws.String("2"), Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureArm64}},
+			{Version: aws.String("3"), Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureArm64}},
+		},
+	}
+	// Version 3 has too few invocations, so version 2 is measured.
+	cw := invocationsFetcher(map[string]float64{"my-fn:$LATEST": 40, "my-fn:2": 40, "my-fn:3": 5})
+	logs := durationLogs(40, map[string]float64{"my-fn:$LATEST": 1000, "my-fn:2": 750})
+
+	result, err := metrics.GetArchitectureMigrationEstimate(context.Background(), logs, cw, lambdaClient, versionsClient, cache.NewCache(), query, 0)
+	require.NoError(t, err)
+	require.Equal(t, "2", result.MeasuredVersion)
+	require.InDelta(t, 0.75, result.PerformanceRatio, 1e-9)
+	// 1001 ms billed on x86_64 are 751 ms on arm64 after rounding up.
+	require.InDelta(t, 40*0.751*0.0000133334+40*0.2/1e6, result.ProjectedCost, 1e-12)
+}
+
+func TestGetArchitectureMigrationEstimate_ScalesSampledReports(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+	lambdaClient := architectureLambdaClient(map[string]lambdatypes.Architecture{"my-fn": lambdatypes.ArchitectureX8664})
+
+	// The logs only hold 10 of the 1000 invocations.
+	result, err := metrics.GetArchitectureMigrationEstimate(context.Background(), durationLogs(10, map[string]float64{"my-fn:$LATEST": 1000}),
+		invocationsFetcher(map[string]float64{"my-fn:$LATEST": 1000}), lambdaClient, &mockFunctionVersionsClient{}, cache.NewCache(), query, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1000, result.Invocations)
+	require.Equal(t, 10, result.SampledInvocations)
+	require.InDelta(t, 1001000.0, result.BilledDurationMs, 1e-6)
+	require.InDelta(t, 1001*0.0000166667+1000*0.2/1e6, result.CurrentCost, 1e-9)
+}
+
+func TestGetArchitectureMigrationEstimate_NegativeRatio(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetArchitectureMigrationEstimate(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, &mockLambdaClient{}, &mockFunctionVersionsClient{}, cache.NewCache(), query, -1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "must not be negative")
+}
+
+func TestGetArchitectureMigrationRanking(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lambdaClient := architectureLambdaClient(map[string]lambdatypes.Architecture{
+		"small": lambdatypes.ArchitectureX8664,
+		"large": lambdatypes.ArchitectureX8664,
+		"idle":  lambdatypes.ArchitectureX8664,
+	})
+	listClient := &mockFunctionListClient{
+		functions: []lambdatypes.FunctionConfiguration{
+			{FunctionName: aws.String("small"), Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureX8664}},
+			{FunctionName: aws.String("graviton"), Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureArm64}},
+			{FunctionName: aws.String("large")},
+			{FunctionName: aws.String("idle"), Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureX8664}},
+		},
+	}
+	cw := invocationsFetcher(map[string]float64{"small:$LATEST": 10, "large:$LATEST": 10})
+	logs := durationLogs(10, map[string]float64{"small:$LATEST": 100, "large:$LATEST": 5000})
+
+	result, err := metrics.GetArchitectureMigrationRanking(context.Background(), logs, cw, lambdaClient, &mockFunctionVersionsClient{}, listClient,
+		cache.NewCache(), nil, start, start.Add(time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 2)
+	require.Equal(t, "large", result.Candidates[0].FunctionName)
+	require.Equal(t, "small", result.Candidates[1].FunctionName)
+	require.Greater(t, result.Candidates[0].Savings, result.Candidates[1].Savings)
+	require.Empty(t, result.Warnings)
+}
+
+func TestGetArchitectureMigrationRanking_FailingFunction(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			if *params.FunctionName == "broken" {
+				return nil, fmt.Errorf("access denied")
+			}
+			return &lambda.GetFunctionOutput{Configuration: &lambdatypes.FunctionConfiguration{MemorySize: aws.Int32(1024)}}, nil
+		},
+	}
+	cw := invocationsFetcher(map[string]float64{"ok:$LATEST": 10, "broken:$LATEST": 10})
+	logs := durationLogs(10, map[string]float64{"ok:$LATEST": 100, "broken:$LATEST": 100})
+
+	result, err := metrics.GetArchitectureMigrationRanking(context.Background(), logs, cw, lambdaClient, &mockFunctionVersionsClient{}, &mockFunctionListClient{},
+		cache.NewCache(), []string{"broken", "ok"}, start, start.Add(time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 1)
+	require.Equal(t, "ok", result.Candidates[0].FunctionName)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "estimate broken")
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func asyncFetcher(received float64) *mockCWFetcher {
+	return &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			values := map[string]float64{
+				"AsyncEventsReceived":         received,
+				"AsyncEventsDropped":          5,
+				"DeadLetterErrors":            1,
+				"DestinationDeliveryFailures": 1,
+			}
+			if metricName == "AsyncEventAge" {
+				ages := map[string]float64{"p50": 1000, "p90": 5000, "p99": 50000, "Maximum": 55000}
+				return []types.MetricDataResult{{Values: []float64{ages[stat]}}}, nil
+			}
+			return []types.MetricDataResult{{Values: []float64{values[metricName]}}}, nil
+		},
+	}
+}
+
+func TestGetAsyncInvocationStatistics_HappyPath(t *testing.T) {
+	invokeConfigClient := &mockEventInvokeConfigClient{
+		output: &lambda.GetFunctionEventInvokeConfigOutput{
+			MaximumRetryAttempts:     aws.Int32(1),
+			MaximumEventAgeInSeconds: aws.Int32(60),
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetAsyncInvocationStatistics(context.Background(), asyncFetcher(100), memorySizeLambdaClient(128), invokeConfigClient, query)
+	require.NoError(t, err)
+	require.Equal(t, 100.0, result.EventsReceived)
+	require.InDelta(t, 0.05, result.DropRate, 1e-9)
+	require.InDelta(t, 0.02, result.DeliveryFailureRate, 1e-9)
+	require.Equal(t, 1000.0, *result.P50EventAge)
+	require.Equal(t, 55000.0, *result.MaxEventAge)
+	require.Equal(t, int32(1), result.MaximumRetryAttempts)
+	require.Equal(t, int32(60), result.MaximumEventAgeSeconds)
+
+	// p99 and max are above 80% of 60 s, events are dropped without a destination and deliveries failed.
+	require.Len(t, result.Warnings, 4)
+	require.Contains(t, result.Warnings[0], "p99 event age")
+	require.Contains(t, result.Warnings[2], "without an on-failure destination")
+}
+
+func TestGetAsyncInvocationStatistics_DefaultConfig(t *testing.T) {
+	invokeConfigClient := &mockEventInvokeConfigClient{
+		err: &lambdatypes.ResourceNotFoundException{Message: aws.String("not found")},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetAsyncInvocationStatistics(context.Background(), asyncFetcher(100), memorySizeLambdaClient(128), invokeConfigClient, query)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), result.MaximumRetryAttempts)
+	require.Equal(t, int32(21600), result.MaximumEventAgeSeconds)
+	require.Len(t, result.Warnings, 2)
+}
+
+func TestGetAsyncInvocationStatistics_NoEvents(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetAsyncInvocationStatistics(context.Background(), asyncFetcher(0), memorySizeLambdaClient(128), &mockEventInvokeConfigClient{}, query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// auditLambdaClient returns a mock of a function with 2048 MB of memory, the maximum timeout and the given runtime.
+func auditLambdaClient(runtime lambdatypes.Runtime) *mockLambdaClient {
+	return &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{
+					FunctionName:  aws.String("my-fn"),
+					Runtime:       runtime,
+					MemorySize:    aws.Int32(2048),
+					Timeout:       aws.Int32(900),
+					TracingConfig: &lambdatypes.TracingConfigResponse{Mode: lambdatypes.TracingModeActive},
+				},
+			}, nil
+		},
+	}
+}
+
+func TestGetAuditReport(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:      time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			values := map[string]float64{"Invocations": 3, "AsyncEventsReceived": 3}
+			return []types.MetricDataResult{{Values: []float64{values[metricName]}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{results: []map[string]string{
+		{"memoryUtilizationRatio": "0.05"},
+		{"memoryUtilizationRatio": "0.08"},
+		{"memoryUtilizationRatio": "0.1"},
+	}}
+
+	report, err := metrics.GetAuditReport(context.Background(), logs, cw, auditLambdaClient(lambdatypes.RuntimePython39),
+		&mockProvisionedConcurrencyClient{}, &mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, cache.NewCache(),
+		query, sdktypes.AuditOptions{Critical: true}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	ruleIDs := []string{}
+	for _, finding := range report.Findings {
+		ruleIDs = append(ruleIDs, finding.RuleID)
+	}
+	require.Equal(t, []string{
+		"async-without-failure-destination",
+		"runtime-deprecation-soon",
+		"memory-over-provisioned",
+		"critical-without-reserved-concurrency",
+		"timeout-at-maximum",
+	}, ruleIDs)
+	require.Equal(t, sdktypes.AuditSeverityHigh, report.HighestSeverity)
+	require.ElementsMatch(t, []string{"runtime-deprecated", "tracing-disabled"}, report.PassedRules)
+	require.Contains(t, report.Findings[2].Message, "10% of 2048 MB")
+}
+
+func TestGetAuditReport_CustomRulesWithoutInvocations(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:      time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	var subject sdktypes.AuditSubject
+	rules := []sdktypes.AuditRule{{
+		ID:       "capture",
+		Severity: sdktypes.AuditSeverityLow,
+		Check: func(s sdktypes.AuditSubject) []string {
+			subject = s
+			return nil
+		},
+	}}
+
+	report, err := metrics.GetAuditReport(context.Background(), &mockLogsFetcher{}, cw, auditLambdaClient(lambdatypes.RuntimeGo1x),
+		&mockProvisionedConcurrencyClient{}, &mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, cache.NewCache(),
+		query, sdktypes.AuditOptions{Rules: rules}, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Empty(t, report.Findings)
+	require.Equal(t, []string{"capture"}, report.PassedRules)
+	require.Nil(t, subject.MemoryUsage)
+	require.Equal(t, "go1.x", subject.Configuration.Runtime)
+}
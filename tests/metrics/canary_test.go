@@ -0,0 +1,297 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
+	codedeploytypes "github.com/aws/aws-sdk-go-v2/service/codedeploy/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// canaryVersion describes the invocations of one version in the mocks.
+type canaryVersion struct {
+	invocations int
+	errors      float64
+	timeouts    int
+	durationMs  float64
+}
+
+// canaryFetchers returns mocks that serve the invocations of the versions, keyed by qualifier.
+func canaryFetchers(versions map[string]canaryVersion) (*mockLogsFetcher, *mockCWFetcher) {
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			version := versions[fq.Qualifier]
+			rows := []map[string]string{}
+			for i := 0; i < version.invocations; i++ {
+				extra := ""
+				if i < version.timeouts {
+					extra = "Status: timeout"
+				}
+				rows = append(rows, reportRow(fq.StartTime.Add(time.Duration(i)*time.Second), "stream-a",
+					fmt.Sprintf("req-%s-%d", fq.Qualifier, i), version.durationMs, 512, 128, extra))
+			}
+			return rows, nil
+		},
+	}
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			version := versions[query.Qualifier]
+			value := float64(version.invocations)
+			if metricName == "Errors" {
+				value = version.errors
+			}
+			return []types.MetricDataResult{{Values: []float64{value}}}, nil
+		},
+	}
+	return logs, cw
+}
+
+func canaryQuery() sdktypes.FunctionQuery {
+	end := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	return sdktypes.FunctionQuery{FunctionName: "my-fn", StartTime: end.Add(-30 * time.Minute), EndTime: end}
+}
+
+func checkStatus(t *testing.T, analysis *sdktypes.CanaryAnalysisReturn, metric string) sdktypes.CanaryCheckStatus {
+	t.Helper()
+	for _, check := range analysis.Checks {
+		if check.Metric == metric {
+			return check.Status
+		}
+	}
+	t.Fatalf("no check for %s", metric)
+	return ""
+}
+
+func TestGetCanaryAnalysis_Proceed(t *testing.T) {
+	logs, cw := canaryFetchers(map[string]canaryVersion{
+		"1": {invocations: 200, errors: 2, durationMs: 100},
+		"2": {invocations: 200, errors: 3, durationMs: 110},
+	})
+
+	analysis, err := metrics.GetCanaryAnalysis(context.Background(), logs, cw, &mockAliasClient{}, canaryQuery(),
+		"", "1", "2", metrics.DefaultCanaryThresholds())
+
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.CanaryVerdictProceed, analysis.Verdict)
+	require.Len(t, analysis.Checks, 3)
+	require.InDelta(t, 0.005, analysis.Checks[0].Difference, 1e-9)
+	require.InDelta(t, 0.1, analysis.Checks[1].Difference, 1e-9)
+	require.Equal(t, 200, analysis.Canary.Invocations)
+	require.Nil(t, analysis.CanaryWeight)
+}
+
+func TestGetCanaryAnalysis_Rollback(t *testing.T) {
+	tests := []struct {
+		name   string
+		canary canaryVersion
+		metric string
+	}{
+		{"error rate", canaryVersion{invocations: 200, errors: 10, durationMs: 100}, "errorRate"},
+		{"p99 duration", canaryVersion{invocations: 200, durationMs: 150}, "p99DurationMs"},
+		{"timeout rate", canaryVersion{invocations: 200, timeouts: 4, durationMs: 100}, "timeoutRate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, cw := canaryFetchers(map[string]canaryVersion{
+				"1": {invocations: 200, durationMs: 100},
+				"2": tt.canary,
+			})
+
+			analysis, err := metrics.GetCanaryAnalysis(context.Background(), logs, cw, &mockAliasClient{}, canaryQuery(),
+				"", "1", "2", metrics.DefaultCanaryThresholds())
+
+			require.NoError(t, err)
+			require.Equal(t, sdktypes.CanaryVerdictRollback, analysis.Verdict)
+			require.Equal(t, sdktypes.CanaryCheckFailed, checkStatus(t, analysis, tt.metric))
+		})
+	}
+}
+
+func TestGetCanaryAnalysis_InconclusiveWithFewInvocations(t *testing.T) {
+	logs, cw := canaryFetchers(map[string]canaryVersion{
+		"1": {invocations: 200, durationMs: 100},
+		"2": {invocations: 20, durationMs: 100},
+	})
+
+	analysis, err := metrics.GetCanaryAnalysis(context.Background(), logs, cw, &mockAliasClient{}, canaryQuery(),
+		"", "1", "2", metrics.DefaultCanaryThresholds())
+
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.CanaryVerdictInconclusive, analysis.Verdict)
+	for _, check := range analysis.Checks {
+		require.Equal(t, sdktypes.CanaryCheckInconclusive, check.Status, check.Metric)
+	}
+	require.Nil(t, analysis.Canary.P99DurationMs)
+}
+
+func TestGetCanaryAnalysis_SampleSizePerSource(t *testing.T) {
+	// Only few REPORT lines are in the logs, e.g. due to a short log retention.
+	logs, _ := canaryFetchers(map[string]canaryVersion{
+		"1": {invocations: 20, durationMs: 100},
+		"2": {invocations: 20, durationMs: 100},
+	})
+	_, cw := canaryFetchers(map[string]canaryVersion{
+		"1": {invocations: 1000, errors: 5},
+		"2": {invocations: 1000, errors: 6},
+	})
+
+	analysis, err := metrics.GetCanaryAnalysis(context.Background(), logs, cw, &mockAliasClient{}, canaryQuery(),
+		"live", "1", "2", metrics.DefaultCanaryThresholds())
+
+	require.NoError(t, err)
+	require.Equal(t, 1000, analysis.Canary.Invocations)
+	require.Equal(t, 20, analysis.Canary.ReportedInvocations)
+	require.Equal(t, sdktypes.CanaryCheckPassed, checkStatus(t, analysis, "errorRate"))
+	require.Equal(t, sdktypes.CanaryCheckInconclusive, checkStatus(t, analysis, "timeoutRate"))
+	require.Contains(t, analysis.Checks[0].Source, "alias live")
+	require.Contains(t, analysis.Checks[2].Source, "REPORT lines")
+	require.Equal(t, sdktypes.CanaryVerdictInconclusive, analysis.Verdict)
+}
+
+func TestGetCanaryAnalysis_VersionsFromAlias(t *testing.T) {
+	logs, cw := canaryFetchers(map[string]canaryVersion{
+		"3": {invocations: 200, durationMs: 100},
+		"4": {invocations: 200, durationMs: 100},
+	})
+	aliasClient := &mockAliasClient{output: &lambda.GetAliasOutput{
+		FunctionVersion: aws.String("3"),
+		RoutingConfig:   &lambdatypes.AliasRoutingConfiguration{AdditionalVersionWeights: map[string]float64{"4": 0.1}},
+	}}
+
+	analysis, err := metrics.GetCanaryAnalysis(context.Background(), logs, cw, aliasClient, canaryQuery(),
+		"live", "", "", metrics.DefaultCanaryThresholds())
+
+	require.NoError(t, err)
+	require.Equal(t, "3", analysis.Baseline.Version)
+	require.Equal(t, "4", analysis.Canary.Version)
+	require.NotNil(t, analysis.CanaryWeight)
+	require.Equal(t, 0.1, *analysis.CanaryWeight)
+	require.Equal(t, sdktypes.CanaryVerdictProceed, analysis.Verdict)
+}
+
+func TestGetCanaryAnalysis_AliasWithoutRouting(t *testing.T) {
+	aliasClient := &mockAliasClient{output: &lambda.GetAliasOutput{FunctionVersion: aws.String("3")}}
+
+	_, err := metrics.GetCanaryAnalysis(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, aliasClient, canaryQuery(),
+		"live", "", "", metrics.DefaultCanaryThresholds())
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "exactly one additional version")
+}
+
+func TestHandleCanaryHook(t *testing.T) {
+	tests := []struct {
+		name                  string
+		canary                canaryVersion
+		proceedOnInconclusive bool
+		want                  codedeploytypes.LifecycleEventStatus
+	}{
+		{"proceed", canaryVersion{invocations: 200, durationMs: 100}, false, codedeploytypes.LifecycleEventStatusSucceeded},
+		{"rollback", canaryVersion{invocations: 200, errors: 20, durationMs: 100}, true, codedeploytypes.LifecycleEventStatusFailed},
+		{"inconclusive", canaryVersion{invocations: 5, durationMs: 100}, false, codedeploytypes.LifecycleEventStatusFailed},
+		{"inconclusive proceeds", canaryVersion{invocations: 5, durationMs: 100}, true, codedeploytypes.LifecycleEventStatusSucceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, cw := canaryFetchers(map[string]canaryVersion{
+				"1": {invocations: 200, durationMs: 100},
+				"2": tt.canary,
+			})
+			codeDeploy := &mockCodeDeployClient{}
+			config := sdktypes.CanaryHookConfig{
+				FunctionName:          "my-fn",
+				BaselineVersion:       "1",
+				CanaryVersion:         "2",
+				Window:                10 * time.Minute,
+				Thresholds:            metrics.DefaultCanaryThresholds(),
+				ProceedOnInconclusive: tt.proceedOnInconclusive,
+			}
+			event := sdktypes.CodeDeployHookEvent{DeploymentID: "d-123", LifecycleEventHookExecutionID: "hook-1"}
+
+			_, err := metrics.HandleCanaryHook(context.Background(), logs, cw, &mockAliasClient{}, codeDeploy, config, event, time.Now())
+
+			require.NoError(t, err)
+			require.Len(t, codeDeploy.inputs, 1)
+			require.Equal(t, tt.want, codeDeploy.inputs[0].Status)
+			require.Equal(t, "d-123", aws.ToString(codeDeploy.inputs[0].DeploymentId))
+			require.Equal(t, "hook-1", aws.ToString(codeDeploy.inputs[0].LifecycleEventHookExecutionId))
+		})
+	}
+}
+
+func TestHandleCanaryHook_VersionsFromDeployment(t *testing.T) {
+	logs, cw := canaryFetchers(map[string]canaryVersion{
+		"3": {invocations: 200, durationMs: 100},
+		"4": {invocations: 200, durationMs: 100},
+	})
+	// While the hooks run, the alias routes all traffic to one version.
+	aliasClient := &mockAliasClient{output: &lambda.GetAliasOutput{FunctionVersion: aws.String("4")}}
+	codeDeploy := &mockCodeDeployClient{deployment: &codedeploy.GetDeploymentOutput{
+		DeploymentInfo: &codedeploytypes.DeploymentInfo{
+			Revision: &codedeploytypes.RevisionLocation{
+				RevisionType: codedeploytypes.RevisionLocationTypeAppSpecContent,
+				AppSpecContent: &codedeploytypes.AppSpecContent{Content: aws.String(`version: 0.0
+Resources:
+  - myFunction:
+      Type: AWS::Lambda::Function
+      Properties:
+        Name: my-fn
+        Alias: live
+        CurrentVersion: 3
+        TargetVersion: 4
+Hooks:
+  - AfterAllowTraffic: canary-hook
+`)},
+			},
+		},
+	}}
+	config := sdktypes.CanaryHookConfig{FunctionName: "my-fn", Window: 10 * time.Minute, Thresholds: metrics.DefaultCanaryThresholds()}
+
+	analysis, err := metrics.HandleCanaryHook(context.Background(), logs, cw, aliasClient, codeDeploy, config,
+		sdktypes.CodeDeployHookEvent{DeploymentID: "d-123", LifecycleEventHookExecutionID: "hook-1"}, time.Now())
+
+	require.NoError(t, err)
+	require.Equal(t, "live", analysis.Alias)
+	require.Equal(t, "3", analysis.Baseline.Version)
+	require.Equal(t, "4", analysis.Canary.Version)
+	require.Equal(t, sdktypes.CanaryVerdictProceed, analysis.Verdict)
+	require.Equal(t, codedeploytypes.LifecycleEventStatusSucceeded, codeDeploy.inputs[0].Status)
+}
+
+func TestHandleCanaryHook_AnalysisErrorFailsDeployment(t *testing.T) {
+	codeDeploy := &mockCodeDeployClient{}
+	aliasClient := &mockAliasClient{err: errors.New("access denied")}
+	config := sdktypes.CanaryHookConfig{FunctionName: "my-fn", Alias: "live", Window: 10 * time.Minute}
+
+	_, err := metrics.HandleCanaryHook(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, aliasClient, codeDeploy,
+		config, sdktypes.CodeDeployHookEvent{DeploymentID: "d-123", LifecycleEventHookExecutionID: "hook-1"}, time.Now())
+
+	require.Error(t, err)
+	require.Len(t, codeDeploy.inputs, 1)
+	require.Equal(t, codedeploytypes.LifecycleEventStatusFailed, codeDeploy.inputs[0].Status)
+}
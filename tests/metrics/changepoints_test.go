@@ -0,0 +1,85 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetChangePoints_DurationShiftAfterDeployment(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	hours := 72
+	shift := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			result := types.MetricDataResult{}
+			for hour := 0; hour < hours; hour++ {
+				ts := start.Add(time.Duration(hour) * time.Hour)
+				value := 1000.0
+				switch {
+				case metricName == "Errors":
+					value = 10
+				case metricName == "Duration" && ts.Before(shift):
+					value = float64(300 + hour%5)
+				case metricName == "Duration":
+					value = float64(500 + hour%5)
+				}
+				result.Timestamps = append(result.Timestamps, ts)
+				result.Values = append(result.Values, value)
+			}
+			return []types.MetricDataResult{result}, nil
+		},
+	}
+	logs := &mockLogsFetcher{results: []map[string]string{
+		{"bucket": "2025-01-01 00:00:00.000", "maxMemoryUsedMB": "128"},
+		{"bucket": "2025-01-01 01:00:00.000", "maxMemoryUsedMB": "128"},
+		{"bucket": "2025-01-01 02:00:00.000", "maxMemoryUsedMB": "128"},
+	}}
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: start, EndTime: start.Add(time.Duration(hours) * time.Hour)}
+
+	report, err := metrics.GetChangePoints(context.Background(), logs, cw, deploymentVersions(), deploymentEvents(), query, sdktypes.ChangePointOptions{})
+
+	require.NoError(t, err)
+	require.Len(t, report.Series["errorRate"], hours)
+	require.Len(t, report.Series["p99DurationMs"], hours)
+	require.Len(t, report.Series["maxMemoryUsedMB"], 3)
+	require.Len(t, report.Detections, 3)
+	require.Len(t, report.Deployments, 3)
+	require.Len(t, report.ChangePoints, 1)
+	change := report.ChangePoints[0]
+	require.Equal(t, "p99DurationMs", change.Metric)
+	require.Equal(t, shift, change.Time)
+	require.Equal(t, sdktypes.AnomalyDirectionUp, change.Direction)
+	require.Equal(t, "B", change.NearestDeployment.CodeSha256)
+	require.Equal(t, time.Hour, change.DeploymentDistance)
+	require.True(t, change.Attributed)
+}
+
+func TestGetChangePoints_InvalidOptions(t *testing.T) {
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST"}
+
+	_, err := metrics.GetChangePoints(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, deploymentVersions(), deploymentEvents(), query,
+		sdktypes.ChangePointOptions{MinRelativeChange: -0.1})
+
+	require.Error(t, err)
+}
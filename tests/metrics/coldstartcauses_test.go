@@ -0,0 +1,154 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func lastModifiedLambdaClient(lastModified string) *mockLambdaClient {
+	return &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{
+					LastModified: aws.String(lastModified),
+				},
+			}, nil
+		},
+	}
+}
+
+func TestGetColdStartCauses_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cold := "Init Duration: 1000.00 ms\t"
+	logs := &mockLogsFetcher{results: []map[string]string{
+		// First environment after the deployment at 00:05, its second invocation crashes.
+		reportRow(start.Add(6*time.Minute), "a", "a-1", 1000, 128, 64, cold),
+		reportRow(start.Add(7*time.Minute), "a", "a-2", 1000, 128, 64, "Status: error\tError Type: Runtime.ExitError"),
+		// Replaces the crashed environment.
+		reportRow(start.Add(8*time.Minute), "b", "b-1", 1000, 128, 64, cold),
+		reportRow(start.Add(9*time.Minute), "b", "b-2", 10000, 128, 64, ""),
+		// Starts while b is busy.
+		reportRow(start.Add(9*time.Minute), "c", "c-1", 1000, 128, 64, cold),
+		// Starts after 30 minutes without any activity.
+		reportRow(start.Add(40*time.Minute), "d", "d-1", 1000, 128, 64, cold),
+		// Starts shortly after d without any reason visible in the logs.
+		reportRow(start.Add(42*time.Minute), "e", "e-1", 1000, 128, 64, cold),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{7}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetColdStartCauses(context.Background(), logs, cw, lastModifiedLambdaClient("2025-01-01T00:05:00.000+0000"), cache.NewCache(), query, 0)
+	require.NoError(t, err)
+	require.Equal(t, 5, result.ColdStarts)
+
+	causes := make(map[string]sdktypes.ColdStartCause)
+	for _, classification := range result.Classifications {
+		causes[classification.RequestID] = classification.Cause
+	}
+	require.Equal(t, map[string]sdktypes.ColdStartCause{
+		"a-1": sdktypes.ColdStartCauseDeployment,
+		"b-1": sdktypes.ColdStartCauseErrorReplacement,
+		"c-1": sdktypes.ColdStartCauseScaleOut,
+		"d-1": sdktypes.ColdStartCauseIdleRecycle,
+		"e-1": sdktypes.ColdStartCauseUnknown,
+	}, causes)
+	require.Equal(t, 1, result.ScaleOut.Count)
+	require.InDelta(t, 0.2, result.ScaleOut.Rate, 1e-9)
+	require.Empty(t, result.Warnings)
+	require.Equal(t, start.Add(6*time.Minute-2*time.Second), result.Classifications[0].Timestamp)
+}
+
+func TestGetColdStartCauses_NoPreviousEnvironment(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs := &mockLogsFetcher{results: []map[string]string{
+		reportRow(start.Add(30*time.Minute), "a", "a-1", 1000, 128, 64, "Init Duration: 1000.00 ms\t"),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{1}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetColdStartCauses(context.Background(), logs, cw, lastModifiedLambdaClient("2024-12-01T00:00:00.000+0000"), cache.NewCache(), query, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.ColdStarts)
+	require.Equal(t, 0, result.IdleRecycle.Count)
+	require.Equal(t, 1, result.Unknown.Count)
+}
+
+func TestGetColdStartCauses_IdleSinceReplacedEnvironment(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []map[string]string
+	// Environment a keeps serving a request every two minutes.
+	for i := 0; i < 30; i++ {
+		rows = append(rows, reportRow(start.Add(time.Duration(2*i)*time.Minute), "a", fmt.Sprintf("a-%d", i), 10, 128, 64, ""))
+	}
+	// Environment b retires after a minute and is replaced by c half an hour later.
+	rows = append(rows,
+		reportRow(start.Add(time.Minute), "b", "b-1", 10, 128, 64, ""),
+		reportRow(start.Add(31*time.Minute), "c", "c-1", 1000, 128, 64, "Init Duration: 1000.00 ms\t"),
+	)
+	logs := &mockLogsFetcher{results: rows}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{32}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetColdStartCauses(context.Background(), logs, cw, lastModifiedLambdaClient("2024-12-01T00:00:00.000+0000"), cache.NewCache(), query, 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.ColdStarts)
+	require.Equal(t, sdktypes.ColdStartCauseIdleRecycle, result.Classifications[0].Cause)
+}
+
+func TestGetColdStartCauses_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetColdStartCauses(context.Background(), logs, cw, lastModifiedLambdaClient("2025-01-01T00:05:00.000+0000"), cache.NewCache(), query, 0)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
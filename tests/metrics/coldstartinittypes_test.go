@@ -0,0 +1,102 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetColdStartsByInitType_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	initRow := func(ts time.Time, logStream, message string) map[string]string {
+		return map[string]string{
+			"@timestamp": ts.Format("2006-01-02 15:04:05.000"),
+			"@logStream": logStream,
+			"@message":   message,
+		}
+	}
+	logs := &mockLogsFetcher{results: []map[string]string{
+		initRow(start, "od", "INIT_START Runtime Version: python:3.12.v30"),
+		reportRow(start.Add(time.Second), "od", "od-1", 10, 128, 64, "Init Duration: 200.00 ms\t"),
+		reportRow(start.Add(2*time.Second), "od", "od-2", 10, 128, 64, ""),
+
+		initRow(start, "ss", "RESTORE_START Runtime Version: java:21.v20"),
+		reportRow(start.Add(time.Second), "ss", "ss-1", 10, 128, 64, "Restore Duration: 300.00 ms\tBilled Restore Duration: 100 ms\t"),
+		reportRow(start.Add(2*time.Second), "ss", "ss-2", 10, 128, 64, ""),
+		reportRow(start.Add(3*time.Second), "ss", "ss-3", 10, 128, 64, ""),
+
+		initRow(start, "pc", `{"time":"2025-01-01T00:00:00.000Z","type":"platform.initStart","record":{"initializationType":"provisioned-concurrency","phase":"init"}}`),
+		initRow(start, "pc", `{"time":"2025-01-01T00:00:00.500Z","type":"platform.initReport","record":{"initializationType":"provisioned-concurrency","phase":"init","metrics":{"durationMs":500}}}`),
+		reportRow(start.Add(time.Minute), "pc", "pc-1", 10, 128, 64, ""),
+		reportRow(start.Add(2*time.Minute), "pc", "pc-2", 10, 128, 64, ""),
+
+		// Initialized before the time window.
+		reportRow(start.Add(time.Second), "old", "old-1", 10, 128, 64, ""),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{8}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetColdStartsByInitType(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.UnknownEnvironments)
+
+	require.Equal(t, 1, result.OnDemand.Environments)
+	require.Equal(t, 2, result.OnDemand.Invocations)
+	require.Equal(t, 1, result.OnDemand.ColdStarts)
+	require.InDelta(t, 0.5, result.OnDemand.ColdStartRate, 1e-9)
+	require.NotNil(t, result.OnDemand.InitDuration)
+	require.Equal(t, 200.0, result.OnDemand.InitDuration.Mean)
+
+	require.Equal(t, 1, result.SnapStart.Environments)
+	require.Equal(t, 1, result.SnapStart.ColdStarts)
+	require.InDelta(t, 1.0/3.0, result.SnapStart.ColdStartRate, 1e-9)
+	require.Equal(t, 300.0, result.SnapStart.InitDuration.Mean)
+
+	require.Equal(t, 1, result.ProvisionedConcurrency.Environments)
+	require.Equal(t, 2, result.ProvisionedConcurrency.Invocations)
+	require.Equal(t, 0, result.ProvisionedConcurrency.ColdStarts)
+	require.Equal(t, 500.0, result.ProvisionedConcurrency.InitDuration.Mean)
+}
+
+func TestGetColdStartsByInitType_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetColdStartsByInitType(context.Background(), logs, cw, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
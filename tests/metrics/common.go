@@ -16,9 +16,16 @@ package tests
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 
 	sdktypes "github.com/dominikhei/serverless-statistics/types"
 )
@@ -26,22 +33,46 @@ import (
 // The common file holds the mocks used by all test cases of the metrics package.
 
 // Mock CloudWatchFetcher based on the interface in the interfaces package.
+// If fetchFunc is set, it is used instead of the static results, which allows
+// returning different results per metric or query.
 type mockCWFetcher struct {
-	results []types.MetricDataResult
-	err     error
+	results   []types.MetricDataResult
+	err       error
+	fetchFunc func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error)
 }
 
 func (m *mockCWFetcher) FetchMetric(ctx context.Context, query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+	if m.fetchFunc != nil {
+		return m.fetchFunc(query, metricName, stat)
+	}
 	return m.results, m.err
 }
 
+func (m *mockCWFetcher) FetchMetricSeries(ctx context.Context, query sdktypes.FunctionQuery, metricName string, stat string, periodSeconds int32) ([]types.MetricDataResult, error) {
+	return m.FetchMetric(ctx, query, metricName, stat)
+}
+
+func (m *mockCWFetcher) FetchAccountMetricSeries(ctx context.Context, query sdktypes.FunctionQuery, metricName string, stat string, periodSeconds int32) ([]types.MetricDataResult, error) {
+	return m.FetchMetric(ctx, query, metricName, stat)
+}
+
+func (m *mockCWFetcher) FetchDimensionMetricSeries(ctx context.Context, query sdktypes.FunctionQuery, namespace string, metricName string, stat string, periodSeconds int32, dimensions map[string]string) ([]types.MetricDataResult, error) {
+	return m.FetchMetric(ctx, query, metricName, stat)
+}
+
 // Mock LogsInsights based on the interface in the interfaces package.
+// If runQueryFunc is set, it is used instead of the static results, which allows
+// returning different results per query.
 type mockLogsFetcher struct {
-	results []map[string]string
-	err     error
+	results      []map[string]string
+	err          error
+	runQueryFunc func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error)
 }
 
 func (m *mockLogsFetcher) RunQuery(ctx context.Context, fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+	if m.runQueryFunc != nil {
+		return m.runQueryFunc(fq, queryString)
+	}
 	return m.results, m.err
 }
 
@@ -53,3 +84,186 @@ type mockLambdaClient struct {
 func (m *mockLambdaClient) GetFunction(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
 	return m.GetFunctionFunc(ctx, params, optFns...)
 }
+
+// reportRow builds a Logs Insights result row of a REPORT line, as returned by the report records query.
+// extra is appended to the message, e.g. "Init Duration: 100.00 ms\t" or "Status: timeout".
+func reportRow(ts time.Time, logStream, requestID string, durationMs, memorySizeMB, maxMemoryUsedMB float64, extra string) map[string]string {
+	return map[string]string{
+		"@timestamp": ts.UTC().Format("2006-01-02 15:04:05.000"),
+		"@logStream": logStream,
+		"@requestId": requestID,
+		"@message": fmt.Sprintf("REPORT RequestId: %s\tDuration: %.2f ms\tBilled Duration: %.0f ms\tMemory Size: %.0f MB\tMax Memory Used: %.0f MB\t%s",
+			requestID, durationMs, durationMs+1, memorySizeMB, maxMemoryUsedMB, extra),
+	}
+}
+
+// Mock provisioned concurrency client based on the interface in the interfaces package.
+type mockProvisionedConcurrencyClient struct {
+	output *lambda.GetProvisionedConcurrencyConfigOutput
+	err    error
+}
+
+func (m *mockProvisionedConcurrencyClient) GetProvisionedConcurrencyConfig(ctx context.Context, params *lambda.GetProvisionedConcurrencyConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetProvisionedConcurrencyConfigOutput, error) {
+	return m.output, m.err
+}
+
+// Mock concurrency client based on the interface in the interfaces package.
+type mockConcurrencyClient struct {
+	reserved     *int32
+	accountLimit *lambdatypes.AccountLimit
+	err          error
+}
+
+func (m *mockConcurrencyClient) GetFunctionConcurrency(ctx context.Context, params *lambda.GetFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionConcurrencyOutput, error) {
+	return &lambda.GetFunctionConcurrencyOutput{ReservedConcurrentExecutions: m.reserved}, m.err
+}
+
+func (m *mockConcurrencyClient) GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput, optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error) {
+	return &lambda.GetAccountSettingsOutput{AccountLimit: m.accountLimit}, m.err
+}
+
+// Mock event invoke config client based on the interface in the interfaces package.
+type mockEventInvokeConfigClient struct {
+	output *lambda.GetFunctionEventInvokeConfigOutput
+	err    error
+}
+
+func (m *mockEventInvokeConfigClient) GetFunctionEventInvokeConfig(ctx context.Context, params *lambda.GetFunctionEventInvokeConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionEventInvokeConfigOutput, error) {
+	return m.output, m.err
+}
+
+// Mock event source mapping client based on the interface in the interfaces package.
+// The mappings are returned in pages of pageSize, all at once if pageSize is 0.
+type mockEventSourceMappingClient struct {
+	mappings []lambdatypes.EventSourceMappingConfiguration
+	pageSize int
+	err      error
+}
+
+func (m *mockEventSourceMappingClient) ListEventSourceMappings(ctx context.Context, params *lambda.ListEventSourceMappingsInput, optFns ...func(*lambda.Options)) (*lambda.ListEventSourceMappingsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	start := 0
+	if params.Marker != nil {
+		fmt.Sscanf(*params.Marker, "%d", &start)
+	}
+	end := len(m.mappings)
+	if m.pageSize > 0 && start+m.pageSize < end {
+		end = start + m.pageSize
+	}
+	output := &lambda.ListEventSourceMappingsOutput{EventSourceMappings: m.mappings[start:end]}
+	if end < len(m.mappings) {
+		marker := fmt.Sprintf("%d", end)
+		output.NextMarker = &marker
+	}
+	return output, nil
+}
+
+// Mock function versions client based on the interface in the interfaces package.
+type mockFunctionVersionsClient struct {
+	versions []lambdatypes.FunctionConfiguration
+	err      error
+}
+
+func (m *mockFunctionVersionsClient) ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error) {
+	return &lambda.ListVersionsByFunctionOutput{Versions: m.versions}, m.err
+}
+
+// Mock function list client based on the interface in the interfaces package.
+type mockFunctionListClient struct {
+	functions []lambdatypes.FunctionConfiguration
+	err       error
+}
+
+func (m *mockFunctionListClient) ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error) {
+	return &lambda.ListFunctionsOutput{Functions: m.functions}, m.err
+}
+
+// Mock function URL client based on the interface in the interfaces package.
+type mockFunctionURLClient struct {
+	output *lambda.GetFunctionUrlConfigOutput
+	err    error
+}
+
+func (m *mockFunctionURLClient) GetFunctionUrlConfig(ctx context.Context, params *lambda.GetFunctionUrlConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionUrlConfigOutput, error) {
+	return m.output, m.err
+}
+
+// Mock CloudTrail client based on the interface in the interfaces package.
+// The events are returned in pages of pageSize, all at once if it is 0.
+// Events are filtered by the ResourceName lookup attribute like CloudTrail does.
+type mockCloudTrailClient struct {
+	events        []cloudtrailtypes.Event
+	pageSize      int
+	err           error
+	resourceNames []string
+}
+
+func (m *mockCloudTrailClient) LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput, optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	events := m.events
+	for _, attribute := range params.LookupAttributes {
+		if attribute.AttributeKey != cloudtrailtypes.LookupAttributeKeyResourceName {
+			continue
+		}
+		if params.NextToken == nil {
+			m.resourceNames = append(m.resourceNames, aws.ToString(attribute.AttributeValue))
+		}
+		events = []cloudtrailtypes.Event{}
+		for _, event := range m.events {
+			for _, resource := range event.Resources {
+				if aws.ToString(resource.ResourceName) == aws.ToString(attribute.AttributeValue) {
+					events = append(events, event)
+					break
+				}
+			}
+		}
+	}
+	start := 0
+	if params.NextToken != nil {
+		fmt.Sscanf(*params.NextToken, "%d", &start)
+	}
+	end := len(events)
+	if m.pageSize > 0 && start+m.pageSize < end {
+		end = start + m.pageSize
+	}
+	output := &cloudtrail.LookupEventsOutput{Events: events[start:end]}
+	if end < len(events) {
+		next := fmt.Sprintf("%d", end)
+		output.NextToken = &next
+	}
+	return output, nil
+}
+
+// Mock alias client based on the interface in the interfaces package.
+type mockAliasClient struct {
+	output *lambda.GetAliasOutput
+	err    error
+}
+
+func (m *mockAliasClient) GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error) {
+	return m.output, m.err
+}
+
+// Mock CodeDeploy client based on the interface in the interfaces package.
+// The inputs of the calls are recorded to assert on the reported status.
+type mockCodeDeployClient struct {
+	deployment *codedeploy.GetDeploymentOutput
+	inputs     []*codedeploy.PutLifecycleEventHookExecutionStatusInput
+	err        error
+}
+
+func (m *mockCodeDeployClient) GetDeployment(ctx context.Context, params *codedeploy.GetDeploymentInput, optFns ...func(*codedeploy.Options)) (*codedeploy.GetDeploymentOutput, error) {
+	if m.deployment == nil {
+		return nil, fmt.Errorf("deployment %s not found", *params.DeploymentId)
+	}
+	return m.deployment, nil
+}
+
+func (m *mockCodeDeployClient) PutLifecycleEventHookExecutionStatus(ctx context.Context, params *codedeploy.PutLifecycleEventHookExecutionStatusInput, optFns ...func(*codedeploy.Options)) (*codedeploy.PutLifecycleEventHookExecutionStatusOutput, error) {
+	m.inputs = append(m.inputs, params)
+	return &codedeploy.PutLifecycleEventHookExecutionStatusOutput{}, m.err
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// seriesFetcher returns a mock whose metrics are series with the given values at the timestamps.
+func seriesFetcher(timestamps []time.Time, series map[string][]float64) *mockCWFetcher {
+	return &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			values, ok := series[metricName]
+			if !ok {
+				return []types.MetricDataResult{{}}, nil
+			}
+			return []types.MetricDataResult{{Timestamps: timestamps[:len(values)], Values: values}}, nil
+		},
+	}
+}
+
+func TestGetConcurrencyProfile_ReservedConcurrency(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cw := seriesFetcher([]time.Time{start, start.Add(3 * time.Minute)}, map[string][]float64{
+		"Invocations":          {50, 20},
+		"ConcurrentExecutions": {9, 4},
+		"Throttles":            {5, 1},
+	})
+	client := &mockConcurrencyClient{
+		reserved: aws.Int32(10),
+		accountLimit: &lambdatypes.AccountLimit{
+			ConcurrentExecutions:           1000,
+			UnreservedConcurrentExecutions: aws.Int32(990),
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+	}
+
+	result, err := metrics.GetConcurrencyProfile(context.Background(), &mockLogsFetcher{}, cw, client, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, "cloudwatch", result.ConcurrencySource)
+	require.Equal(t, int32(10), result.ConcurrencyLimit)
+	require.Equal(t, int32(1000), result.AccountConcurrencyLimit)
+	require.Equal(t, 9.0, result.PeakConcurrency)
+	require.Equal(t, 1.0, result.Headroom)
+	require.InDelta(t, 0.1, result.HeadroomRate, 1e-9)
+
+	require.Equal(t, 6.0, result.Throttles)
+	require.Len(t, result.ThrottleBursts, 2)
+	require.True(t, result.ThrottleBursts[0].Saturated)
+	require.False(t, result.ThrottleBursts[1].Saturated)
+	require.InDelta(t, 5.0/6.0, result.SaturatedThrottleShare, 1e-9)
+}
+
+func TestGetConcurrencyProfile_EstimatedFromLogs(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cw := seriesFetcher([]time.Time{start}, map[string][]float64{
+		"Invocations":                    {2},
+		"Throttles":                      {3},
+		"UnreservedConcurrentExecutions": {850},
+	})
+	logs := &mockLogsFetcher{results: []map[string]string{
+		reportRow(start.Add(10*time.Second), "a", "a-1", 5000, 128, 64, ""),
+		reportRow(start.Add(12*time.Second), "b", "b-1", 5000, 128, 64, ""),
+	}}
+	client := &mockConcurrencyClient{
+		accountLimit: &lambdatypes.AccountLimit{
+			ConcurrentExecutions:           1000,
+			UnreservedConcurrentExecutions: aws.Int32(900),
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetConcurrencyProfile(context.Background(), logs, cw, client, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, "logs", result.ConcurrencySource)
+	require.Nil(t, result.ReservedConcurrency)
+	require.Equal(t, int32(900), result.ConcurrencyLimit)
+	require.Equal(t, 2.0, result.PeakConcurrency)
+	require.Len(t, result.ThrottleBursts, 1)
+	require.Equal(t, 850.0, result.ThrottleBursts[0].PeakConcurrency)
+	require.True(t, result.ThrottleBursts[0].Saturated)
+	require.Equal(t, 1.0, result.SaturatedThrottleShare)
+}
+
+func TestGetConcurrencyProfile_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetConcurrencyProfile(context.Background(), &mockLogsFetcher{}, cw, &mockConcurrencyClient{}, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetConfigurationDrift_Changes(t *testing.T) {
+	baseline := &sdktypes.BaseStatisticsReturn{
+		FunctionName:   "my-fn",
+		Qualifier:      "1",
+		MemorySizeMB:   aws.Int32(512),
+		TimeoutSeconds: aws.Int32(30),
+		Runtime:        "python3.11",
+		EnvironmentVariables: map[string]string{
+			"STAGE":       "prod",
+			"OLD_FLAG":    "on",
+			"DB_PASSWORD": "sha256:aaa",
+			"API_TOKEN":   "[REDACTED]",
+		},
+		EnvironmentVariableVisibility: map[string]sdktypes.EnvironmentVariableVisibility{
+			"STAGE":       sdktypes.EnvironmentVariableRevealed,
+			"OLD_FLAG":    sdktypes.EnvironmentVariableRevealed,
+			"DB_PASSWORD": sdktypes.EnvironmentVariableHashed,
+			"API_TOKEN":   sdktypes.EnvironmentVariableRedacted,
+		},
+		Layers: []sdktypes.LayerConfiguration{
+			{Arn: "arn:aws:lambda:eu-west-1:123456789012:layer:deps:3"},
+			{Arn: "arn:aws:lambda:eu-west-1:123456789012:layer:old:1"},
+		},
+		ReservedConcurrency: aws.Int32(10),
+	}
+	target := &sdktypes.BaseStatisticsReturn{
+		FunctionName:   "my-fn",
+		Qualifier:      "2",
+		MemorySizeMB:   aws.Int32(1024),
+		TimeoutSeconds: aws.Int32(30),
+		Runtime:        "python3.12",
+		EnvironmentVariables: map[string]string{
+			"STAGE":       "prod",
+			"NEW_FLAG":    "on",
+			"DB_PASSWORD": "sha256:bbb",
+			"API_TOKEN":   "[REDACTED]",
+		},
+		EnvironmentVariableVisibility: map[string]sdktypes.EnvironmentVariableVisibility{
+			"STAGE":       sdktypes.EnvironmentVariableRevealed,
+			"NEW_FLAG":    sdktypes.EnvironmentVariableRevealed,
+			"DB_PASSWORD": sdktypes.EnvironmentVariableHashed,
+			"API_TOKEN":   sdktypes.EnvironmentVariableRedacted,
+		},
+		Layers: []sdktypes.LayerConfiguration{
+			{Arn: "arn:aws:lambda:eu-west-1:123456789012:layer:deps:4"},
+		},
+		VpcConfig: &sdktypes.VpcConfiguration{
+			VpcID:            "vpc-1",
+			SubnetIDs:        []string{"subnet-a", "subnet-b"},
+			SecurityGroupIDs: []string{"sg-1"},
+		},
+	}
+
+	result, err := metrics.GetConfigurationDrift(context.Background(), &mockCWFetcher{}, cache.NewCache(), baseline, target, 0)
+	require.NoError(t, err)
+	require.True(t, result.HasDrift)
+	require.Nil(t, result.MetricShift)
+	require.Equal(t, []sdktypes.ConfigurationChange{
+		{Field: "environmentVariables.DB_PASSWORD", Kind: sdktypes.ConfigurationChangeModified},
+		{Field: "environmentVariables.NEW_FLAG", Kind: sdktypes.ConfigurationChangeAdded},
+		{Field: "environmentVariables.OLD_FLAG", Kind: sdktypes.ConfigurationChangeRemoved},
+		{Field: "layers.arn:aws:lambda:eu-west-1:123456789012:layer:deps", Kind: sdktypes.ConfigurationChangeModified,
+			Baseline: "arn:aws:lambda:eu-west-1:123456789012:layer:deps:3", Target: "arn:aws:lambda:eu-west-1:123456789012:layer:deps:4"},
+		{Field: "layers.arn:aws:lambda:eu-west-1:123456789012:layer:old", Kind: sdktypes.ConfigurationChangeRemoved,
+			Baseline: "arn:aws:lambda:eu-west-1:123456789012:layer:old:1"},
+		{Field: "memorySizeMb", Kind: sdktypes.ConfigurationChangeModified, Baseline: "512", Target: "1024"},
+		{Field: "reservedConcurrency", Kind: sdktypes.ConfigurationChangeRemoved, Baseline: "10"},
+		{Field: "runtime", Kind: sdktypes.ConfigurationChangeModified, Baseline: "python3.11", Target: "python3.12"},
+		{Field: "vpcConfig.securityGroupIds", Kind: sdktypes.ConfigurationChangeAdded, Target: "sg-1"},
+		{Field: "vpcConfig.subnetIds", Kind: sdktypes.ConfigurationChangeAdded, Target: "subnet-a,subnet-b"},
+		{Field: "vpcConfig.vpcId", Kind: sdktypes.ConfigurationChangeAdded, Target: "vpc-1"},
+	}, result.Changes)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "API_TOKEN")
+}
+
+func TestGetConfigurationDrift_NoDrift(t *testing.T) {
+	snapshot := &sdktypes.BaseStatisticsReturn{
+		FunctionName:         "my-fn",
+		Qualifier:            "$LATEST",
+		MemorySizeMB:         aws.Int32(128),
+		EnvironmentVariables: map[string]string{"STAGE": "prod"},
+	}
+
+	result, err := metrics.GetConfigurationDrift(context.Background(), &mockCWFetcher{}, cache.NewCache(), snapshot, snapshot, 0)
+	require.NoError(t, err)
+	require.False(t, result.HasDrift)
+	require.Empty(t, result.Changes)
+	require.Empty(t, result.Warnings)
+}
+
+func TestGetConfigurationDrift_MetricShift(t *testing.T) {
+	baseline := &sdktypes.BaseStatisticsReturn{FunctionName: "my-fn", Qualifier: "1", TimeoutSeconds: aws.Int32(3)}
+	target := &sdktypes.BaseStatisticsReturn{FunctionName: "my-fn", Qualifier: "2", TimeoutSeconds: aws.Int32(10),
+		LastModified: "2025-01-01T12:00:00.000+0000"}
+	changeTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sums := map[string]map[string]float64{
+		"1": {"Invocations": 100, "Errors": 10, "Throttles": 0, "Duration": 20000},
+		"2": {"Invocations": 200, "Errors": 2, "Throttles": 4, "Duration": 60000},
+	}
+	var queries []sdktypes.FunctionQuery
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if metricName == "Invocations" {
+				queries = append(queries, query)
+			}
+			return []types.MetricDataResult{{Values: []float64{sums[query.Qualifier][metricName]}}}, nil
+		},
+	}
+
+	result, err := metrics.GetConfigurationDrift(context.Background(), cw, cache.NewCache(), baseline, target, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, result.Changes, 1)
+	require.Equal(t, []sdktypes.FunctionQuery{
+		{FunctionName: "my-fn", Qualifier: "1", StartTime: changeTime.Add(-time.Hour), EndTime: changeTime},
+		{FunctionName: "my-fn", Qualifier: "2", StartTime: changeTime, EndTime: changeTime.Add(time.Hour)},
+	}, queries)
+
+	shift := result.MetricShift
+	require.NotNil(t, shift)
+	require.Equal(t, changeTime, shift.ChangeTime)
+	require.Equal(t, 100, shift.Before.Invocations)
+	require.InDelta(t, 0.1, shift.Before.ErrorRate, 1e-9)
+	require.InDelta(t, 200, shift.Before.MeanDurationMs, 1e-9)
+	require.InDelta(t, 0.02, shift.After.ThrottleRate, 1e-9)
+	require.InDelta(t, -0.09, shift.ErrorRateChange, 1e-9)
+	require.InDelta(t, 100, shift.MeanDurationChangeMs, 1e-9)
+	require.InDelta(t, 0.5, *shift.RelativeMeanDurationChange, 1e-9)
+	require.Empty(t, result.Warnings)
+}
+
+func TestGetConfigurationDrift_DifferentFunctions(t *testing.T) {
+	_, err := metrics.GetConfigurationDrift(context.Background(), &mockCWFetcher{}, cache.NewCache(),
+		&sdktypes.BaseStatisticsReturn{FunctionName: "a"}, &sdktypes.BaseStatisticsReturn{FunctionName: "b"}, 0)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "different functions")
+}
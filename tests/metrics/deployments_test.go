@@ -0,0 +1,194 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	cloudtrailtypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// codeUpdateEvent returns an UpdateFunctionCode CloudTrail event of a function.
+func codeUpdateEvent(functionName string, eventTime time.Time, codeSha256, user string) cloudtrailtypes.Event {
+	return lambdaEvent("UpdateFunctionCode20150331v2", functionName, eventTime, codeSha256, user)
+}
+
+// lambdaEvent returns a CloudTrail event of a Lambda API call on a function, referred to by name or ARN.
+func lambdaEvent(eventName, functionName string, eventTime time.Time, codeSha256, user string) cloudtrailtypes.Event {
+	return cloudtrailtypes.Event{
+		EventId:   aws.String(fmt.Sprintf("%s-%s-%s", eventName, functionName, eventTime.Format(time.RFC3339))),
+		EventName: aws.String(eventName),
+		EventTime: aws.Time(eventTime),
+		Username:  aws.String(user),
+		Resources: []cloudtrailtypes.Resource{{
+			ResourceName: aws.String(functionName),
+			ResourceType: aws.String("AWS::Lambda::Function"),
+		}},
+		CloudTrailEvent: aws.String(fmt.Sprintf(
+			`{"requestParameters":{"functionName":%q},"responseElements":{"functionName":%q,"codeSha256":%q}}`,
+			functionName, functionName, codeSha256)),
+	}
+}
+
+// deploymentVersions returns the published versions 1 (code A) and 2 (code B) and $LATEST (code C).
+func deploymentVersions() *mockFunctionVersionsClient {
+	return &mockFunctionVersionsClient{
+		versions: []lambdatypes.FunctionConfiguration{
+			{Version: aws.String("$LATEST"), CodeSha256: aws.String("C"), LastModified: aws.String("2025-01-03T10:00:00.000+0000"),
+				FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-fn:$LATEST")},
+			{Version: aws.String("1"), CodeSha256: aws.String("A"), LastModified: aws.String("2024-12-20T12:00:00.000+0000"),
+				FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-fn:1")},
+			{Version: aws.String("2"), CodeSha256: aws.String("B"), LastModified: aws.String("2025-01-02T09:05:00.000+0000"),
+				FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-fn:2")},
+		},
+	}
+}
+
+// deploymentEvents returns the CloudTrail events of the deployments of code B and C, a configuration
+// update and a deployment of another function.
+func deploymentEvents() *mockCloudTrailClient {
+	return &mockCloudTrailClient{
+		events: []cloudtrailtypes.Event{
+			codeUpdateEvent("arn:aws:lambda:us-east-1:123456789012:function:my-fn", time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC), "C", "bob"),
+			codeUpdateEvent("other-fn", time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC), "X", "alice"),
+			codeUpdateEvent("my-fn", time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), "B", "alice"),
+			lambdaEvent("UpdateFunctionConfiguration20150331v2", "my-fn", time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), "D", "alice"),
+		},
+		pageSize: 2,
+	}
+}
+
+func TestGetDeploymentTimeline(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	events := deploymentEvents()
+
+	result, err := metrics.GetDeploymentTimeline(context.Background(), deploymentVersions(), events, "my-fn", start, start.Add(72*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, result.Warnings)
+	require.Equal(t, []string{"my-fn", "arn:aws:lambda:us-east-1:123456789012:function:my-fn"}, events.resourceNames)
+	require.Equal(t, []sdktypes.Deployment{
+		{Time: time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC), CodeSha256: "A", Version: "1", Source: sdktypes.DeploymentSourceVersion},
+		{Time: time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC), CodeSha256: "B", Version: "2", Source: sdktypes.DeploymentSourceCloudTrail, User: "alice"},
+		{Time: time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC), CodeSha256: "C", Source: sdktypes.DeploymentSourceCloudTrail, User: "bob"},
+	}, result.Deployments)
+}
+
+func TestGetDeploymentTimeline_WithoutCloudTrail(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := metrics.GetDeploymentTimeline(context.Background(), deploymentVersions(),
+		&mockCloudTrailClient{err: errors.New("access denied")}, "my-fn", start, start.Add(72*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "CloudTrail events are not available")
+	require.Len(t, result.Deployments, 3)
+	require.Equal(t, sdktypes.DeploymentSourceVersion, result.Deployments[1].Source)
+	require.Equal(t, time.Date(2025, 1, 2, 9, 5, 0, 0, time.UTC), result.Deployments[1].Time)
+	require.Equal(t, sdktypes.DeploymentSourceLastModified, result.Deployments[2].Source)
+}
+
+func TestGetDeploymentTimeline_OnlyLatest(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	versions := &mockFunctionVersionsClient{
+		versions: []lambdatypes.FunctionConfiguration{
+			{Version: aws.String("$LATEST"), CodeSha256: aws.String("C"), LastModified: aws.String("2025-01-03T10:00:00.000+0000")},
+		},
+	}
+
+	result, err := metrics.GetDeploymentTimeline(context.Background(), versions, &mockCloudTrailClient{}, "my-fn", start, start.Add(72*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, result.Deployments, 1)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "can also be a configuration update")
+}
+
+func TestSplitByDeployments(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(72 * time.Hour),
+	}
+	secondDeployment := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+	thirdDeployment := time.Date(2025, 1, 3, 8, 0, 0, 0, time.UTC)
+	metric := func(ctx context.Context, query sdktypes.FunctionQuery) (time.Duration, error) {
+		if query.StartTime.Equal(secondDeployment) {
+			return 0, &sdkerrors.NoInvocationsError{FunctionName: query.FunctionName}
+		}
+		return query.EndTime.Sub(query.StartTime), nil
+	}
+
+	result, err := metrics.SplitByDeployments(context.Background(), deploymentVersions(), deploymentEvents(), query, metric)
+	require.NoError(t, err)
+	require.Len(t, result.Segments, 3)
+
+	require.Equal(t, "A", result.Segments[0].Deployment.CodeSha256)
+	require.Equal(t, start, result.Segments[0].StartTime)
+	require.Equal(t, secondDeployment, result.Segments[0].EndTime)
+	require.Equal(t, secondDeployment.Sub(start), result.Segments[0].Result)
+
+	require.Equal(t, "B", result.Segments[1].Deployment.CodeSha256)
+	require.True(t, result.Segments[1].NoInvocations)
+	require.Zero(t, result.Segments[1].Result)
+
+	require.Equal(t, "C", result.Segments[2].Deployment.CodeSha256)
+	require.Equal(t, thirdDeployment, result.Segments[2].StartTime)
+	require.Equal(t, query.EndTime, result.Segments[2].EndTime)
+	require.False(t, result.Segments[2].NoInvocations)
+}
+
+func TestSplitByDeployments_UnknownRevisionAtStart(t *testing.T) {
+	start := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(24 * time.Hour),
+	}
+	versions := &mockFunctionVersionsClient{
+		versions: []lambdatypes.FunctionConfiguration{
+			{Version: aws.String("$LATEST"), CodeSha256: aws.String("C"), LastModified: aws.String("2025-01-03T10:00:00.000+0000")},
+		},
+	}
+	metric := func(ctx context.Context, query sdktypes.FunctionQuery) (int, error) { return 1, nil }
+
+	result, err := metrics.SplitByDeployments(context.Background(), versions, &mockCloudTrailClient{}, query, metric)
+	require.NoError(t, err)
+	require.Len(t, result.Segments, 2)
+	require.Nil(t, result.Segments[0].Deployment)
+	require.Equal(t, "C", result.Segments[1].Deployment.CodeSha256)
+}
+
+func TestSplitByDeployments_PublishedVersion(t *testing.T) {
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "1"}
+	metric := func(ctx context.Context, query sdktypes.FunctionQuery) (int, error) { return 0, nil }
+
+	_, err := metrics.SplitByDeployments(context.Background(), deploymentVersions(), &mockCloudTrailClient{}, query, metric)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "immutable")
+}
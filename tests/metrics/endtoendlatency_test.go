@@ -0,0 +1,129 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetEndToEndLatencyStatistics_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []map[string]string
+	for i := 0; i < 98; i++ {
+		rows = append(rows, reportRow(start.Add(time.Duration(i)*time.Second), "warm", fmt.Sprintf("w-%d", i), 100, 128, 64, ""))
+	}
+	rows = append(rows,
+		reportRow(start.Add(time.Minute), "slow", "slow", 500, 128, 64, ""),
+		reportRow(start.Add(2*time.Minute), "cold-1", "c-1", 100, 128, 64, "Init Duration: 900.00 ms\t"),
+		reportRow(start.Add(3*time.Minute), "cold-2", "c-2", 100, 128, 64, "Restore Duration: 900.00 ms\tBilled Restore Duration: 100 ms\t"),
+	)
+	logs := &mockLogsFetcher{results: rows}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{101}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetEndToEndLatencyStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 101, result.All.Count)
+	require.Equal(t, 99, result.Warm.Count)
+	require.Equal(t, 500.0, result.Warm.Max)
+	require.Equal(t, 2, result.Cold.Count)
+	require.Equal(t, 1000.0, result.Cold.Mean)
+
+	require.NotNil(t, result.All.P99)
+	require.Equal(t, 1000.0, *result.All.P99)
+	require.NotNil(t, result.ColdStartShareOfP99Tail)
+	require.InDelta(t, 1.0, *result.ColdStartShareOfP99Tail, 1e-9)
+	require.NotNil(t, result.InitTimeShareOfP99Tail)
+	require.InDelta(t, 0.9, *result.InitTimeShareOfP99Tail, 1e-9)
+	require.NotNil(t, result.P99IncreaseFromColdStarts)
+	require.InDelta(t, 900, *result.P99IncreaseFromColdStarts, 1e-9)
+	require.Empty(t, result.Warnings)
+}
+
+func TestGetEndToEndLatencyStatistics_TruncatedReports(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]map[string]string, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		rows = append(rows, reportRow(start.Add(time.Duration(i)*time.Second), "a", fmt.Sprintf("r-%d", i), 100, 128, 64, ""))
+	}
+	logs := &mockLogsFetcher{results: rows}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{20000}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(6 * time.Hour),
+	}
+
+	result, err := metrics.GetEndToEndLatencyStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 10000, result.All.Count)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "10000")
+}
+
+func TestGetEndToEndLatencyStatistics_TooFewForP99(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs := &mockLogsFetcher{results: []map[string]string{
+		reportRow(start, "a", "1", 100, 128, 64, "Init Duration: 50.00 ms\t"),
+		reportRow(start.Add(time.Second), "a", "2", 100, 128, 64, ""),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{2}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetEndToEndLatencyStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 150.0, result.Cold.Max)
+	require.Equal(t, 100.0, result.Warm.Max)
+	require.Nil(t, result.ColdStartShareOfP99Tail)
+	require.Nil(t, result.P99IncreaseFromColdStarts)
+}
+
+func TestGetEndToEndLatencyStatistics_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetEndToEndLatencyStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetEnvironmentLifecycleStatistics_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cold := "Init Duration: 1000.00 ms\t"
+	logs := &mockLogsFetcher{results: []map[string]string{
+		reportRow(start.Add(10*time.Second), "a", "a-1", 1000, 128, 64, cold),
+		reportRow(start.Add(70*time.Second), "a", "a-2", 1000, 128, 64, ""),
+		reportRow(start.Add(310*time.Second), "a", "a-3", 1000, 128, 64, ""),
+		reportRow(start.Add(90*time.Second), "b", "b-1", 1000, 128, 64, cold),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{4}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(48 * time.Minute),
+	}
+
+	result, err := metrics.GetEnvironmentLifecycleStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.EnvironmentCount)
+	require.InDelta(t, 1.0/3.0, result.InitTimeFraction, 1e-9)
+
+	a := result.Environments[0]
+	require.Equal(t, "a", a.LogStream)
+	require.Equal(t, 3, a.Invocations)
+	require.Equal(t, start.Add(8*time.Second), a.FirstSeen)
+	require.InDelta(t, 302, a.LifetimeSeconds, 1e-9)
+	require.InDelta(t, 239, a.MaxIdleGapSeconds, 1e-9)
+	require.InDelta(t, 0.25, a.InitTimeFraction, 1e-9)
+
+	require.NotNil(t, result.InvocationsPerEnvironment)
+	require.Equal(t, 2.0, result.InvocationsPerEnvironment.Mean)
+	require.NotNil(t, result.IdleGapSeconds)
+	require.Equal(t, 2, result.IdleGapSeconds.Count)
+	require.InDelta(t, 149, result.IdleGapSeconds.Mean, 1e-9)
+
+	require.Len(t, result.ConcurrentEnvironments, 48)
+	require.Equal(t, 1, result.ConcurrentEnvironments[0].Environments)
+	require.Equal(t, 2, result.ConcurrentEnvironments[1].Environments)
+	require.Equal(t, 1, result.ConcurrentEnvironments[5].Environments)
+	require.Equal(t, 0, result.ConcurrentEnvironments[6].Environments)
+	require.Equal(t, 2, result.PeakConcurrentEnvironments)
+}
+
+func TestGetEnvironmentLifecycleStatistics_JSONLogFormat(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	jsonReport := func(ts time.Time, requestID, metrics string) map[string]string {
+		return map[string]string{
+			"@timestamp": ts.Format("2006-01-02 15:04:05.000"),
+			"@logStream": "a",
+			"@message": fmt.Sprintf(`{"time":%q,"type":"platform.report","record":{"requestId":%q,"metrics":{%s},"status":"success"}}`,
+				ts.Format(time.RFC3339Nano), requestID, metrics),
+		}
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if !strings.Contains(queryString, `platform\.report`) {
+				return nil, errors.New("query does not match JSON platform.report records")
+			}
+			return []map[string]string{
+				jsonReport(start.Add(10*time.Second), "a-1", `"durationMs":1000,"billedDurationMs":1000,"memorySizeMB":128,"maxMemoryUsedMB":64,"initDurationMs":1000`),
+				jsonReport(start.Add(70*time.Second), "a-2", `"durationMs":1000,"billedDurationMs":1000,"memorySizeMB":128,"maxMemoryUsedMB":64`),
+			}, nil
+		},
+	}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{2}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(10 * time.Minute),
+	}
+
+	result, err := metrics.GetEnvironmentLifecycleStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.EnvironmentCount)
+	require.Equal(t, 2, result.Environments[0].Invocations)
+	require.Equal(t, start.Add(8*time.Second), result.Environments[0].FirstSeen)
+}
+
+func TestGetEnvironmentLifecycleStatistics_UnalignedStartTime(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 40, 0, time.UTC)
+	logs := &mockLogsFetcher{results: []map[string]string{
+		reportRow(start.Add(30*time.Second), "a", "a-1", 1000, 128, 64, ""),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{1}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(48 * time.Minute),
+	}
+
+	result, err := metrics.GetEnvironmentLifecycleStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Empty(t, result.Warnings)
+	require.Len(t, result.ConcurrentEnvironments, 48)
+	require.Equal(t, start, result.ConcurrentEnvironments[0].Timestamp)
+	require.Equal(t, 1, result.ConcurrentEnvironments[0].Environments)
+	require.Equal(t, 0, result.ConcurrentEnvironments[1].Environments)
+}
+
+func TestGetEnvironmentLifecycleStatistics_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetEnvironmentLifecycleStatistics(context.Background(), logs, cw, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
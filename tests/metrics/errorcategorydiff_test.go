@@ -0,0 +1,125 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetErrorCategoryDiff_Versions(t *testing.T) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if query.Qualifier == "1" {
+				return []types.MetricDataResult{{Values: []float64{100}}}, nil
+			}
+			return []types.MetricDataResult{{Values: []float64{200}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if fq.Qualifier == "1" {
+				return []map[string]string{
+					{"error_category": "ValidationError", "error_count": "10"},
+					{"error_category": "ThrottlingException", "error_count": "4"},
+					{"error_category": "ImportError", "error_count": "2"},
+				}, nil
+			}
+			return []map[string]string{
+				{"error_category": "ValidationError", "error_count": "40"},
+				{"error_category": "ThrottlingException", "error_count": "2"},
+				{"error_category": "KeyError", "error_count": "6"},
+			}, nil
+		},
+	}
+	now := time.Now()
+	baseline := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "1", StartTime: now.Add(-time.Hour), EndTime: now}
+	target := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "2", StartTime: now.Add(-time.Hour), EndTime: now}
+
+	result, err := metrics.GetErrorCategoryDiff(context.Background(), logs, cw, cache.NewCache(), baseline, target)
+	require.NoError(t, err)
+
+	require.True(t, result.HasNewErrors)
+	require.Equal(t, 100, result.BaselineInvocations)
+	require.Equal(t, 200, result.TargetInvocations)
+
+	require.Len(t, result.New, 1)
+	require.Equal(t, "KeyError", result.New[0].ErrorCategory)
+	require.InDelta(t, 0.03, result.New[0].TargetRate, 1e-9)
+	require.Nil(t, result.New[0].RelativeRateChange)
+
+	require.Len(t, result.Resolved, 1)
+	require.Equal(t, "ImportError", result.Resolved[0].ErrorCategory)
+
+	require.Len(t, result.Increased, 1)
+	require.Equal(t, "ValidationError", result.Increased[0].ErrorCategory)
+	require.InDelta(t, 0.1, result.Increased[0].RateChange, 1e-9)
+	require.InDelta(t, 1.0, *result.Increased[0].RelativeRateChange, 1e-9)
+
+	require.Len(t, result.Decreased, 1)
+	require.Equal(t, "ThrottlingException", result.Decreased[0].ErrorCategory)
+	require.InDelta(t, -0.03, result.Decreased[0].RateChange, 1e-9)
+}
+
+func TestGetErrorCategoryDiff_TimeWindowsUnchanged(t *testing.T) {
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{50}}},
+	}
+	logs := &mockLogsFetcher{
+		results: []map[string]string{
+			{"error_category": "ValidationError", "error_count": "5"},
+		},
+	}
+	now := time.Now()
+	baseline := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: now.Add(-2 * time.Hour), EndTime: now.Add(-time.Hour)}
+	target := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: now.Add(-time.Hour), EndTime: now}
+
+	result, err := metrics.GetErrorCategoryDiff(context.Background(), logs, cw, cache.NewCache(), baseline, target)
+	require.NoError(t, err)
+	require.False(t, result.HasNewErrors)
+	require.Empty(t, result.New)
+	require.Empty(t, result.Resolved)
+	require.Empty(t, result.Increased)
+	require.Empty(t, result.Decreased)
+}
+
+func TestGetErrorCategoryDiff_NoTargetInvocations(t *testing.T) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if query.Qualifier == "2" {
+				return []types.MetricDataResult{{Values: []float64{0}}}, nil
+			}
+			return []types.MetricDataResult{{Values: []float64{10}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{}
+	now := time.Now()
+	baseline := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "1", StartTime: now.Add(-time.Hour), EndTime: now}
+	target := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "2", StartTime: now.Add(-time.Hour), EndTime: now}
+
+	_, err := metrics.GetErrorCategoryDiff(context.Background(), logs, cw, cache.NewCache(), baseline, target)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetErrorClassification_HappyPath(t *testing.T) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if metricName == "Errors" {
+				return []types.MetricDataResult{{Values: []float64{7}}}, nil
+			}
+			return []types.MetricDataResult{{Values: []float64{100}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "INIT_REPORT") {
+				return []map[string]string{{"initFailures": "2"}}, nil
+			}
+			return []map[string]string{
+				// Out of memory crashes also report a runtime exit, the more severe class wins.
+				{"@requestId": "a", "outOfMemoryLines": "1", "exitErrorLines": "1", "errorLogLines": "1"},
+				{"@requestId": "b", "exitErrorLines": "1"},
+				{"@requestId": "c", "timeoutLines": "1"},
+				{"@requestId": "d", "unhandledErrorLines": "1", "errorLogLines": "1"},
+				// e succeeded according to its platform report, f may be one of the handled errors.
+				{"@requestId": "e", "errorLogLines": "3", "successStatusLines": "1"},
+				{"@requestId": "f", "errorLogLines": "1"},
+				{"@requestId": "g", "errorLogLines": "1", "failedStatusLines": "1"},
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetErrorClassification(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+
+	require.Equal(t, 100, result.Invocations)
+	require.Equal(t, 7, result.FunctionErrors.Count)
+	require.Equal(t, 1, result.OutOfMemoryErrors.Count)
+	require.Equal(t, 1, result.RuntimeExitErrors.Count)
+	require.Equal(t, 1, result.SandboxTimeouts.Count)
+	require.Equal(t, 1, result.UnhandledErrors.Count)
+	require.Equal(t, 3, result.HandledErrors.Count)
+	require.Equal(t, 1, result.LoggedOnlyErrors.Count)
+	require.Equal(t, 2, result.InitFailures.Count)
+	require.InDelta(t, 0.01, result.LoggedOnlyErrors.Rate, 1e-9)
+	require.Empty(t, result.Warnings)
+	require.InDelta(t, 0.07, result.FunctionErrors.Rate, 1e-9)
+}
+
+func TestGetErrorClassification_HandledNeverNegative(t *testing.T) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if metricName == "Errors" {
+				return []types.MetricDataResult{{Values: []float64{0}}}, nil
+			}
+			return []types.MetricDataResult{{Values: []float64{10}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{
+		results: []map[string]string{
+			{"@requestId": "a", "timeoutLines": "1"},
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetErrorClassification(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.HandledErrors.Count)
+	require.Equal(t, 1, result.SandboxTimeouts.Count)
+}
+
+func TestGetErrorClassification_LoggedOnlyWithoutHandledErrors(t *testing.T) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if metricName == "Errors" {
+				return []types.MetricDataResult{{Values: []float64{1}}}, nil
+			}
+			return []types.MetricDataResult{{Values: []float64{100}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "INIT_REPORT") {
+				return []map[string]string{}, nil
+			}
+			// The text log format has no status, one of the three invocations is the handled error.
+			return []map[string]string{
+				{"@requestId": "a", "errorLogLines": "1"},
+				{"@requestId": "b", "errorLogLines": "1"},
+				{"@requestId": "c", "errorLogLines": "2"},
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetErrorClassification(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.HandledErrors.Count)
+	require.Equal(t, 2, result.LoggedOnlyErrors.Count)
+}
+
+func TestGetErrorClassification_TruncatedResults(t *testing.T) {
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{50000}}},
+	}
+	rows := make([]map[string]string, 10000)
+	for i := range rows {
+		rows[i] = map[string]string{"timeoutLines": "1"}
+	}
+	logs := &mockLogsFetcher{results: rows}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetErrorClassification(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "undercounted")
+}
+
+func TestGetErrorClassification_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{0}}},
+	}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetErrorClassification(context.Background(), logs, cw, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
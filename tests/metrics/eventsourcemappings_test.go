@@ -0,0 +1,300 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func timeoutLambdaClient(timeout int32) *mockLambdaClient {
+	return &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{
+					Timeout: aws.Int32(timeout),
+				},
+			}, nil
+		},
+	}
+}
+
+func TestGetEventSourceMappingStatistics_FallingBehindStream(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamps := []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour), start.Add(3 * time.Hour)}
+	cw := seriesFetcher(timestamps, map[string][]float64{
+		"Invocations":      {25, 25, 25, 25},
+		"Errors":           {1, 0, 0, 0},
+		"IteratorAge":      {1000, 2000, 3000, 4000},
+		"PolledEventCount": {50, 50, 50, 50},
+	})
+	esmClient := &mockEventSourceMappingClient{
+		mappings: []lambdatypes.EventSourceMappingConfiguration{
+			{
+				UUID:                           aws.String("uuid-1"),
+				EventSourceArn:                 aws.String("arn:aws:kinesis:us-east-1:123456789012:stream/orders"),
+				State:                          aws.String("Enabled"),
+				BatchSize:                      aws.Int32(100),
+				MaximumBatchingWindowInSeconds: aws.Int32(0),
+				ParallelizationFactor:          aws.Int32(1),
+			},
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "sequenceNumber") {
+				return []map[string]string{
+					{"@requestId": "req-1", "records": "3"},
+					{"@requestId": "req-2", "records": "1"},
+				}, nil
+			}
+			return []map[string]string{
+				reportRow(start.Add(time.Minute), "stream-a", "req-1", 2900, 128, 64, ""),
+				reportRow(start.Add(2*time.Minute), "stream-a", "req-2", 1000, 128, 64, ""),
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(4 * time.Hour),
+	}
+
+	result, err := metrics.GetEventSourceMappingStatistics(context.Background(), logs, cw, timeoutLambdaClient(3), esmClient, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 100.0, result.Invocations)
+	require.Equal(t, 1.0, result.FunctionErrors)
+	require.Equal(t, int32(3), result.TimeoutSeconds)
+	require.Equal(t, 2900.0, result.BatchDuration.Max)
+	require.Len(t, result.Mappings, 1)
+
+	mapping := result.Mappings[0]
+	require.Equal(t, sdktypes.EventSourceTypeKinesis, mapping.SourceType)
+	require.Equal(t, "IteratorAge", mapping.LagMetric)
+	require.Equal(t, 4000.0, *mapping.MaxLag)
+	require.Len(t, mapping.LagOverTime, 4)
+	require.InDelta(t, 1000.0, *mapping.LagGrowthPerHour, 1e-9)
+	require.True(t, mapping.FallingBehind)
+	require.Equal(t, 200.0, *mapping.PolledEvents)
+	require.Equal(t, 2.0, *mapping.RecordsPerInvocation)
+	require.Equal(t, 2, mapping.RecordsLoggedInvocations)
+
+	// Falling behind, batch duration close to the timeout, small batches and failures retrying whole batches.
+	require.Len(t, mapping.Warnings, 4)
+	require.Contains(t, mapping.Warnings[0], "parallelization factor (currently 1)")
+	require.Contains(t, mapping.Warnings[1], "max batch duration of 2900 ms")
+	require.Contains(t, mapping.Warnings[2], "without ReportBatchItemFailures")
+	require.Contains(t, mapping.Warnings[3], "batches hold 2.0 of up to 100 records")
+}
+
+func TestGetEventSourceMappingStatistics_MultipleStreamMappings(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamps := []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour)}
+	cw := seriesFetcher(timestamps, map[string][]float64{
+		"Invocations": {10, 10, 10},
+		"IteratorAge": {500, 500, 500},
+	})
+	esmClient := &mockEventSourceMappingClient{
+		mappings: []lambdatypes.EventSourceMappingConfiguration{
+			{
+				UUID:           aws.String("uuid-1"),
+				EventSourceArn: aws.String("arn:aws:kinesis:us-east-1:123456789012:stream/orders"),
+				State:          aws.String("Enabled"),
+				BatchSize:      aws.Int32(100),
+			},
+			{
+				UUID:           aws.String("uuid-2"),
+				EventSourceArn: aws.String("arn:aws:kinesis:us-east-1:123456789012:stream/payments"),
+				State:          aws.String("Enabled"),
+				BatchSize:      aws.Int32(100),
+			},
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "sequenceNumber") {
+				return nil, errors.New("records can not be attributed to one of several mappings")
+			}
+			return []map[string]string{reportRow(start.Add(time.Minute), "stream-a", "req-1", 100, 128, 64, "")}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(3 * time.Hour),
+	}
+
+	result, err := metrics.GetEventSourceMappingStatistics(context.Background(), logs, cw, timeoutLambdaClient(30), esmClient, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Len(t, result.Mappings, 2)
+	for _, mapping := range result.Mappings {
+		require.Nil(t, mapping.RecordsPerInvocation)
+		require.Len(t, mapping.Warnings, 1)
+		require.Contains(t, mapping.Warnings[0], "IteratorAge is reported per function")
+	}
+}
+
+func TestGetEventSourceMappingStatistics_UnloggedEventsAndMQ(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamps := []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour)}
+	cw := seriesFetcher(timestamps, map[string][]float64{
+		"Invocations":                   {10, 10, 10},
+		"Errors":                        {1, 1, 1},
+		"ApproximateAgeOfOldestMessage": {30, 30, 30},
+	})
+	esmClient := &mockEventSourceMappingClient{
+		mappings: []lambdatypes.EventSourceMappingConfiguration{
+			{
+				UUID:           aws.String("uuid-1"),
+				EventSourceArn: aws.String("arn:aws:sqs:us-east-1:123456789012:orders"),
+				State:          aws.String("Enabled"),
+				BatchSize:      aws.Int32(10),
+			},
+			{
+				UUID:           aws.String("uuid-2"),
+				EventSourceArn: aws.String("arn:aws:mq:us-east-1:123456789012:broker:orders:b-1234"),
+				State:          aws.String("Enabled"),
+				BatchSize:      aws.Int32(100),
+			},
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			// The function does not log the events it receives.
+			if strings.Contains(queryString, "receiptHandle") {
+				return []map[string]string{}, nil
+			}
+			return []map[string]string{reportRow(start.Add(time.Minute), "stream-a", "req-1", 100, 128, 64, "")}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(3 * time.Hour),
+	}
+
+	result, err := metrics.GetEventSourceMappingStatistics(context.Background(), logs, cw, timeoutLambdaClient(30), esmClient, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Len(t, result.Mappings, 2)
+
+	sqs := result.Mappings[0]
+	require.Nil(t, sqs.RecordsPerInvocation)
+	require.Equal(t, 0, sqs.RecordsLoggedInvocations)
+	require.Len(t, sqs.Warnings, 1)
+	require.Contains(t, sqs.Warnings[0], "without ReportBatchItemFailures")
+
+	// Amazon MQ does not support ReportBatchItemFailures.
+	mq := result.Mappings[1]
+	require.Equal(t, sdktypes.EventSourceTypeOther, mq.SourceType)
+	require.Empty(t, mq.Warnings)
+}
+
+func TestGetEventSourceMappingStatistics_PaginatedMappings(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamps := []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour)}
+	cw := seriesFetcher(timestamps, map[string][]float64{
+		"ApproximateAgeOfOldestMessage": {30, 30, 30},
+	})
+	esmClient := &mockEventSourceMappingClient{
+		mappings: []lambdatypes.EventSourceMappingConfiguration{
+			{
+				UUID:                  aws.String("uuid-1"),
+				EventSourceArn:        aws.String("arn:aws:sqs:us-east-1:123456789012:orders"),
+				State:                 aws.String("Enabled"),
+				BatchSize:             aws.Int32(10),
+				FunctionResponseTypes: []lambdatypes.FunctionResponseType{lambdatypes.FunctionResponseTypeReportBatchItemFailures},
+			},
+			{
+				UUID:                   aws.String("uuid-2"),
+				SelfManagedEventSource: &lambdatypes.SelfManagedEventSource{},
+				State:                  aws.String("Disabled"),
+				BatchSize:              aws.Int32(100),
+			},
+		},
+		pageSize: 1,
+	}
+	logs := &mockLogsFetcher{err: errors.New("logs must not be queried without invocations")}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(3 * time.Hour),
+	}
+
+	result, err := metrics.GetEventSourceMappingStatistics(context.Background(), logs, cw, timeoutLambdaClient(30), esmClient, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 0.0, result.Invocations)
+	require.Nil(t, result.BatchDuration)
+	require.Len(t, result.Mappings, 2)
+
+	sqs := result.Mappings[0]
+	require.Equal(t, sdktypes.EventSourceTypeSQS, sqs.SourceType)
+	require.Equal(t, "ApproximateAgeOfOldestMessage", sqs.LagMetric)
+	require.True(t, sqs.ReportsBatchItemFailures)
+	require.False(t, sqs.FallingBehind)
+	require.Nil(t, sqs.PolledEvents)
+	require.Nil(t, sqs.RecordsPerInvocation)
+	require.Empty(t, sqs.Warnings)
+
+	kafka := result.Mappings[1]
+	require.Equal(t, sdktypes.EventSourceTypeKafka, kafka.SourceType)
+	require.Equal(t, "OffsetLag", kafka.LagMetric)
+	require.Len(t, kafka.Warnings, 1)
+	require.Contains(t, kafka.Warnings[0], "mapping is Disabled")
+}
+
+func TestGetEventSourceMappingStatistics_NoMappings(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "v1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetEventSourceMappingStatistics(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, &mockLambdaClient{}, &mockEventSourceMappingClient{}, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Empty(t, result.Mappings)
+	require.Equal(t, "v1", result.Qualifier)
+}
+
+func TestGetEventSourceMappingStatistics_ListError(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+	esmClient := &mockEventSourceMappingClient{err: fmt.Errorf("access denied")}
+
+	_, err := metrics.GetEventSourceMappingStatistics(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, &mockLambdaClient{}, esmClient, cache.NewCache(), query)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to list event source mappings")
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// growingMetrics returns daily metrics from start on: invocations growing by 100 per day from 1000,
+// 1024 GB-seconds per day at 1024 MB, and a peak concurrency growing by one per day from 10.
+func growingMetrics(start time.Time, days int) *mockCWFetcher {
+	return &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			result := types.MetricDataResult{}
+			for i := 0; i < days; i++ {
+				value := 1000 + 100*float64(i)
+				switch metricName {
+				case "Duration":
+					value = 1024 * 1000
+				case "ConcurrentExecutions":
+					value = 10 + float64(i)
+				}
+				result.Timestamps = append(result.Timestamps, start.Add(time.Duration(i)*24*time.Hour))
+				result.Values = append(result.Values, value)
+			}
+			return []types.MetricDataResult{result}, nil
+		},
+	}
+}
+
+func forecastLambdaClient() *mockLambdaClient {
+	return &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{MemorySize: aws.Int32(1024)},
+			}, nil
+		},
+	}
+}
+
+func TestGetCapacityForecast(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// The partial last day is left out of the history.
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: start, EndTime: start.Add(45*24*time.Hour + 6*time.Hour)}
+	concurrency := &mockConcurrencyClient{
+		reserved:     aws.Int32(100),
+		accountLimit: &lambdatypes.AccountLimit{ConcurrentExecutions: 1000, UnreservedConcurrentExecutions: aws.Int32(900)},
+	}
+
+	result, err := metrics.GetCapacityForecast(context.Background(), growingMetrics(start, 45), forecastLambdaClient(), concurrency, query,
+		sdktypes.ForecastOptions{Horizon: 60 * 24 * time.Hour})
+
+	require.NoError(t, err)
+	require.Empty(t, result.Warnings)
+	require.Equal(t, start.Add(45*24*time.Hour), result.EndTime)
+	require.Len(t, result.Series["invocations"], 45)
+	require.InDelta(t, 1024, result.Series["gbSeconds"][0].Value, 1e-9)
+	require.Len(t, result.Forecasts, 4)
+	for _, forecast := range result.Forecasts {
+		require.Len(t, forecast.Forecast, 60)
+	}
+	require.Equal(t, "invocations", result.Forecasts[0].Metric)
+	require.InDelta(t, 100, result.Forecasts[0].Trend, 1e-9)
+
+	// The peak concurrency reaches the reserved concurrency of 100 after 90 days, on April 1st.
+	require.Len(t, result.Limits, 2)
+	require.Equal(t, "reserved", result.Limits[0].Limit)
+	require.InDelta(t, 54, result.Limits[0].PeakConcurrency, 1e-9)
+	require.InDelta(t, 0.54, result.Limits[0].Utilization, 1e-9)
+	require.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), *result.Limits[0].ExpectedAt)
+	require.Equal(t, "account", result.Limits[1].Limit)
+	require.Nil(t, result.Limits[1].ExpectedAt)
+
+	// March covers the days 59 to 89 of the history.
+	cost := result.NextMonthCost
+	require.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), cost.Month)
+	require.InDelta(t, 31*1000+100*(59+89)*31/2, cost.Invocations, 1e-6)
+	require.InDelta(t, 31*1024, cost.GBSeconds, 1e-6)
+	require.InDelta(t, cost.Invocations/1e6*0.20+cost.GBSeconds*0.0000166667, cost.Cost, 1e-9)
+	require.InDelta(t, cost.Cost, cost.CostUpper, 1e-6)
+}
+
+func TestGetCapacityForecast_HoltWintersFallback(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: start, EndTime: start.Add(10 * 24 * time.Hour)}
+
+	result, err := metrics.GetCapacityForecast(context.Background(), growingMetrics(start, 10), forecastLambdaClient(), &mockConcurrencyClient{}, query,
+		sdktypes.ForecastOptions{Model: sdktypes.ForecastModelHoltWinters})
+
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 4)
+	require.Contains(t, result.Warnings[0], "linear model")
+	require.Equal(t, sdktypes.ForecastModelLinear, result.Forecasts[0].Model)
+	require.Empty(t, result.Limits)
+}
+
+func TestGetCapacityForecast_UnalignedStartTime(t *testing.T) {
+	dayStart := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := dayStart.Add(13*time.Hour + 37*time.Minute)
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: start, EndTime: start.Add(10 * 24 * time.Hour)}
+	// The datapoints are a minute off the start of their days.
+	cw := growingMetrics(dayStart.Add(-time.Minute), 10)
+	fetchFunc := cw.fetchFunc
+	cw.fetchFunc = func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+		require.Equal(t, dayStart, query.StartTime)
+		return fetchFunc(query, metricName, stat)
+	}
+
+	result, err := metrics.GetCapacityForecast(context.Background(), cw, forecastLambdaClient(), &mockConcurrencyClient{}, query,
+		sdktypes.ForecastOptions{})
+
+	require.NoError(t, err)
+	require.Equal(t, dayStart, result.StartTime)
+	require.Equal(t, dayStart.Add(10*24*time.Hour), result.EndTime)
+	require.Len(t, result.Series["invocations"], 10)
+	for i, point := range result.Series["invocations"] {
+		require.Equal(t, dayStart.Add(time.Duration(i)*24*time.Hour), point.Timestamp)
+		require.InDelta(t, 1000+100*float64(i), point.Value, 1e-9)
+	}
+}
+
+func TestGetCapacityForecast_ShortTimeRange(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: start, EndTime: start.Add(36 * time.Hour)}
+
+	_, err := metrics.GetCapacityForecast(context.Background(), growingMetrics(start, 1), forecastLambdaClient(), &mockConcurrencyClient{}, query,
+		sdktypes.ForecastOptions{})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "two full days")
+}
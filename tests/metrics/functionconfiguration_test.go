@@ -16,6 +16,8 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -31,13 +33,14 @@ func TestGetFunctionConfiguration(t *testing.T) {
 		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
 			return &lambda.GetFunctionOutput{
 				Configuration: &types.FunctionConfiguration{
-					FunctionName: aws.String("my-lambda-fn"),
-					FunctionArn:  aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-lambda-fn"),
-					Version:      aws.String("1"),
-					MemorySize:   aws.Int32(512),
-					Timeout:      aws.Int32(15),
-					Runtime:      types.RuntimeGo1x,
-					LastModified: aws.String("2023-01-01T00:00:00.000+0000"),
+					FunctionName:  aws.String("my-lambda-fn"),
+					FunctionArn:   aws.String("arn:aws:lambda:us-east-1:123456789012:function:my-lambda-fn"),
+					Version:       aws.String("1"),
+					MemorySize:    aws.Int32(512),
+					Timeout:       aws.Int32(15),
+					Runtime:       types.RuntimeGo1x,
+					Architectures: []types.Architecture{types.ArchitectureArm64},
+					LastModified:  aws.String("2023-01-01T00:00:00.000+0000"),
 					Environment: &types.EnvironmentResponse{
 						Variables: map[string]string{
 							"ENV": "prod",
@@ -53,7 +56,7 @@ func TestGetFunctionConfiguration(t *testing.T) {
 		Qualifier:    "1",
 	}
 
-	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, query)
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, &mockProvisionedConcurrencyClient{}, &mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, query, sdktypes.RedactionPolicy{})
 	require.NoError(t, err)
 
 	require.Equal(t, "my-lambda-fn", result.FunctionName)
@@ -61,6 +64,7 @@ func TestGetFunctionConfiguration(t *testing.T) {
 	require.Equal(t, int32(512), *result.MemorySizeMB)
 	require.Equal(t, int32(15), *result.TimeoutSeconds)
 	require.Equal(t, "go1.x", result.Runtime)
+	require.Equal(t, []string{"arm64"}, result.Architectures)
 	require.Equal(t, "2023-01-01T00:00:00.000+0000", result.LastModified)
 	require.Equal(t, map[string]string{"ENV": "prod"}, result.EnvironmentVariables)
 }
@@ -90,7 +94,7 @@ func TestGetFunctionConfiguration_NoEnvVars(t *testing.T) {
 		Qualifier:    "1",
 	}
 
-	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, query)
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, &mockProvisionedConcurrencyClient{}, &mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, query, sdktypes.RedactionPolicy{})
 	require.NoError(t, err)
 	require.Empty(t, result.EnvironmentVariables)
 }
@@ -119,7 +123,7 @@ func TestGetFunctionConfiguration_MissingMemoryAndTimeout(t *testing.T) {
 		Qualifier:    "1",
 	}
 
-	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, query)
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, &mockProvisionedConcurrencyClient{}, &mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, query, sdktypes.RedactionPolicy{})
 	require.NoError(t, err)
 
 	require.Equal(t, "my-lambda-fn", result.FunctionName)
@@ -131,3 +135,204 @@ func TestGetFunctionConfiguration_MissingMemoryAndTimeout(t *testing.T) {
 	require.Equal(t, "2023-01-01T00:00:00.000+0000", result.LastModified)
 	require.Equal(t, map[string]string{"ENV": "prod"}, result.EnvironmentVariables)
 }
+
+func TestGetFunctionConfiguration_Snapshot(t *testing.T) {
+	mockLambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &types.FunctionConfiguration{
+					FunctionName:     aws.String("my-lambda-fn"),
+					Version:          aws.String("$LATEST"),
+					Handler:          aws.String("bootstrap"),
+					PackageType:      types.PackageTypeZip,
+					CodeSize:         2048,
+					EphemeralStorage: &types.EphemeralStorage{Size: aws.Int32(1024)},
+					Layers: []types.Layer{
+						{Arn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:otel:3"), CodeSize: 512},
+					},
+					VpcConfig: &types.VpcConfigResponse{
+						VpcId:            aws.String("vpc-1"),
+						SubnetIds:        []string{"subnet-b", "subnet-a"},
+						SecurityGroupIds: []string{"sg-1"},
+					},
+					SnapStart:        &types.SnapStartResponse{ApplyOn: types.SnapStartApplyOnNone, OptimizationStatus: types.SnapStartOptimizationStatusOff},
+					LoggingConfig:    &types.LoggingConfig{LogFormat: types.LogFormatJson, LogGroup: aws.String("/aws/lambda/my-lambda-fn")},
+					DeadLetterConfig: &types.DeadLetterConfig{TargetArn: aws.String("arn:aws:sqs:us-east-1:123456789012:dlq")},
+					TracingConfig:    &types.TracingConfigResponse{Mode: types.TracingModeActive},
+				},
+				Concurrency: &types.Concurrency{ReservedConcurrentExecutions: aws.Int32(50)},
+				Tags:        map[string]string{"team": "payments"},
+			}, nil
+		},
+	}
+	pcClient := &mockProvisionedConcurrencyClient{err: errors.New("provisioned concurrency must not be read for $LATEST")}
+	invokeConfigClient := &mockEventInvokeConfigClient{
+		output: &lambda.GetFunctionEventInvokeConfigOutput{
+			MaximumRetryAttempts: aws.Int32(0),
+			DestinationConfig: &types.DestinationConfig{
+				OnFailure: &types.OnFailure{Destination: aws.String("arn:aws:sqs:us-east-1:123456789012:failures")},
+			},
+		},
+	}
+	urlClient := &mockFunctionURLClient{
+		output: &lambda.GetFunctionUrlConfigOutput{
+			FunctionUrl: aws.String("https://abc.lambda-url.us-east-1.on.aws/"),
+			AuthType:    types.FunctionUrlAuthTypeAwsIam,
+			InvokeMode:  types.InvokeModeBuffered,
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-lambda-fn",
+		Qualifier:    "$LATEST",
+	}
+
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, pcClient, invokeConfigClient, urlClient, query, sdktypes.RedactionPolicy{})
+	require.NoError(t, err)
+	require.Equal(t, "bootstrap", result.Handler)
+	require.Equal(t, "Zip", result.PackageType)
+	require.Equal(t, int64(2048), result.CodeSizeBytes)
+	require.Equal(t, int32(1024), *result.EphemeralStorageMB)
+	require.Equal(t, []sdktypes.LayerConfiguration{{Arn: "arn:aws:lambda:us-east-1:123456789012:layer:otel:3", CodeSizeBytes: 512}}, result.Layers)
+	require.Equal(t, []string{"subnet-a", "subnet-b"}, result.VpcConfig.SubnetIDs)
+	require.Equal(t, int32(50), *result.ReservedConcurrency)
+	require.Nil(t, result.ProvisionedConcurrency)
+	require.Equal(t, "None", result.SnapStart.ApplyOn)
+	require.Equal(t, "JSON", result.LoggingConfig.LogFormat)
+	require.Equal(t, "arn:aws:sqs:us-east-1:123456789012:dlq", result.DeadLetterTargetArn)
+	require.Equal(t, int32(0), *result.AsyncInvokeConfig.MaximumRetryAttempts)
+	require.Equal(t, "arn:aws:sqs:us-east-1:123456789012:failures", result.AsyncInvokeConfig.OnFailureDestination)
+	require.Equal(t, "Active", result.TracingMode)
+	require.Equal(t, map[string]string{"team": "payments"}, result.Tags)
+	require.Equal(t, "AWS_IAM", result.FunctionURL.AuthType)
+
+	// Snapshots serialize to JSON and back without losing settings.
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var decoded sdktypes.BaseStatisticsReturn
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, *result, decoded)
+}
+
+func TestGetFunctionConfiguration_PublishedVersion(t *testing.T) {
+	mockLambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &types.FunctionConfiguration{
+					FunctionName: aws.String("my-lambda-fn"),
+					Version:      aws.String("3"),
+					VpcConfig:    &types.VpcConfigResponse{VpcId: aws.String("")},
+				},
+			}, nil
+		},
+	}
+	pcClient := &mockProvisionedConcurrencyClient{
+		output: &lambda.GetProvisionedConcurrencyConfigOutput{RequestedProvisionedConcurrentExecutions: aws.Int32(5)},
+	}
+	invokeConfigClient := &mockEventInvokeConfigClient{err: &types.ResourceNotFoundException{}}
+	urlClient := &mockFunctionURLClient{err: errors.New("function url must not be read for published versions")}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-lambda-fn",
+		Qualifier:    "3",
+	}
+
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, pcClient, invokeConfigClient, urlClient, query, sdktypes.RedactionPolicy{})
+	require.NoError(t, err)
+	require.Equal(t, int32(5), *result.ProvisionedConcurrency)
+	require.Nil(t, result.VpcConfig)
+	require.Nil(t, result.AsyncInvokeConfig)
+	require.Nil(t, result.FunctionURL)
+	require.Empty(t, result.Layers)
+	require.Empty(t, result.Tags)
+}
+
+func TestGetFunctionConfiguration_NoProvisionedConcurrency(t *testing.T) {
+	mockLambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &types.FunctionConfiguration{FunctionName: aws.String("my-lambda-fn"), Version: aws.String("1")},
+			}, nil
+		},
+	}
+	pcClient := &mockProvisionedConcurrencyClient{err: &types.ProvisionedConcurrencyConfigNotFoundException{}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-lambda-fn",
+		Qualifier:    "1",
+	}
+
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, pcClient, &mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, query, sdktypes.RedactionPolicy{})
+	require.NoError(t, err)
+	require.Nil(t, result.ProvisionedConcurrency)
+	require.Empty(t, result.Warnings)
+}
+
+func TestGetFunctionConfiguration_AccessDenied(t *testing.T) {
+	mockLambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &types.FunctionConfiguration{FunctionName: aws.String("my-lambda-fn")},
+				TagsError:     &types.TagsError{ErrorCode: aws.String("AccessDeniedException"), Message: aws.String("not authorized to perform lambda:ListTags")},
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-lambda-fn",
+		Qualifier:    "$LATEST",
+	}
+
+	result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, &mockProvisionedConcurrencyClient{},
+		&mockEventInvokeConfigClient{err: errors.New("access denied")}, &mockFunctionURLClient{err: errors.New("access denied")}, query, sdktypes.RedactionPolicy{})
+	require.NoError(t, err)
+	require.Nil(t, result.AsyncInvokeConfig)
+	require.Nil(t, result.FunctionURL)
+	require.Empty(t, result.Tags)
+	require.Len(t, result.Warnings, 3)
+	require.Contains(t, result.Warnings[0], "lambda:ListTags")
+	require.Contains(t, result.Warnings[1], "failed to get event invoke config")
+	require.Contains(t, result.Warnings[2], "failed to get function url config")
+}
+
+func TestGetFunctionConfiguration_RedactsSecrets(t *testing.T) {
+	mockLambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &types.FunctionConfiguration{
+					FunctionName: aws.String("my-lambda-fn"),
+					Environment: &types.EnvironmentResponse{
+						Variables: map[string]string{
+							"ENV":         "prod",
+							"DB_PASSWORD": "hunter2",
+						},
+					},
+				},
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-lambda-fn",
+		Qualifier:    "$LATEST",
+	}
+
+	tests := []struct {
+		name           string
+		policy         sdktypes.RedactionPolicy
+		wantPassword   string
+		wantVisibility sdktypes.EnvironmentVariableVisibility
+	}{
+		{name: "redacted by default", wantPassword: "[REDACTED]", wantVisibility: sdktypes.EnvironmentVariableRedacted},
+		{name: "hashed", policy: sdktypes.RedactionPolicy{HashSecrets: true, HashKey: []byte("test-key")},
+			wantPassword: "hmac-sha256:65f93b070e9be4bccdf648502c4984608c04a63d393603725a9a59aba8f2c14e", wantVisibility: sdktypes.EnvironmentVariableHashed},
+		{name: "revealed", policy: sdktypes.RedactionPolicy{RevealSecrets: true}, wantPassword: "hunter2", wantVisibility: sdktypes.EnvironmentVariableRevealed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := metrics.GetFunctionConfiguration(context.Background(), mockLambdaClient, &mockProvisionedConcurrencyClient{},
+				&mockEventInvokeConfigClient{}, &mockFunctionURLClient{}, query, tt.policy)
+			require.NoError(t, err)
+			require.Equal(t, "prod", result.EnvironmentVariables["ENV"])
+			require.Equal(t, sdktypes.EnvironmentVariableRevealed, result.EnvironmentVariableVisibility["ENV"])
+			require.Equal(t, tt.wantPassword, result.EnvironmentVariables["DB_PASSWORD"])
+			require.Equal(t, tt.wantVisibility, result.EnvironmentVariableVisibility["DB_PASSWORD"])
+		})
+	}
+}
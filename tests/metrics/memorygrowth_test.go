@@ -0,0 +1,138 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func memorySizeLambdaClient(memorySize int32) *mockLambdaClient {
+	return &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{
+					MemorySize: aws.Int32(memorySize),
+				},
+			}, nil
+		},
+	}
+}
+
+func TestGetMemoryGrowth_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []map[string]string
+	for i := 0; i < 6; i++ {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		// The leaking environment grows 10 MB per invocation.
+		rows = append(rows, reportRow(ts, "leaking", fmt.Sprintf("l-%d", i), 10, 128, float64(50+10*i), ""))
+		// The stable environment goes up and down.
+		rows = append(rows, reportRow(ts, "stable", fmt.Sprintf("s-%d", i), 10, 128, float64(60+i%2), ""))
+	}
+	// Environments with too few invocations are ignored.
+	rows = append(rows, reportRow(start, "short", "x", 10, 128, 60, ""))
+
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{13}}}}
+	logs := &mockLogsFetcher{results: rows}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetMemoryGrowth(context.Background(), logs, cw, memorySizeLambdaClient(256), cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 256.0, result.MemorySizeMB)
+	require.Len(t, result.Environments, 2)
+	require.Len(t, result.AffectedEnvironments, 1)
+
+	leaking := result.AffectedEnvironments[0]
+	require.Equal(t, "leaking", leaking.LogStream)
+	require.Equal(t, 6, leaking.Invocations)
+	require.True(t, leaking.MonotonicallyIncreasing)
+	require.InDelta(t, 10, leaking.SlopeMBPerInvocation, 1e-9)
+	require.InDelta(t, 1, leaking.RSquared, 1e-9)
+	require.NotNil(t, leaking.ProjectedInvocationsUntilOOM)
+	// (256 - 100) / 10
+	require.InDelta(t, 15.6, *leaking.ProjectedInvocationsUntilOOM, 1e-9)
+
+	stable := result.Environments[1]
+	require.Equal(t, "stable", stable.LogStream)
+	require.False(t, stable.MonotonicallyIncreasing)
+}
+
+func TestGetMemoryGrowth_PlateauNotFlagged(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var rows []map[string]string
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		// Both environments grow once after the first invocation and then plateau.
+		small, large := 104.0, 120.0
+		if i == 0 {
+			small, large = 100, 100
+		}
+		rows = append(rows, reportRow(ts, "small-step", fmt.Sprintf("s-%d", i), 10, 256, small, ""))
+		rows = append(rows, reportRow(ts, "large-step", fmt.Sprintf("l-%d", i), 10, 256, large, ""))
+	}
+
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{20}}}}
+	logs := &mockLogsFetcher{results: rows}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetMemoryGrowth(context.Background(), logs, cw, memorySizeLambdaClient(256), cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Len(t, result.Environments, 2)
+	require.Empty(t, result.AffectedEnvironments)
+	require.Empty(t, result.Warnings)
+	for _, env := range result.Environments {
+		require.Greater(t, env.SlopeMBPerInvocation, 0.0)
+		require.False(t, env.MonotonicallyIncreasing, env.LogStream)
+	}
+}
+
+func TestGetMemoryGrowth_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetMemoryGrowth(context.Background(), logs, cw, memorySizeLambdaClient(128), cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
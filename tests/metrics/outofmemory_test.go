@@ -0,0 +1,170 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetOutOfMemoryRate_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(48 * time.Hour)
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{10}}},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "signal: killed") {
+				return []map[string]string{
+					{"@requestId": "killed", "signalLines": "1"},
+					{"@requestId": "full", "signalLines": "1"},
+				}, nil
+			}
+			return []map[string]string{
+				reportRow(start.Add(10*time.Minute), "s1", "ok-1", 10, 128, 64, ""),
+				reportRow(start.Add(20*time.Minute), "s1", "ok-2", 10, 128, 96, ""),
+				reportRow(start.Add(2*time.Hour), "s1", "full", 10, 128, 128, "Status: error\tError Type: Runtime.ExitError"),
+				reportRow(start.Add(3*time.Hour), "s2", "oom", 10, 128, 120, "Status: error\tError Type: Runtime.OutOfMemory"),
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      end,
+	}
+
+	result, err := metrics.GetOutOfMemoryRate(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.OutOfMemoryCount)
+	require.InDelta(t, 0.3, result.OutOfMemoryRate, 1e-9)
+	require.Equal(t, []string{"full", "killed", "oom"}, result.RequestIDs)
+
+	require.Len(t, result.MemoryUsageOverTime, 3)
+	first := result.MemoryUsageOverTime[0]
+	require.Equal(t, start, first.Timestamp)
+	require.Equal(t, 2, first.Invocations)
+	require.InDelta(t, 0.75, first.MaxUsageRate, 1e-9)
+	require.InDelta(t, 0.625, first.MeanUsageRate, 1e-9)
+	require.InDelta(t, 1.0, result.MemoryUsageOverTime[1].MaxUsageRate, 1e-9)
+}
+
+func TestGetOutOfMemoryRate_BucketsAlignedToStartTime(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 7, 0, 0, time.UTC)
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{2}}},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "signal: killed") {
+				return []map[string]string{}, nil
+			}
+			// Both invocations are within the first hour after the start time.
+			return []map[string]string{
+				reportRow(start.Add(3*time.Minute), "s1", "a", 10, 128, 64, ""),
+				reportRow(start.Add(58*time.Minute), "s1", "b", 10, 128, 64, ""),
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(48 * time.Hour),
+	}
+
+	result, err := metrics.GetOutOfMemoryRate(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Len(t, result.MemoryUsageOverTime, 1)
+	require.Equal(t, start, result.MemoryUsageOverTime[0].Timestamp)
+	require.Equal(t, 2, result.MemoryUsageOverTime[0].Invocations)
+	require.Empty(t, result.Warnings)
+}
+
+func TestGetOutOfMemoryRate_TruncatedResults(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{20000}}},
+	}
+	reports := make([]map[string]string, 10000)
+	for i := range reports {
+		reports[i] = reportRow(start.Add(time.Duration(i)*time.Second), "s1", "req", 10, 128, 64, "")
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			if strings.Contains(queryString, "signal: killed") {
+				return []map[string]string{}, nil
+			}
+			return reports, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(24 * time.Hour),
+	}
+
+	result, err := metrics.GetOutOfMemoryRate(context.Background(), logs, cw, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "first 10000 REPORT lines")
+}
+
+func TestGetOutOfMemoryRate_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{0}}},
+	}
+	logs := &mockLogsFetcher{}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetOutOfMemoryRate(context.Background(), logs, cw, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
+
+func TestGetOutOfMemoryRate_QueryError(t *testing.T) {
+	cw := &mockCWFetcher{
+		results: []types.MetricDataResult{{Values: []float64{5}}},
+	}
+	logs := &mockLogsFetcher{err: errors.New("query failed")}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetOutOfMemoryRate(context.Background(), logs, cw, cache.NewCache(), query)
+	require.Error(t, err)
+}
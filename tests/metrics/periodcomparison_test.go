@@ -0,0 +1,224 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+var (
+	currentStart  = time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	previousStart = currentStart.Add(-7 * 24 * time.Hour)
+)
+
+// comparisonFetchers returns fetchers of two periods with 10000 invocations each, in which the errors and
+// durations doubled from the previous to the current period, and counts their calls per period start.
+func comparisonFetchers(calls map[time.Time]int) (*mockCWFetcher, *mockLogsFetcher) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			calls[query.StartTime]++
+			value := 0.0
+			switch metricName {
+			case "Invocations":
+				value = 10000
+			case "Errors":
+				value = 100
+				if query.StartTime.Equal(currentStart) {
+					value = 200
+				}
+			}
+			return []types.MetricDataResult{{Timestamps: []time.Time{query.StartTime}, Values: []float64{value}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			calls[fq.StartTime]++
+			switch {
+			case strings.Contains(queryString, "durationMs"):
+				rows := []map[string]string{}
+				for i := 0; i < 100; i++ {
+					duration := 100 + i
+					if fq.StartTime.Equal(currentStart) {
+						duration += 100
+					}
+					rows = append(rows, map[string]string{"durationMs": fmt.Sprint(duration)})
+				}
+				return rows, nil
+			case strings.Contains(queryString, "memoryUtilizationRatio"):
+				rows := []map[string]string{}
+				for i := 0; i < 100; i++ {
+					rows = append(rows, map[string]string{"memoryUtilizationRatio": fmt.Sprint(0.4 + float64(i%10)/100)})
+				}
+				return rows, nil
+			}
+			return []map[string]string{{"invocationsCount": "10000", "timeoutCount": "10", "totalInvocations": "10000", "coldStartLines": "100"}}, nil
+		},
+	}
+	return cw, logs
+}
+
+func newPeriodCache() *cache.ResultCache[map[sdktypes.ComparisonMetric]sdktypes.PeriodValue] {
+	return cache.NewResultCache[map[sdktypes.ComparisonMetric]sdktypes.PeriodValue]()
+}
+
+func TestGetPeriodComparison(t *testing.T) {
+	calls := map[time.Time]int{}
+	cw, logs := comparisonFetchers(calls)
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: currentStart, EndTime: currentStart.Add(7 * 24 * time.Hour)}
+
+	result, err := metrics.GetPeriodComparison(context.Background(), logs, cw, cache.NewCache(), newPeriodCache(), query,
+		sdktypes.PeriodComparisonOptions{}, query.EndTime.Add(2*time.Hour))
+
+	require.NoError(t, err)
+	require.Empty(t, result.Warnings)
+	require.Equal(t, previousStart, result.PreviousStartTime)
+	require.Equal(t, currentStart, result.PreviousEndTime)
+	require.Len(t, result.Comparisons, 11)
+	comparisons := map[sdktypes.ComparisonMetric]sdktypes.MetricComparison{}
+	for _, comparison := range result.Comparisons {
+		comparisons[comparison.Metric] = comparison
+	}
+
+	errorRate := comparisons[sdktypes.ComparisonMetricErrorRate]
+	require.InDelta(t, 0.02, errorRate.Current.Value, 1e-9)
+	require.InDelta(t, 0.01, errorRate.Previous.Value, 1e-9)
+	require.InDelta(t, 0.01, errorRate.AbsoluteDelta, 1e-9)
+	require.InDelta(t, 1, *errorRate.RelativeDelta, 1e-9)
+	require.Less(t, *errorRate.PValue, 1e-6)
+	require.True(t, errorRate.Significant)
+
+	meanDuration := comparisons[sdktypes.ComparisonMetricMeanDuration]
+	require.InDelta(t, 100, meanDuration.AbsoluteDelta, 1e-9)
+	require.NotNil(t, meanDuration.PValue)
+	require.True(t, meanDuration.Significant)
+
+	// Percentiles have no standard error and are only compared by their relative change.
+	p95Duration := comparisons[sdktypes.ComparisonMetricP95Duration]
+	require.Nil(t, p95Duration.PValue)
+	require.True(t, p95Duration.Significant)
+
+	for _, metric := range []sdktypes.ComparisonMetric{
+		sdktypes.ComparisonMetricInvocations,
+		sdktypes.ComparisonMetricThrottleRate,
+		sdktypes.ComparisonMetricTimeoutRate,
+		sdktypes.ComparisonMetricColdStartRate,
+		sdktypes.ComparisonMetricMeanMemoryUsageRate,
+		sdktypes.ComparisonMetricP95MemoryUsageRate,
+	} {
+		require.Equal(t, 0.0, comparisons[metric].AbsoluteDelta, metric)
+		require.False(t, comparisons[metric].Significant, metric)
+	}
+}
+
+func TestGetPeriodComparison_CachesCompletePeriods(t *testing.T) {
+	calls := map[time.Time]int{}
+	cw, logs := comparisonFetchers(calls)
+	periodCache := newPeriodCache()
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: currentStart, EndTime: currentStart.Add(7 * 24 * time.Hour)}
+	// The current period just ended and can still change, the previous one is complete.
+	now := query.EndTime
+
+	for i := 0; i < 2; i++ {
+		_, err := metrics.GetPeriodComparison(context.Background(), logs, cw, cache.NewCache(), periodCache, query,
+			sdktypes.PeriodComparisonOptions{Offset: sdktypes.ComparePreviousWeek}, now)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, 2*calls[previousStart], calls[currentStart])
+	cached, ok := periodCache.Get(cache.CacheKey{FunctionName: "my-fn", Qualifier: "$LATEST", Start: previousStart, End: currentStart})
+	require.True(t, ok)
+	require.Len(t, cached, 11)
+}
+
+func TestGetPeriodComparison_SelectedMetricsWithoutPreviousInvocations(t *testing.T) {
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			if query.StartTime.Before(currentStart) {
+				return []types.MetricDataResult{}, nil
+			}
+			return []types.MetricDataResult{{Timestamps: []time.Time{query.StartTime}, Values: []float64{100}}}, nil
+		},
+	}
+	logs := &mockLogsFetcher{err: errors.New("logs must not be queried")}
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: currentStart, EndTime: currentStart.Add(24 * time.Hour)}
+
+	result, err := metrics.GetPeriodComparison(context.Background(), logs, cw, cache.NewCache(), newPeriodCache(), query,
+		sdktypes.PeriodComparisonOptions{
+			Offset:  sdktypes.ComparePreviousDay,
+			Metrics: []sdktypes.ComparisonMetric{sdktypes.ComparisonMetricInvocations, sdktypes.ComparisonMetricErrorRate},
+		}, query.EndTime)
+
+	require.NoError(t, err)
+	require.Equal(t, currentStart.Add(-24*time.Hour), result.PreviousStartTime)
+	require.Len(t, result.Comparisons, 1)
+	require.Nil(t, result.Comparisons[0].RelativeDelta)
+	require.True(t, result.Comparisons[0].Significant)
+	require.Equal(t, []string{"errorRate: not available in the previous period"}, result.Warnings)
+}
+
+func TestGetPeriodComparison_PreviousLogsBeyondRetention(t *testing.T) {
+	calls := map[time.Time]int{}
+	cw, logs := comparisonFetchers(calls)
+	runQueryFunc := logs.runQueryFunc
+	logs.runQueryFunc = func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+		if fq.StartTime.Equal(previousStart) {
+			return nil, errors.New("log events are beyond the retention period")
+		}
+		return runQueryFunc(fq, queryString)
+	}
+	periodCache := newPeriodCache()
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: currentStart, EndTime: currentStart.Add(7 * 24 * time.Hour)}
+
+	result, err := metrics.GetPeriodComparison(context.Background(), logs, cw, cache.NewCache(), periodCache, query,
+		sdktypes.PeriodComparisonOptions{}, query.EndTime.Add(2*time.Hour))
+
+	require.NoError(t, err)
+	// Only the metrics from CloudWatch are compared.
+	require.Len(t, result.Comparisons, 3)
+	require.Len(t, result.Warnings, 4+8)
+	require.Contains(t, result.Warnings[0], "previous period: timeout rate could not be calculated")
+	require.Contains(t, result.Warnings[0], "beyond the retention period")
+	require.Contains(t, result.Warnings[4], "not available in the previous period")
+
+	// The previous period is not cached, as its values are incomplete.
+	_, ok := periodCache.Get(cache.CacheKey{FunctionName: "my-fn", Qualifier: "$LATEST", Start: previousStart, End: currentStart})
+	require.False(t, ok)
+}
+
+func TestGetPeriodComparison_InvalidOptions(t *testing.T) {
+	query := sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", StartTime: currentStart, EndTime: currentStart.Add(24 * time.Hour)}
+
+	for _, options := range []sdktypes.PeriodComparisonOptions{
+		{Offset: -time.Hour},
+		{SignificanceLevel: 1},
+		{Metrics: []sdktypes.ComparisonMetric{"p42DurationMs"}},
+	} {
+		_, err := metrics.GetPeriodComparison(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, cache.NewCache(), newPeriodCache(), query,
+			options, query.EndTime)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestGetProvisionedConcurrencyStatistics_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			switch {
+			case metricName == "Invocations":
+				return []types.MetricDataResult{{Values: []float64{100}}}, nil
+			case metricName == "ProvisionedConcurrencyUtilization" && stat == "Maximum":
+				return []types.MetricDataResult{{
+					Timestamps: []time.Time{start.Add(time.Hour), start},
+					Values:     []float64{0.9, 0.5},
+				}}, nil
+			case metricName == "ProvisionedConcurrencyUtilization" && stat == "Average":
+				return []types.MetricDataResult{{
+					Timestamps: []time.Time{start.Add(time.Hour), start},
+					Values:     []float64{0.5, 0.3},
+				}}, nil
+			case metricName == "ProvisionedConcurrencyInvocations":
+				return []types.MetricDataResult{{Values: []float64{80}}}, nil
+			case metricName == "ProvisionedConcurrencySpilloverInvocations":
+				return []types.MetricDataResult{{Values: []float64{20}}}, nil
+			}
+			return nil, errors.New("unexpected metric")
+		},
+	}
+	pcClient := &mockProvisionedConcurrencyClient{
+		output: &lambda.GetProvisionedConcurrencyConfigOutput{
+			RequestedProvisionedConcurrentExecutions: aws.Int32(10),
+			AllocatedProvisionedConcurrentExecutions: aws.Int32(10),
+			AvailableProvisionedConcurrentExecutions: aws.Int32(10),
+			Status:                                   lambdatypes.ProvisionedConcurrencyStatusEnumReady,
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    start,
+		EndTime:      start.Add(2 * time.Hour),
+	}
+
+	result, err := metrics.GetProvisionedConcurrencyStatistics(context.Background(), cw, pcClient, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.True(t, result.Configured)
+	require.Equal(t, int32(10), result.AllocatedConcurrency)
+	require.Equal(t, "READY", result.Status)
+	require.Equal(t, 0.9, result.MaxUtilization)
+	require.InDelta(t, 0.4, result.MeanUtilization, 1e-9)
+	require.Len(t, result.UtilizationOverTime, 2)
+	require.Equal(t, start, result.UtilizationOverTime[0].Timestamp)
+	require.Equal(t, 80.0, result.ProvisionedInvocations)
+	require.InDelta(t, 0.2, result.SpilloverRate, 1e-9)
+}
+
+func TestGetProvisionedConcurrencyStatistics_NotConfigured(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{5}}}}
+	pcClient := &mockProvisionedConcurrencyClient{
+		err: &lambdatypes.ProvisionedConcurrencyConfigNotFoundException{Message: aws.String("not found")},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	result, err := metrics.GetProvisionedConcurrencyStatistics(context.Background(), cw, pcClient, cache.NewCache(), query)
+	require.NoError(t, err)
+	require.False(t, result.Configured)
+	require.Empty(t, result.UtilizationOverTime)
+}
+
+func TestGetProvisionedConcurrencyStatistics_NoInvocations(t *testing.T) {
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+
+	_, err := metrics.GetProvisionedConcurrencyStatistics(context.Background(), cw, &mockProvisionedConcurrencyClient{}, cache.NewCache(), query)
+	var noInvErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvErr))
+}
+
+func TestGetProvisionedConcurrencyRecommendation_HappyPath(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	cold := "Init Duration: 1000.00 ms\t"
+	logs := &mockLogsFetcher{results: []map[string]string{
+		// Three overlapping cold starts and a single one later on.
+		reportRow(start.Add(10*time.Second), "a", "a-1", 9000, 1024, 64, cold),
+		reportRow(start.Add(12*time.Second), "b", "b-1", 9000, 1024, 64, cold),
+		reportRow(start.Add(13*time.Second), "c", "c-1", 9000, 1024, 64, cold),
+		reportRow(start.Add(time.Minute), "d", "d-1", 9000, 1024, 64, cold),
+	}}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{4}}}}
+	lambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdatypes.FunctionConfiguration{
+					MemorySize:    aws.Int32(1024),
+					Architectures: []lambdatypes.Architecture{lambdatypes.ArchitectureArm64},
+				},
+			}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+
+	result, err := metrics.GetProvisionedConcurrencyRecommendation(context.Background(), logs, cw, lambdaClient, cache.NewCache(), query, 0.75)
+	require.NoError(t, err)
+	require.Equal(t, 4, result.ColdStarts)
+	require.Equal(t, 3, result.PeakConcurrency)
+	require.Equal(t, 2, result.RecommendedConcurrency)
+	require.Equal(t, 3, result.EliminatedColdStarts)
+	require.Equal(t, "arm64", result.Architecture)
+
+	// 4 invocations billed with 9001 ms at 1 GB.
+	require.InDelta(t, 4*9.001*0.0000133334, result.OnDemandCost, 1e-12)
+	// 2 GB provisioned for an hour, 3 invocations served by it and one spilled over.
+	wantProvisioned := 2*3600*0.0000033334 + 3*9*0.0000077778 + 9.001*0.0000133334
+	require.InDelta(t, wantProvisioned, result.ProvisionedCost, 1e-12)
+	require.InDelta(t, result.OnDemandCost-result.ProvisionedCost, result.Savings, 1e-12)
+
+	result, err = metrics.GetProvisionedConcurrencyRecommendation(context.Background(), logs, cw, lambdaClient, cache.NewCache(), query, 1)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.RecommendedConcurrency)
+	require.Equal(t, 4, result.EliminatedColdStarts)
+}
+
+func TestGetProvisionedConcurrencyRecommendation_ScaledToInvocations(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []map[string]string{}
+	for i := 0; i < 10000; i++ {
+		rows = append(rows, reportRow(start.Add(time.Duration(i)*time.Second), "a", fmt.Sprintf("a-%d", i), 999, 1024, 64, ""))
+	}
+	logs := &mockLogsFetcher{results: rows}
+	// The REPORT lines only cover half of the invocations.
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{20000}}}}
+	lambdaClient := &mockLambdaClient{
+		GetFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{Configuration: &lambdatypes.FunctionConfiguration{MemorySize: aws.Int32(1024)}}, nil
+		},
+	}
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    start,
+		EndTime:      start.Add(6 * time.Hour),
+	}
+
+	result, err := metrics.GetProvisionedConcurrencyRecommendation(context.Background(), logs, cw, lambdaClient, cache.NewCache(), query, 0.75)
+	require.NoError(t, err)
+	require.Equal(t, 20000, result.Invocations)
+	require.Equal(t, 10000, result.SampledInvocations)
+	// 20000 invocations billed with 1000 ms at 1 GB.
+	require.InDelta(t, 20000*0.0000166667, result.OnDemandCost, 1e-9)
+	require.Len(t, result.Warnings, 1)
+	require.Contains(t, result.Warnings[0], "only the first 10000 REPORT lines are analyzed")
+}
+
+func TestGetProvisionedConcurrencyRecommendation_InvalidTarget(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "1",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+	_, err := metrics.GetProvisionedConcurrencyRecommendation(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, &mockLambdaClient{}, cache.NewCache(), query, 1.5)
+	require.Error(t, err)
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func sloQuery() sdktypes.FunctionQuery {
+	// The end is not aligned to 5 minutes and is truncated to 12:00.
+	return sdktypes.FunctionQuery{FunctionName: "my-fn", Qualifier: "$LATEST", EndTime: time.Date(2025, 6, 30, 12, 3, 0, 0, time.UTC)}
+}
+
+func TestGetSLOReport_Success(t *testing.T) {
+	end := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	cw := &mockCWFetcher{
+		fetchFunc: func(query sdktypes.FunctionQuery, metricName string, stat string) ([]types.MetricDataResult, error) {
+			require.Equal(t, end, query.EndTime)
+			require.Equal(t, end.Add(-24*time.Hour), query.StartTime)
+			if metricName == "Invocations" {
+				return []types.MetricDataResult{{
+					Timestamps: []time.Time{end.Add(-10 * time.Hour), end.Add(-5 * time.Minute)},
+					Values:     []float64{1000, 1000},
+				}}, nil
+			}
+			return []types.MetricDataResult{{
+				Timestamps: []time.Time{end.Add(-5 * time.Minute)},
+				Values:     []float64{100},
+			}}, nil
+		},
+	}
+	specs := []sdktypes.SLOSpec{{Name: "availability", Indicator: sdktypes.SLOIndicatorSuccess, Objective: 0.99, Window: 24 * time.Hour}}
+
+	report, err := metrics.GetSLOReport(context.Background(), &mockLogsFetcher{}, cw, sloQuery(), specs)
+
+	require.NoError(t, err)
+	require.Equal(t, end, report.EndTime)
+	require.Len(t, report.SLOs, 1)
+	status := report.SLOs[0]
+	require.Equal(t, 2000.0, status.TotalInvocations)
+	require.Equal(t, 1900.0, status.GoodInvocations)
+	require.False(t, status.Met)
+	// 10% errors in the last 5 minutes burn the budget 10 times faster than allowed in all alert windows,
+	// which exceeds the threshold of the slow burn alert but not of the fast burn alert.
+	require.InDelta(t, 10, *status.Alerts[0].ShortBurnRate, 1e-9)
+	require.False(t, status.Alerts[0].Firing)
+	require.True(t, status.Alerts[1].Firing)
+	require.Equal(t, 1, report.FiringAlerts)
+}
+
+func TestGetSLOReport_LatencyAndTimeouts(t *testing.T) {
+	queries := 0
+	logs := &mockLogsFetcher{
+		runQueryFunc: func(fq sdktypes.FunctionQuery, queryString string) ([]map[string]string, error) {
+			queries++
+			if strings.Contains(queryString, "slowInvocations") {
+				require.Contains(t, queryString, "coalesce(@duration, jsonDurationMs) > 800")
+				return []map[string]string{
+					{"bucket": "2025-06-30 11:55:00.000", "slowInvocations": "50"},
+				}, nil
+			}
+			return []map[string]string{
+				{"bucket": "2025-06-30 11:00:00.000", "invocations": "100", "timeouts": "0"},
+				{"bucket": "2025-06-30 11:55:00.000", "invocations": "100", "timeouts": "20"},
+			}, nil
+		},
+	}
+	specs := []sdktypes.SLOSpec{
+		{Name: "latency", Indicator: sdktypes.SLOIndicatorLatency, Objective: 0.99, LatencyThresholdMs: 800, Window: 7 * 24 * time.Hour},
+		{Name: "latency-strict", Indicator: sdktypes.SLOIndicatorLatency, Objective: 0.999, LatencyThresholdMs: 800, Window: 7 * 24 * time.Hour},
+		{Name: "timeouts", Indicator: sdktypes.SLOIndicatorNoTimeout, Objective: 0.999, Window: 7 * 24 * time.Hour},
+	}
+
+	report, err := metrics.GetSLOReport(context.Background(), logs, &mockCWFetcher{}, sloQuery(), specs)
+
+	require.NoError(t, err)
+	// Both latency specs share a threshold and are fetched once.
+	require.Equal(t, 3, queries)
+	require.Len(t, report.SLOs, 3)
+	require.Equal(t, 200.0, report.SLOs[0].TotalInvocations)
+	require.Equal(t, 150.0, report.SLOs[0].GoodInvocations)
+	require.Equal(t, 180.0, report.SLOs[2].GoodInvocations)
+	fast := report.SLOs[0].Alerts[0]
+	require.InDelta(t, 25, *fast.LongBurnRate, 1e-9)
+	require.InDelta(t, 50, *fast.ShortBurnRate, 1e-9)
+	require.True(t, fast.Firing)
+	require.Equal(t, 6, report.FiringAlerts)
+}
+
+func TestGetSLOReport_InvalidSpec(t *testing.T) {
+	specs := []sdktypes.SLOSpec{{Name: "latency", Indicator: sdktypes.SLOIndicatorLatency, Objective: 0.99}}
+
+	_, err := metrics.GetSLOReport(context.Background(), &mockLogsFetcher{}, &mockCWFetcher{}, sloQuery(), specs)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "latency threshold")
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/require"
+
+	sdkerrors "github.com/dominikhei/serverless-statistics/errors"
+	"github.com/dominikhei/serverless-statistics/internal/cache"
+	"github.com/dominikhei/serverless-statistics/internal/metrics"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+// durationRows returns a REPORT row per duration, and a timed out one per timeout.
+func durationRows(start time.Time, durations []float64, timeouts int, timeoutMs float64) []map[string]string {
+	rows := []map[string]string{}
+	for i, duration := range durations {
+		rows = append(rows, reportRow(start.Add(time.Duration(i)*time.Minute), "stream-a", fmt.Sprintf("req-%d", i), duration, 128, 64, ""))
+	}
+	for i := 0; i < timeouts; i++ {
+		rows = append(rows, reportRow(start.Add(time.Duration(len(durations)+i)*time.Minute), "stream-b", fmt.Sprintf("timeout-%d", i), timeoutMs, 128, 64, "Status: timeout"))
+	}
+	return rows
+}
+
+func TestGetTimeoutRecommendation_Assessments(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+	tests := []struct {
+		name                string
+		timeout             int32
+		durations           []float64
+		timeouts            int
+		wantAssessment      sdktypes.TimeoutAssessment
+		wantRecommended     int32
+		wantEstimatedRate   float64
+		wantSavedSeconds    float64
+		wantJustificationIn string
+	}{
+		{
+			name:                "too high with hung invocations",
+			timeout:             60,
+			durations:           []float64{800, 900, 1000, 1000},
+			timeouts:            1,
+			wantAssessment:      sdktypes.TimeoutAssessmentTooHigh,
+			wantRecommended:     2,
+			wantEstimatedRate:   0.2,
+			wantSavedSeconds:    58,
+			wantJustificationIn: "hung invocations are billed up to the timeout",
+		},
+		{
+			name:                "too close to the tail",
+			timeout:             3,
+			durations:           []float64{1000, 1500, 2500},
+			wantAssessment:      sdktypes.TimeoutAssessmentTooClose,
+			wantRecommended:     4,
+			wantEstimatedRate:   0,
+			wantJustificationIn: "83% of the timeout of 3 s",
+		},
+		{
+			name:                "appropriate",
+			timeout:             5,
+			durations:           []float64{1000, 1200},
+			wantAssessment:      sdktypes.TimeoutAssessmentAppropriate,
+			wantRecommended:     5,
+			wantEstimatedRate:   0,
+			wantJustificationIn: "leaves enough headroom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{float64(len(tt.durations) + tt.timeouts)}}}}
+			logs := &mockLogsFetcher{results: durationRows(start, tt.durations, tt.timeouts, float64(tt.timeout)*1000)}
+
+			result, err := metrics.GetTimeoutRecommendation(context.Background(), logs, cw, timeoutLambdaClient(tt.timeout), cache.NewCache(), query)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAssessment, result.Assessment)
+			require.Equal(t, tt.wantRecommended, result.RecommendedTimeoutSeconds)
+			require.InDelta(t, tt.wantEstimatedRate, result.EstimatedTimeoutRate, 1e-9)
+			require.Equal(t, tt.wantSavedSeconds, result.EstimatedBilledSecondsSaved)
+			require.Contains(t, result.Justification, tt.wantJustificationIn)
+			require.Equal(t, tt.durations[len(tt.durations)-1], result.MaxDurationMs)
+			require.Equal(t, tt.timeouts, result.TimedOutInvocations)
+		})
+	}
+}
+
+func TestGetTimeoutRecommendation_AllTimedOut(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    start,
+		EndTime:      start.Add(time.Hour),
+	}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{2}}}}
+	logs := &mockLogsFetcher{results: durationRows(start, nil, 2, 10000)}
+
+	result, err := metrics.GetTimeoutRecommendation(context.Background(), logs, cw, timeoutLambdaClient(10), cache.NewCache(), query)
+	require.NoError(t, err)
+	require.Equal(t, sdktypes.TimeoutAssessmentTooClose, result.Assessment)
+	require.Equal(t, int32(10), result.RecommendedTimeoutSeconds)
+	require.Equal(t, 1.0, result.TimeoutRate)
+	require.Equal(t, 1.0, result.EstimatedTimeoutRate)
+	require.Contains(t, result.Justification, "all 2 invocations timed out")
+}
+
+func TestGetTimeoutRecommendation_NoInvocations(t *testing.T) {
+	query := sdktypes.FunctionQuery{
+		FunctionName: "my-fn",
+		Qualifier:    "$LATEST",
+		StartTime:    time.Now().Add(-1 * time.Hour),
+		EndTime:      time.Now(),
+	}
+	cw := &mockCWFetcher{results: []types.MetricDataResult{{Values: []float64{0}}}}
+
+	_, err := metrics.GetTimeoutRecommendation(context.Background(), &mockLogsFetcher{}, cw, timeoutLambdaClient(3), cache.NewCache(), query)
+	var noInvocationsErr *sdkerrors.NoInvocationsError
+	require.True(t, errors.As(err, &noInvocationsErr))
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/redaction"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+func TestIsSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		value  string
+		policy sdktypes.RedactionPolicy
+		want   bool
+	}{
+		{name: "secret suffix", key: "CLIENT_SECRET", value: "x", want: true},
+		{name: "token suffix", key: "github_token", value: "x", want: true},
+		{name: "password", key: "PASSWORD", value: "hunter2", want: true},
+		{name: "secret word inside the name", key: "TOKENIZER_MODEL", value: "bert", want: false},
+		{name: "secret word at the start", key: "SECRETS_MANAGER_ENDPOINT", value: "https://example.com", want: false},
+		{name: "plain value", key: "STAGE", value: "prod", want: false},
+		{name: "url", key: "ENDPOINT", value: "https://example.com/api/v1/items", want: false},
+		{name: "random base64 value", key: "SIGNING", value: "q8ZP1vT3nR0xYk7WmB2sLdC9hJ4fUeA6", want: true},
+		{name: "random hex value", key: "CHECKSUM_SALT", value: "9f86d081884c7d659a2feaa0c55ad015", want: true},
+		{name: "repetitive value", key: "PADDING", value: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", want: false},
+		{name: "entropy detection disabled", key: "SIGNING", value: "q8ZP1vT3nR0xYk7WmB2sLdC9hJ4fUeA6",
+			policy: sdktypes.RedactionPolicy{DisableEntropyDetection: true}, want: false},
+		{name: "custom pattern", key: "DB_DSN", value: "x",
+			policy: sdktypes.RedactionPolicy{NamePatterns: []string{"*_dsn"}}, want: true},
+		{name: "allowlisted", key: "FEATURE_TOKEN", value: "x",
+			policy: sdktypes.RedactionPolicy{Allowlist: []string{"feature_token"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, redaction.IsSecret(tt.key, tt.value, tt.policy))
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	variables := map[string]string{"STAGE": "prod", "DB_PASSWORD": "hunter2"}
+
+	values, visibility := redaction.Redact(variables, sdktypes.RedactionPolicy{})
+	require.Equal(t, map[string]string{"STAGE": "prod", "DB_PASSWORD": "[REDACTED]"}, values)
+	require.Equal(t, sdktypes.EnvironmentVariableRevealed, visibility["STAGE"])
+	require.Equal(t, sdktypes.EnvironmentVariableRedacted, visibility["DB_PASSWORD"])
+
+	values, visibility = redaction.Redact(variables, sdktypes.RedactionPolicy{HashSecrets: true})
+	require.Equal(t, redaction.Hash("hunter2", nil), values["DB_PASSWORD"])
+	require.Regexp(t, "^hmac-sha256:[0-9a-f]{64}$", values["DB_PASSWORD"])
+	require.Equal(t, sdktypes.EnvironmentVariableHashed, visibility["DB_PASSWORD"])
+
+	values, _ = redaction.Redact(variables, sdktypes.RedactionPolicy{HashSecrets: true, HashKey: []byte("key")})
+	require.Equal(t, redaction.Hash("hunter2", []byte("key")), values["DB_PASSWORD"])
+	require.NotEqual(t, redaction.Hash("hunter2", nil), values["DB_PASSWORD"])
+
+	values, visibility = redaction.Redact(variables, sdktypes.RedactionPolicy{HashSecrets: true, RevealSecrets: true})
+	require.Equal(t, variables, values)
+	require.Equal(t, sdktypes.EnvironmentVariableRevealed, visibility["DB_PASSWORD"])
+}
+
+func TestEntropy(t *testing.T) {
+	require.Equal(t, 0.0, redaction.Entropy(""))
+	require.Equal(t, 0.0, redaction.Entropy("aaaa"))
+	require.InDelta(t, 2.0, redaction.Entropy("abcd"), 1e-9)
+}
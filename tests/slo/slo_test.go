@@ -0,0 +1,156 @@
+// Copyright 2025 dominikhei
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dominikhei/serverless-statistics/internal/slo"
+	sdktypes "github.com/dominikhei/serverless-statistics/types"
+)
+
+var end = time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+
+// steadyBuckets returns a bucket for every 5 minutes of the window before end with the given invocations,
+// and bad invocations in the buckets for which bad returns true.
+func steadyBuckets(window time.Duration, total, badPerBucket float64, bad func(start time.Time) bool) []slo.Bucket {
+	buckets := []slo.Bucket{}
+	for start := end.Add(-window); start.Before(end); start = start.Add(slo.BucketSize) {
+		bucket := slo.Bucket{Start: start, Good: total, Total: total}
+		if bad(start) {
+			bucket.Good -= badPerBucket
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+func availabilitySpec(t *testing.T) sdktypes.SLOSpec {
+	t.Helper()
+	spec, err := slo.Normalize(sdktypes.SLOSpec{Name: "availability", Indicator: sdktypes.SLOIndicatorSuccess, Objective: 0.999})
+	require.NoError(t, err)
+	return spec
+}
+
+func alertStatus(t *testing.T, status sdktypes.SLOStatus, name string) sdktypes.BurnRateAlertStatus {
+	t.Helper()
+	for _, alert := range status.Alerts {
+		if alert.Name == name {
+			return alert
+		}
+	}
+	t.Fatalf("no alert %s", name)
+	return sdktypes.BurnRateAlertStatus{}
+}
+
+func TestNormalize_Defaults(t *testing.T) {
+	spec := availabilitySpec(t)
+
+	require.Equal(t, slo.DefaultWindow, spec.Window)
+	require.Equal(t, slo.DefaultBurnRateAlerts(), spec.Alerts)
+}
+
+func TestNormalize_InvalidSpecs(t *testing.T) {
+	tests := []struct {
+		name string
+		spec sdktypes.SLOSpec
+	}{
+		{"unknown indicator", sdktypes.SLOSpec{Indicator: "throughput", Objective: 0.99}},
+		{"objective of 1", sdktypes.SLOSpec{Indicator: sdktypes.SLOIndicatorSuccess, Objective: 1}},
+		{"latency without threshold", sdktypes.SLOSpec{Indicator: sdktypes.SLOIndicatorLatency, Objective: 0.99}},
+		{"window too long", sdktypes.SLOSpec{Indicator: sdktypes.SLOIndicatorSuccess, Objective: 0.99, Window: 90 * 24 * time.Hour}},
+		{"alert longer than window", sdktypes.SLOSpec{Indicator: sdktypes.SLOIndicatorSuccess, Objective: 0.99, Window: 24 * time.Hour,
+			Alerts: []sdktypes.BurnRateAlert{{Name: "ticket", LongWindow: 3 * 24 * time.Hour, ShortWindow: 6 * time.Hour, Threshold: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := slo.Normalize(tt.spec)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEvaluate_ErrorBudget(t *testing.T) {
+	spec := availabilitySpec(t)
+	// 100 invocations every 5 minutes for 30 days, with 1 error every 5 minutes in the first 15 days.
+	buckets := steadyBuckets(spec.Window, 100, 1, func(start time.Time) bool {
+		return start.Before(end.Add(-15 * 24 * time.Hour))
+	})
+
+	status := slo.Evaluate(spec, buckets, end)
+
+	require.Equal(t, 864000.0, status.TotalInvocations)
+	require.Equal(t, 864000.0-4320, status.GoodInvocations)
+	require.NotNil(t, status.Attainment)
+	require.InDelta(t, 0.995, *status.Attainment, 1e-9)
+	require.False(t, status.Met)
+	require.InDelta(t, 864, status.ErrorBudget, 1e-6)
+	require.InDelta(t, -4, status.ErrorBudgetRemaining, 1e-9)
+	for _, alert := range status.Alerts {
+		require.False(t, alert.Firing, alert.Name)
+		require.Equal(t, 0.0, *alert.ShortBurnRate)
+	}
+}
+
+func TestEvaluate_FastBurnFires(t *testing.T) {
+	spec := availabilitySpec(t)
+	// 2% errors in the last hour burn the budget 20 times faster than allowed.
+	buckets := steadyBuckets(spec.Window, 100, 2, func(start time.Time) bool {
+		return !start.Before(end.Add(-time.Hour))
+	})
+
+	status := slo.Evaluate(spec, buckets, end)
+
+	fast := alertStatus(t, status, "fast-burn")
+	require.InDelta(t, 20, *fast.LongBurnRate, 1e-6)
+	require.InDelta(t, 20, *fast.ShortBurnRate, 1e-6)
+	require.True(t, fast.Firing)
+	slow := alertStatus(t, status, "slow-burn")
+	require.InDelta(t, 20.0/6, *slow.LongBurnRate, 1e-6)
+	require.False(t, slow.Firing)
+	require.True(t, status.Met)
+}
+
+func TestEvaluate_ShortWindowResetsAlert(t *testing.T) {
+	spec := availabilitySpec(t)
+	// The errors stopped 10 minutes ago, so the long window still burns but the short one does not.
+	buckets := steadyBuckets(spec.Window, 100, 5, func(start time.Time) bool {
+		return !start.Before(end.Add(-time.Hour)) && start.Before(end.Add(-10*time.Minute))
+	})
+
+	status := slo.Evaluate(spec, buckets, end)
+
+	fast := alertStatus(t, status, "fast-burn")
+	require.Greater(t, *fast.LongBurnRate, fast.Threshold)
+	require.Equal(t, 0.0, *fast.ShortBurnRate)
+	require.False(t, fast.Firing)
+}
+
+func TestEvaluate_NoInvocations(t *testing.T) {
+	spec := availabilitySpec(t)
+
+	status := slo.Evaluate(spec, []slo.Bucket{}, end)
+
+	require.Nil(t, status.Attainment)
+	require.True(t, status.Met)
+	require.Equal(t, 1.0, status.ErrorBudgetRemaining)
+	for _, alert := range status.Alerts {
+		require.Nil(t, alert.LongBurnRate)
+		require.False(t, alert.Firing)
+	}
+}
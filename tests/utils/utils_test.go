@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
@@ -493,3 +494,95 @@ func TestSumMetricValues(t *testing.T) {
 		})
 	}
 }
+
+func TestBucketSize(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		end  time.Time
+		n    int
+		want time.Duration
+	}{
+		{name: "full hours", end: start.Add(48 * time.Hour), n: 48, want: time.Hour},
+		{name: "rounded up to minutes", end: start.Add(time.Hour), n: 7, want: 9 * time.Minute},
+		{name: "minimum one minute", end: start.Add(10 * time.Minute), n: 100, want: time.Minute},
+		{name: "end before start", end: start.Add(-time.Hour), n: 10, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, utils.BucketSize(start, tt.end, tt.n))
+		})
+	}
+}
+
+func TestLinearRegression(t *testing.T) {
+	slope, intercept, rSquared, err := utils.LinearRegression([]float64{0, 1, 2, 3}, []float64{1, 3, 5, 7})
+	require.NoError(t, err)
+	require.InDelta(t, 2, slope, 1e-9)
+	require.InDelta(t, 1, intercept, 1e-9)
+	require.InDelta(t, 1, rSquared, 1e-9)
+
+	slope, _, rSquared, err = utils.LinearRegression([]float64{0, 1, 2}, []float64{4, 4, 4})
+	require.NoError(t, err)
+	require.Equal(t, 0.0, slope)
+	require.Equal(t, 1.0, rSquared)
+
+	_, _, _, err = utils.LinearRegression([]float64{1}, []float64{1})
+	require.Error(t, err)
+
+	_, _, _, err = utils.LinearRegression([]float64{1, 1}, []float64{1, 2})
+	require.Error(t, err)
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	require.Equal(t, 5.0, utils.Quantile(0.5, sorted))
+	require.Equal(t, 10.0, utils.Quantile(0.999, sorted))
+	require.Equal(t, 1.0, utils.Quantile(0, sorted))
+	require.Equal(t, 0.0, utils.Quantile(0.5, nil))
+}
+
+func TestParseLastModified(t *testing.T) {
+	ts, err := utils.ParseLastModified("2025-01-01T12:30:00.000+0200")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC), ts)
+
+	_, err = utils.ParseLastModified("yesterday")
+	require.Error(t, err)
+}
+
+func TestMedian(t *testing.T) {
+	vals := []float64{5, 1, 3}
+	require.Equal(t, 3.0, utils.Median(vals))
+	require.Equal(t, []float64{5, 1, 3}, vals)
+	require.Equal(t, 2.5, utils.Median([]float64{4, 1, 3, 2}))
+	require.Equal(t, 0.0, utils.Median(nil))
+}
+
+func TestMedianAbsoluteDeviation(t *testing.T) {
+	require.Equal(t, 1.0, utils.MedianAbsoluteDeviation([]float64{1, 2, 3, 4, 100}))
+	require.Equal(t, 0.0, utils.MedianAbsoluteDeviation([]float64{7, 7, 7}))
+}
+
+func TestMean(t *testing.T) {
+	require.Equal(t, 2.5, utils.Mean([]float64{1, 2, 3, 4}))
+	require.Equal(t, 0.0, utils.Mean(nil))
+}
+
+func TestNoiseFloor(t *testing.T) {
+	require.Equal(t, 5.0, utils.NoiseFloor(5, 100))
+	require.Equal(t, 1.0, utils.NoiseFloor(0, -100))
+	require.Equal(t, utils.MinSigma, utils.NoiseFloor(0, 0))
+}
+
+func TestBucketPeriod(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := []sdktypes.MetricPoint{
+		{Timestamp: start},
+		{Timestamp: start.Add(2 * time.Hour)},
+		{Timestamp: start.Add(3 * time.Hour)},
+	}
+	require.Equal(t, time.Hour, utils.BucketPeriod(series))
+	require.Equal(t, time.Duration(0), utils.BucketPeriod(series[:1]))
+}
@@ -17,8 +17,10 @@ package types
 import (
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/codedeploy"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 )
 
@@ -45,6 +47,27 @@ type AWSClients struct {
 	LambdaClient     *lambda.Client
 	CloudWatchClient *cloudwatch.Client
 	LogsClient       *cloudwatchlogs.Client
+	CloudTrailClient *cloudtrail.Client
+	CodeDeployClient *codedeploy.Client
+}
+
+// SummaryStatistics holds descriptive statistics of a set of values.
+// P95, P99 and Conf95 can be nil if not enough values are present to calculate them robustly.
+type SummaryStatistics struct {
+	Min    float64  `json:"min"`
+	Max    float64  `json:"max"`
+	Median float64  `json:"median"`
+	Mean   float64  `json:"mean"`
+	P95    *float64 `json:"p95,omitempty"`    // 95th percentile, requires at least 20 values
+	P99    *float64 `json:"p99,omitempty"`    // 99th percentile, requires at least 100 values
+	Conf95 *float64 `json:"conf95,omitempty"` // 95% confidence interval of the mean, requires at least 30 values
+	Count  int      `json:"count"`
+}
+
+// MetricPoint is a single value of a time series.
+type MetricPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
 }
 
 // ThrottleRateReturn is the return of GetThrottleRate.
@@ -91,16 +114,1030 @@ type MemoryUsagePercentilesReturn struct {
 	EndTime         time.Time `json:"endTime"`
 }
 
-// BaseStatisticsReturn contains general statistics on a lambda function.
+// MemoryUsagePoint holds the memory usage of the invocations that ended within one time bucket.
+type MemoryUsagePoint struct {
+	Timestamp     time.Time `json:"timestamp"`     // Start of the bucket
+	MaxUsageRate  float64   `json:"maxUsageRate"`  // Highest ratio of max memory used to memory size in the bucket
+	MeanUsageRate float64   `json:"meanUsageRate"` // Mean ratio of max memory used to memory size in the bucket
+	Invocations   int       `json:"invocations"`
+}
+
+// OutOfMemoryRateReturn is the return of GetOutOfMemoryRate.
+// MemoryUsageOverTime shows how close invocations get to the memory limit, which can reveal
+// leaks before invocations start to fail.
+type OutOfMemoryRateReturn struct {
+	OutOfMemoryCount    int                `json:"outOfMemoryCount"`
+	OutOfMemoryRate     float64            `json:"outOfMemoryRate"`
+	RequestIDs          []string           `json:"requestIds"` // Request IDs of the invocations that ran out of memory
+	MemoryUsageOverTime []MemoryUsagePoint `json:"memoryUsageOverTime"`
+	Warnings            []string           `json:"warnings"` // Set if the logs hold more invocations than are analyzed
+	FunctionName        string             `json:"functionName"`
+	Qualifier           string             `json:"qualifier"`
+	StartTime           time.Time          `json:"startTime"`
+	EndTime             time.Time          `json:"endTime"`
+}
+
+// EnvironmentMemoryGrowth describes how the max memory used developed over the invocations
+// served by a single execution environment (log stream).
+type EnvironmentMemoryGrowth struct {
+	LogStream                    string   `json:"logStream"`
+	Invocations                  int      `json:"invocations"`
+	FirstMaxMemoryUsedMB         float64  `json:"firstMaxMemoryUsedMb"`
+	LastMaxMemoryUsedMB          float64  `json:"lastMaxMemoryUsedMb"`
+	SlopeMBPerInvocation         float64  `json:"slopeMbPerInvocation"`                   // Growth of max memory used per invocation of a linear fit
+	RSquared                     float64  `json:"rSquared"`                               // Goodness of the linear fit
+	MonotonicallyIncreasing      bool     `json:"monotonicallyIncreasing"`                // Never decreases, fits a line with R² >= 0.8 and grows by at least 10%
+	ProjectedInvocationsUntilOOM *float64 `json:"projectedInvocationsUntilOom,omitempty"` // nil if memory does not grow
+}
+
+// MemoryGrowthReturn is the return of GetMemoryGrowth.
+// Environments contains every environment with enough invocations to fit the growth,
+// AffectedEnvironments only those whose memory increases steadily.
+type MemoryGrowthReturn struct {
+	MemorySizeMB         float64                   `json:"memorySizeMb"`
+	Environments         []EnvironmentMemoryGrowth `json:"environments"`
+	AffectedEnvironments []EnvironmentMemoryGrowth `json:"affectedEnvironments"`
+	Warnings             []string                  `json:"warnings"`
+	FunctionName         string                    `json:"functionName"`
+	Qualifier            string                    `json:"qualifier"`
+	StartTime            time.Time                 `json:"startTime"`
+	EndTime              time.Time                 `json:"endTime"`
+}
+
+// ColdStartCause is the reason an execution environment had to be initialized.
+type ColdStartCause string
+
+const (
+	ColdStartCauseDeployment       ColdStartCause = "deployment"        // First environments after a new version or code update
+	ColdStartCauseScaleOut         ColdStartCause = "scale-out"         // All existing environments were busy
+	ColdStartCauseIdleRecycle      ColdStartCause = "idle-recycle"      // The replaced environment served no requests for longer than the idle threshold
+	ColdStartCauseErrorReplacement ColdStartCause = "error-replacement" // The previous environment was torn down after a failed invocation
+	ColdStartCauseUnknown          ColdStartCause = "unknown"           // None of the above, e.g. routine recycling by the Lambda service
+)
+
+// ColdStartClassification holds the cause of a single cold start.
+type ColdStartClassification struct {
+	RequestID string         `json:"requestId"`
+	LogStream string         `json:"logStream"`
+	Timestamp time.Time      `json:"timestamp"` // Start of the init phase
+	Cause     ColdStartCause `json:"cause"`
+}
+
+// ColdStartCauseStatistics holds the number of cold starts of one cause and their
+// share of all cold starts.
+type ColdStartCauseStatistics struct {
+	Count int     `json:"count"`
+	Rate  float64 `json:"rate"`
+}
+
+// ColdStartCausesReturn is the return of GetColdStartCauses.
+type ColdStartCausesReturn struct {
+	ColdStarts       int                       `json:"coldStarts"`
+	Deployment       ColdStartCauseStatistics  `json:"deployment"`
+	ScaleOut         ColdStartCauseStatistics  `json:"scaleOut"`
+	IdleRecycle      ColdStartCauseStatistics  `json:"idleRecycle"`
+	ErrorReplacement ColdStartCauseStatistics  `json:"errorReplacement"`
+	Unknown          ColdStartCauseStatistics  `json:"unknown"`
+	Classifications  []ColdStartClassification `json:"classifications"`
+	Warnings         []string                  `json:"warnings"`
+	FunctionName     string                    `json:"functionName"`
+	Qualifier        string                    `json:"qualifier"`
+	StartTime        time.Time                 `json:"startTime"`
+	EndTime          time.Time                 `json:"endTime"`
+}
+
+// InitType is the way an execution environment was initialized, as reported by Lambda in initializationType.
+type InitType string
+
+const (
+	InitTypeOnDemand               InitType = "on-demand"
+	InitTypeProvisionedConcurrency InitType = "provisioned-concurrency"
+	InitTypeSnapStart              InitType = "snap-start"
+)
+
+// InitTypeStatistics holds the cold starts of the execution environments of one init type.
+type InitTypeStatistics struct {
+	Environments  int                `json:"environments"`
+	Invocations   int                `json:"invocations"`            // Invocations served by environments of this type
+	ColdStarts    int                `json:"coldStarts"`             // Invocations that waited for an init or restore
+	ColdStartRate float64            `json:"coldStartRate"`          // ColdStarts / Invocations
+	InitDuration  *SummaryStatistics `json:"initDuration,omitempty"` // Init or restore durations in ms
+}
+
+// ColdStartsByInitTypeReturn is the return of GetColdStartsByInitType.
+// Provisioned concurrency environments are initialized ahead of time, so their init
+// durations are not part of any invocation and they do not count as cold starts.
+type ColdStartsByInitTypeReturn struct {
+	OnDemand               InitTypeStatistics `json:"onDemand"`
+	ProvisionedConcurrency InitTypeStatistics `json:"provisionedConcurrency"`
+	SnapStart              InitTypeStatistics `json:"snapStart"`
+	UnknownEnvironments    int                `json:"unknownEnvironments"` // Environments initialized before the time window
+	FunctionName           string             `json:"functionName"`
+	Qualifier              string             `json:"qualifier"`
+	StartTime              time.Time          `json:"startTime"`
+	EndTime                time.Time          `json:"endTime"`
+}
+
+// EnvironmentLifecycle describes a single execution environment (log stream) as observed in the time window.
+type EnvironmentLifecycle struct {
+	LogStream         string    `json:"logStream"`
+	Invocations       int       `json:"invocations"`
+	FirstSeen         time.Time `json:"firstSeen"` // Start of the init phase or of the first invocation
+	LastSeen          time.Time `json:"lastSeen"`  // End of the last invocation
+	LifetimeSeconds   float64   `json:"lifetimeSeconds"`
+	InitDurationMs    float64   `json:"initDurationMs"`
+	BusyDurationMs    float64   `json:"busyDurationMs"`    // Sum of the durations of all invocations
+	InitTimeFraction  float64   `json:"initTimeFraction"`  // InitDurationMs / (InitDurationMs + BusyDurationMs)
+	MaxIdleGapSeconds float64   `json:"maxIdleGapSeconds"` // Longest time between two invocations
+}
+
+// EnvironmentCountPoint holds the number of execution environments that were alive in one time bucket.
+type EnvironmentCountPoint struct {
+	Timestamp    time.Time `json:"timestamp"` // Start of the bucket
+	Environments int       `json:"environments"`
+}
+
+// EnvironmentLifecycleReturn is the return of GetEnvironmentLifecycleStatistics.
+// Environments that were created before or recycled after the time window are cut at its borders,
+// so lifetimes and invocations per environment are lower bounds for those.
+type EnvironmentLifecycleReturn struct {
+	EnvironmentCount           int                     `json:"environmentCount"`
+	InvocationsPerEnvironment  *SummaryStatistics      `json:"invocationsPerEnvironment,omitempty"`
+	LifetimeSeconds            *SummaryStatistics      `json:"lifetimeSeconds,omitempty"`
+	IdleGapSeconds             *SummaryStatistics      `json:"idleGapSeconds,omitempty"` // Gaps between consecutive invocations of the same environment
+	InitTimeFraction           float64                 `json:"initTimeFraction"`         // Share of the time all environments spent in init instead of handling requests
+	ConcurrentEnvironments     []EnvironmentCountPoint `json:"concurrentEnvironments"`
+	PeakConcurrentEnvironments int                     `json:"peakConcurrentEnvironments"`
+	Environments               []EnvironmentLifecycle  `json:"environments"`
+	Warnings                   []string                `json:"warnings"`
+	FunctionName               string                  `json:"functionName"`
+	Qualifier                  string                  `json:"qualifier"`
+	StartTime                  time.Time               `json:"startTime"`
+	EndTime                    time.Time               `json:"endTime"`
+}
+
+// ProvisionedConcurrencyReturn is the return of GetProvisionedConcurrencyStatistics.
+// Utilization values are shares of the allocated provisioned concurrency between 0 and 1.
+type ProvisionedConcurrencyReturn struct {
+	Configured             bool          `json:"configured"` // False if the qualifier has no provisioned concurrency config
+	RequestedConcurrency   int32         `json:"requestedConcurrency"`
+	AllocatedConcurrency   int32         `json:"allocatedConcurrency"`
+	AvailableConcurrency   int32         `json:"availableConcurrency"`
+	Status                 string        `json:"status"` // IN_PROGRESS, READY or FAILED
+	MaxUtilization         float64       `json:"maxUtilization"`
+	MeanUtilization        float64       `json:"meanUtilization"`
+	UtilizationOverTime    []MetricPoint `json:"utilizationOverTime"` // Maximum utilization per period
+	Invocations            float64       `json:"invocations"`
+	ProvisionedInvocations float64       `json:"provisionedInvocations"` // Invocations served by provisioned concurrency
+	SpilloverInvocations   float64       `json:"spilloverInvocations"`   // Invocations served on-demand while provisioned concurrency was in use
+	SpilloverRate          float64       `json:"spilloverRate"`          // SpilloverInvocations / Invocations
+	FunctionName           string        `json:"functionName"`
+	Qualifier              string        `json:"qualifier"`
+	StartTime              time.Time     `json:"startTime"`
+	EndTime                time.Time     `json:"endTime"`
+}
+
+// ProvisionedConcurrencyRecommendationReturn is the return of GetProvisionedConcurrencyRecommendation.
+// Costs are estimates in USD for the time window, based on list prices.
+type ProvisionedConcurrencyRecommendationReturn struct {
+	TargetColdStartReduction float64   `json:"targetColdStartReduction"` // Share of cold starts to eliminate
+	RecommendedConcurrency   int       `json:"recommendedConcurrency"`
+	PeakConcurrency          int       `json:"peakConcurrency"`
+	ColdStarts               int       `json:"coldStarts"`
+	EliminatedColdStarts     int       `json:"eliminatedColdStarts"`
+	Invocations              int       `json:"invocations"`        // From the Invocations metric
+	SampledInvocations       int       `json:"sampledInvocations"` // Invocations with a REPORT line the concurrency and durations are taken from
+	MemorySizeMB             float64   `json:"memorySizeMb"`
+	Architecture             string    `json:"architecture"`
+	OnDemandCost             float64   `json:"onDemandCost"`    // Duration cost of all invocations without provisioned concurrency, scaled from the sampled ones
+	ProvisionedCost          float64   `json:"provisionedCost"` // Duration cost with the recommended provisioned concurrency
+	Savings                  float64   `json:"savings"`         // OnDemandCost - ProvisionedCost, negative if provisioned concurrency is more expensive
+	Warnings                 []string  `json:"warnings"`
+	FunctionName             string    `json:"functionName"`
+	Qualifier                string    `json:"qualifier"`
+	StartTime                time.Time `json:"startTime"`
+	EndTime                  time.Time `json:"endTime"`
+}
+
+// ThrottleBurst is a period in which invocations of a function were throttled.
+type ThrottleBurst struct {
+	Timestamp       time.Time `json:"timestamp"` // Start of the period
+	Throttles       float64   `json:"throttles"`
+	PeakConcurrency float64   `json:"peakConcurrency"` // Concurrency the limit applies to, i.e. of the function or of the unreserved pool
+	Saturated       bool      `json:"saturated"`       // PeakConcurrency reached at least 90% of the limit
+}
+
+// ConcurrencyProfileReturn is the return of GetConcurrencyProfile.
+type ConcurrencyProfileReturn struct {
+	ReservedConcurrency           *int32          `json:"reservedConcurrency,omitempty"` // Nil if the function uses the unreserved pool of the account
+	AccountConcurrencyLimit       int32           `json:"accountConcurrencyLimit"`
+	UnreservedConcurrencyLimit    int32           `json:"unreservedConcurrencyLimit"` // Account limit minus all reserved concurrency
+	ConcurrencyLimit              int32           `json:"concurrencyLimit"`           // Reserved concurrency if set, otherwise the unreserved limit
+	PeakConcurrency               float64         `json:"peakConcurrency"`
+	Headroom                      float64         `json:"headroom"`     // ConcurrencyLimit - PeakConcurrency
+	HeadroomRate                  float64         `json:"headroomRate"` // Headroom / ConcurrencyLimit
+	ConcurrencyOverTime           []MetricPoint   `json:"concurrencyOverTime"`
+	ConcurrencySource             string          `json:"concurrencySource"` // "cloudwatch" or "logs" if estimated from REPORT lines
+	UnreservedConcurrencyOverTime []MetricPoint   `json:"unreservedConcurrencyOverTime"`
+	Throttles                     float64         `json:"throttles"`
+	ThrottleBursts                []ThrottleBurst `json:"throttleBursts"`
+	SaturatedThrottleShare        float64         `json:"saturatedThrottleShare"` // Share of throttles that happened while the limit was saturated
+	FunctionName                  string          `json:"functionName"`
+	Qualifier                     string          `json:"qualifier"`
+	StartTime                     time.Time       `json:"startTime"`
+	EndTime                       time.Time       `json:"endTime"`
+}
+
+// AsyncInvocationStatisticsReturn is the return of GetAsyncInvocationStatistics.
+// Event ages are in milliseconds and nil if CloudWatch has no data for them.
+type AsyncInvocationStatisticsReturn struct {
+	EventsReceived              float64   `json:"eventsReceived"`
+	EventsDropped               float64   `json:"eventsDropped"`
+	DeadLetterErrors            float64   `json:"deadLetterErrors"`
+	DestinationDeliveryFailures float64   `json:"destinationDeliveryFailures"`
+	DropRate                    float64   `json:"dropRate"`            // EventsDropped / EventsReceived
+	DeliveryFailureRate         float64   `json:"deliveryFailureRate"` // (DeadLetterErrors + DestinationDeliveryFailures) / EventsReceived
+	P50EventAge                 *float64  `json:"p50EventAge,omitempty"`
+	P90EventAge                 *float64  `json:"p90EventAge,omitempty"`
+	P99EventAge                 *float64  `json:"p99EventAge,omitempty"`
+	MaxEventAge                 *float64  `json:"maxEventAge,omitempty"`
+	MaximumRetryAttempts        int32     `json:"maximumRetryAttempts"`
+	MaximumEventAgeSeconds      int32     `json:"maximumEventAgeSeconds"`
+	OnSuccessDestination        string    `json:"onSuccessDestination,omitempty"`
+	OnFailureDestination        string    `json:"onFailureDestination,omitempty"`
+	DeadLetterQueue             string    `json:"deadLetterQueue,omitempty"`
+	Warnings                    []string  `json:"warnings"`
+	FunctionName                string    `json:"functionName"`
+	Qualifier                   string    `json:"qualifier"`
+	StartTime                   time.Time `json:"startTime"`
+	EndTime                     time.Time `json:"endTime"`
+}
+
+// EventSourceType is the kind of source an event source mapping reads from.
+type EventSourceType string
+
+const (
+	EventSourceTypeSQS             EventSourceType = "sqs"
+	EventSourceTypeKinesis         EventSourceType = "kinesis"
+	EventSourceTypeDynamoDBStreams EventSourceType = "dynamodb-streams"
+	EventSourceTypeKafka           EventSourceType = "kafka" // Amazon MSK and self-managed Apache Kafka
+	EventSourceTypeOther           EventSourceType = "other"
+)
+
+// EventSourceMappingStatistics holds the settings, lag and warnings of a single event source mapping.
+// The lag is IteratorAge in milliseconds for streams, OffsetLag in records for Kafka and the
+// age of the oldest message in seconds for SQS. IteratorAge and OffsetLag are emitted per function,
+// so with several mappings of the same source type the lag is their maximum.
+// RecordsPerInvocation is counted in the events the function logs and assumes every invocation logs
+// its event once and in full. It is nil if no log line contains records or another mapping has the same source type.
+type EventSourceMappingStatistics struct {
+	UUID                         string          `json:"uuid"`
+	EventSourceArn               string          `json:"eventSourceArn,omitempty"`
+	SourceType                   EventSourceType `json:"sourceType"`
+	State                        string          `json:"state"`
+	LastProcessingResult         string          `json:"lastProcessingResult,omitempty"`
+	BatchSize                    int32           `json:"batchSize"`
+	MaximumBatchingWindowSeconds int32           `json:"maximumBatchingWindowSeconds"`
+	ParallelizationFactor        int32           `json:"parallelizationFactor,omitempty"` // Only for Kinesis and DynamoDB streams
+	ReportsBatchItemFailures     bool            `json:"reportsBatchItemFailures"`
+	LagMetric                    string          `json:"lagMetric,omitempty"`
+	LagUnit                      string          `json:"lagUnit,omitempty"`
+	MaxLag                       *float64        `json:"maxLag,omitempty"`
+	LagOverTime                  []MetricPoint   `json:"lagOverTime"`
+	LagGrowthPerHour             *float64        `json:"lagGrowthPerHour,omitempty"` // Slope of a linear fit through LagOverTime
+	FallingBehind                bool            `json:"fallingBehind"`
+	PolledEvents                 *float64        `json:"polledEvents,omitempty"`         // Requires the EventCount metrics of the mapping to be enabled
+	RecordsPerInvocation         *float64        `json:"recordsPerInvocation,omitempty"` // Average over RecordsLoggedInvocations
+	RecordsLoggedInvocations     int             `json:"recordsLoggedInvocations"`       // Invocations whose log lines contain records
+	Warnings                     []string        `json:"warnings"`
+}
+
+// EventSourceMappingStatisticsReturn is the return of GetEventSourceMappingStatistics.
+// Every invocation of a function with event source mappings processes one batch,
+// so BatchDuration is the duration of the invocations in milliseconds.
+type EventSourceMappingStatisticsReturn struct {
+	Mappings       []EventSourceMappingStatistics `json:"mappings"`
+	Invocations    float64                        `json:"invocations"`
+	FunctionErrors float64                        `json:"functionErrors"`
+	BatchDuration  *SummaryStatistics             `json:"batchDuration,omitempty"`
+	TimeoutSeconds int32                          `json:"timeoutSeconds"`
+	FunctionName   string                         `json:"functionName"`
+	Qualifier      string                         `json:"qualifier"`
+	StartTime      time.Time                      `json:"startTime"`
+	EndTime        time.Time                      `json:"endTime"`
+}
+
+// TimeoutAssessment rates the configured timeout of a function against its observed durations.
+type TimeoutAssessment string
+
+const (
+	TimeoutAssessmentTooHigh     TimeoutAssessment = "too-high"    // Hung invocations are billed far longer than any invocation needs
+	TimeoutAssessmentTooClose    TimeoutAssessment = "too-close"   // The duration tail reaches the timeout, slow invocations time out
+	TimeoutAssessmentAppropriate TimeoutAssessment = "appropriate" // The timeout leaves headroom above the tail without being excessive
+)
+
+// TimeoutRecommendationReturn is the return of GetTimeoutRecommendation.
+// Durations are in milliseconds and only include invocations that did not time out.
+type TimeoutRecommendationReturn struct {
+	TimeoutSeconds              int32             `json:"timeoutSeconds"`
+	Invocations                 int               `json:"invocations"`
+	TimedOutInvocations         int               `json:"timedOutInvocations"`
+	TimeoutRate                 float64           `json:"timeoutRate"`
+	P99DurationMs               float64           `json:"p99DurationMs"`
+	P999DurationMs              float64           `json:"p999DurationMs"`
+	MaxDurationMs               float64           `json:"maxDurationMs"`
+	Assessment                  TimeoutAssessment `json:"assessment"`
+	RecommendedTimeoutSeconds   int32             `json:"recommendedTimeoutSeconds"`
+	EstimatedTimeoutRate        float64           `json:"estimatedTimeoutRate"`        // Timeout rate under the recommended timeout
+	EstimatedBilledSecondsSaved float64           `json:"estimatedBilledSecondsSaved"` // Billed seconds of timed out invocations saved by a lower timeout
+	Justification               string            `json:"justification"`
+	FunctionName                string            `json:"functionName"`
+	Qualifier                   string            `json:"qualifier"`
+	StartTime                   time.Time         `json:"startTime"`
+	EndTime                     time.Time         `json:"endTime"`
+}
+
+// ArchitectureMigrationEstimateReturn is the return of GetArchitectureMigrationEstimate.
+// Costs are in USD at us-east-1 list prices for the invocations observed in the time range.
+type ArchitectureMigrationEstimateReturn struct {
+	Architecture       string    `json:"architecture"`
+	TargetArchitecture string    `json:"targetArchitecture"`
+	PerformanceRatio   float64   `json:"performanceRatio"`          // Duration on the target architecture relative to the current one
+	MeasuredVersion    string    `json:"measuredVersion,omitempty"` // Version on the target architecture the ratio was measured with, empty if configured
+	Invocations        int       `json:"invocations"`               // From the Invocations metric
+	SampledInvocations int       `json:"sampledInvocations"`        // Invocations with a REPORT line the durations are taken from
+	MemorySizeMB       float64   `json:"memorySizeMb"`
+	BilledDurationMs   float64   `json:"billedDurationMs"` // Total billed duration of the invocations, scaled from the sampled ones
+	CurrentCost        float64   `json:"currentCost"`
+	ProjectedCost      float64   `json:"projectedCost"` // Cost of the same invocations on the target architecture
+	Savings            float64   `json:"savings"`       // CurrentCost - ProjectedCost, negative if the target architecture is more expensive
+	SavingsRate        float64   `json:"savingsRate"`   // Savings / CurrentCost
+	FunctionName       string    `json:"functionName"`
+	Qualifier          string    `json:"qualifier"`
+	StartTime          time.Time `json:"startTime"`
+	EndTime            time.Time `json:"endTime"`
+}
+
+// ArchitectureMigrationRankingReturn is the return of GetArchitectureMigrationRanking.
+// Candidates are the x86_64 functions with invocations, ranked by descending savings of a migration to arm64.
+type ArchitectureMigrationRankingReturn struct {
+	Candidates []ArchitectureMigrationEstimateReturn `json:"candidates"`
+	Warnings   []string                              `json:"warnings"` // Functions that could not be estimated
+	StartTime  time.Time                             `json:"startTime"`
+	EndTime    time.Time                             `json:"endTime"`
+}
+
+// BaseStatisticsReturn is a snapshot of the configuration of a lambda function.
+// Settings that are not configured are nil or empty, so two snapshots can be compared field by field.
 type BaseStatisticsReturn struct {
-	FunctionARN          string            `json:"functionArn"`
-	FunctionName         string            `json:"functionName"`
-	Qualifier            string            `json:"qualifier"`
-	MemorySizeMB         *int32            `json:"memorySizeMb,omitempty"`
-	TimeoutSeconds       *int32            `json:"timeoutSeconds,omitempty"`
-	Runtime              string            `json:"runtime"`
-	LastModified         string            `json:"lastModified"`
-	EnvironmentVariables map[string]string `json:"environmentVariables"`
+	FunctionARN                   string                                   `json:"functionArn"`
+	FunctionName                  string                                   `json:"functionName"`
+	Qualifier                     string                                   `json:"qualifier"`
+	MemorySizeMB                  *int32                                   `json:"memorySizeMb,omitempty"`
+	TimeoutSeconds                *int32                                   `json:"timeoutSeconds,omitempty"`
+	Runtime                       string                                   `json:"runtime"`
+	Architectures                 []string                                 `json:"architectures"`
+	LastModified                  string                                   `json:"lastModified"`
+	EnvironmentVariables          map[string]string                        `json:"environmentVariables"` // Values of secrets are redacted or hashed unless revealed
+	EnvironmentVariableVisibility map[string]EnvironmentVariableVisibility `json:"environmentVariableVisibility"`
+	Handler                       string                                   `json:"handler,omitempty"`
+	Role                          string                                   `json:"role,omitempty"`
+	PackageType                   string                                   `json:"packageType,omitempty"` // Zip or Image
+	CodeSizeBytes                 int64                                    `json:"codeSizeBytes"`
+	EphemeralStorageMB            *int32                                   `json:"ephemeralStorageMb,omitempty"`
+	Layers                        []LayerConfiguration                     `json:"layers"`
+	VpcConfig                     *VpcConfiguration                        `json:"vpcConfig,omitempty"`
+	ReservedConcurrency           *int32                                   `json:"reservedConcurrency,omitempty"`
+	ProvisionedConcurrency        *int32                                   `json:"provisionedConcurrency,omitempty"` // Requested provisioned concurrency, only for published versions
+	SnapStart                     *SnapStartConfiguration                  `json:"snapStart,omitempty"`
+	LoggingConfig                 *LoggingConfiguration                    `json:"loggingConfig,omitempty"`
+	DeadLetterTargetArn           string                                   `json:"deadLetterTargetArn,omitempty"`
+	AsyncInvokeConfig             *AsyncInvokeConfiguration                `json:"asyncInvokeConfig,omitempty"`
+	TracingMode                   string                                   `json:"tracingMode,omitempty"` // Active or PassThrough
+	Tags                          map[string]string                        `json:"tags"`
+	FunctionURL                   *FunctionURLConfiguration                `json:"functionUrl,omitempty"` // Only for $LATEST
+	Warnings                      []string                                 `json:"warnings"`              // Settings that could not be read, e.g. for missing permissions
+}
+
+// EnvironmentVariableVisibility is how the value of an environment variable is reported.
+type EnvironmentVariableVisibility string
+
+const (
+	EnvironmentVariableRevealed EnvironmentVariableVisibility = "revealed" // The plain value, for variables that are no secrets or if secrets are revealed
+	EnvironmentVariableRedacted EnvironmentVariableVisibility = "redacted" // The value is replaced by [REDACTED]
+	EnvironmentVariableHashed   EnvironmentVariableVisibility = "hashed"   // The value is replaced by its HMAC-SHA256 hash, so changes remain visible
+)
+
+// RedactionPolicy decides which environment variables are secrets and how their values are reported.
+// The zero value redacts variables whose names match the default patterns, e.g. "*_SECRET", "*_TOKEN"
+// or "PASSWORD", and variables whose values look random like keys or tokens.
+type RedactionPolicy struct {
+	NamePatterns            []string // Glob patterns of secret names in addition to the defaults, e.g. "*_SECRET", matched case-insensitively
+	Allowlist               []string // Names of variables that are never treated as secrets
+	DisableEntropyDetection bool     // Only treat variables matching a name pattern as secrets
+	HashSecrets             bool     // Report secrets as HMAC-SHA256 hashes instead of redacting them
+	HashKey                 []byte   // (Optional) Key of the hashes, a random key of the process if empty. Set it to compare hashes across processes
+	RevealSecrets           bool     // Report secrets in plain text, requires an explicit opt-in
+}
+
+// LayerConfiguration is a layer used by a function.
+type LayerConfiguration struct {
+	Arn           string `json:"arn"`
+	CodeSizeBytes int64  `json:"codeSizeBytes"`
+}
+
+// VpcConfiguration is the VPC a function is connected to. Subnets and security groups are sorted.
+type VpcConfiguration struct {
+	VpcID                   string   `json:"vpcId"`
+	SubnetIDs               []string `json:"subnetIds"`
+	SecurityGroupIDs        []string `json:"securityGroupIds"`
+	Ipv6AllowedForDualStack bool     `json:"ipv6AllowedForDualStack"`
+}
+
+// SnapStartConfiguration is the SnapStart setting of a function.
+type SnapStartConfiguration struct {
+	ApplyOn            string `json:"applyOn"`            // PublishedVersions or None
+	OptimizationStatus string `json:"optimizationStatus"` // On or Off
+}
+
+// LoggingConfiguration is the log format, log levels and log group of a function.
+type LoggingConfiguration struct {
+	LogFormat           string `json:"logFormat"` // Text or JSON
+	ApplicationLogLevel string `json:"applicationLogLevel,omitempty"`
+	SystemLogLevel      string `json:"systemLogLevel,omitempty"`
+	LogGroup            string `json:"logGroup"`
+}
+
+// AsyncInvokeConfiguration is the event invoke config of a function for asynchronous invocations.
+type AsyncInvokeConfiguration struct {
+	MaximumRetryAttempts   *int32 `json:"maximumRetryAttempts,omitempty"`
+	MaximumEventAgeSeconds *int32 `json:"maximumEventAgeSeconds,omitempty"`
+	OnSuccessDestination   string `json:"onSuccessDestination,omitempty"`
+	OnFailureDestination   string `json:"onFailureDestination,omitempty"`
+}
+
+// FunctionURLConfiguration is the function URL of a function.
+type FunctionURLConfiguration struct {
+	URL        string `json:"url"`
+	AuthType   string `json:"authType"`   // AWS_IAM or NONE
+	InvokeMode string `json:"invokeMode"` // BUFFERED or RESPONSE_STREAM
+}
+
+// ConfigurationChangeKind is the kind of a change between two configuration snapshots.
+type ConfigurationChangeKind string
+
+const (
+	ConfigurationChangeAdded    ConfigurationChangeKind = "added"    // The setting is only present in the target
+	ConfigurationChangeRemoved  ConfigurationChangeKind = "removed"  // The setting is only present in the baseline
+	ConfigurationChangeModified ConfigurationChangeKind = "modified" // The setting is present in both with different values
+)
+
+// ConfigurationChange is a setting that differs between two configuration snapshots.
+// Values of environment variables are never reported, only their names in the field.
+type ConfigurationChange struct {
+	Field    string                  `json:"field"` // e.g. "memorySizeMb", "environmentVariables.DB_HOST" or "layers.arn:aws:lambda:eu-west-1:123456789012:layer:deps"
+	Kind     ConfigurationChangeKind `json:"kind"`
+	Baseline string                  `json:"baseline,omitempty"`
+	Target   string                  `json:"target,omitempty"`
+}
+
+// MetricWindowStatistics holds the CloudWatch metrics of a function qualifier in a time window.
+type MetricWindowStatistics struct {
+	Invocations    int       `json:"invocations"`
+	Errors         int       `json:"errors"`
+	Throttles      int       `json:"throttles"`
+	ErrorRate      float64   `json:"errorRate"`
+	ThrottleRate   float64   `json:"throttleRate"`
+	MeanDurationMs float64   `json:"meanDurationMs"`
+	Qualifier      string    `json:"qualifier"`
+	StartTime      time.Time `json:"startTime"`
+	EndTime        time.Time `json:"endTime"`
+}
+
+// MetricShift compares the metrics in the windows before and after a change.
+type MetricShift struct {
+	ChangeTime                 time.Time              `json:"changeTime"`
+	Before                     MetricWindowStatistics `json:"before"`
+	After                      MetricWindowStatistics `json:"after"`
+	ErrorRateChange            float64                `json:"errorRateChange"`    // After.ErrorRate - Before.ErrorRate
+	ThrottleRateChange         float64                `json:"throttleRateChange"` // After.ThrottleRate - Before.ThrottleRate
+	MeanDurationChangeMs       float64                `json:"meanDurationChangeMs"`
+	RelativeMeanDurationChange *float64               `json:"relativeMeanDurationChange,omitempty"` // MeanDurationChangeMs / Before.MeanDurationMs, nil without invocations before
+}
+
+// ConfigurationDriftReturn is the return of GetConfigurationDrift and CompareConfigurationSnapshots.
+// All changes between two snapshots took effect at the last modification of the target, which is
+// the change time of the metric shift.
+type ConfigurationDriftReturn struct {
+	Changes              []ConfigurationChange `json:"changes"`
+	HasDrift             bool                  `json:"hasDrift"`
+	MetricShift          *MetricShift          `json:"metricShift,omitempty"` // nil if no window is given or the target has no last modification date
+	Warnings             []string              `json:"warnings"`
+	FunctionName         string                `json:"functionName"`
+	BaselineQualifier    string                `json:"baselineQualifier"`
+	BaselineLastModified string                `json:"baselineLastModified"`
+	TargetQualifier      string                `json:"targetQualifier"`
+	TargetLastModified   string                `json:"targetLastModified"`
+}
+
+// DeploymentSource is where a deployment of a function was read from.
+type DeploymentSource string
+
+const (
+	DeploymentSourceCloudTrail   DeploymentSource = "cloudtrail"    // An UpdateFunctionCode event in CloudTrail
+	DeploymentSourceVersion      DeploymentSource = "version"       // The last modification of a published version
+	DeploymentSourceLastModified DeploymentSource = "last-modified" // The last modification of $LATEST, which can also be a configuration update
+)
+
+// Deployment is the start of a code revision of a function.
+type Deployment struct {
+	Time       time.Time        `json:"time"`
+	CodeSha256 string           `json:"codeSha256"`
+	Version    string           `json:"version,omitempty"` // First published version with this code, empty if it was never published
+	Source     DeploymentSource `json:"source"`
+	User       string           `json:"user,omitempty"` // Identity that deployed the code, only for CloudTrail events
+}
+
+// DeploymentTimelineReturn is the return of GetDeploymentTimeline.
+// Deployments are in ascending order and start with the one active at the start time, if it is known.
+type DeploymentTimelineReturn struct {
+	Deployments  []Deployment `json:"deployments"`
+	Warnings     []string     `json:"warnings"`
+	FunctionName string       `json:"functionName"`
+	StartTime    time.Time    `json:"startTime"`
+	EndTime      time.Time    `json:"endTime"`
+}
+
+// DeploymentSegment is the result of a metric for the part of a time range that ran one code revision.
+type DeploymentSegment[T any] struct {
+	Deployment    *Deployment `json:"deployment,omitempty"` // nil if the revision active at the start time is unknown
+	StartTime     time.Time   `json:"startTime"`
+	EndTime       time.Time   `json:"endTime"`
+	Result        T           `json:"result"`
+	NoInvocations bool        `json:"noInvocations"` // True if the segment has no invocations, Result is then the zero value
+}
+
+// DeploymentSplitReturn is the return of SplitByDeployments.
+type DeploymentSplitReturn[T any] struct {
+	Segments     []DeploymentSegment[T] `json:"segments"`
+	Warnings     []string               `json:"warnings"`
+	FunctionName string                 `json:"functionName"`
+	Qualifier    string                 `json:"qualifier"`
+	StartTime    time.Time              `json:"startTime"`
+	EndTime      time.Time              `json:"endTime"`
+}
+
+// AuditSeverity is the severity of an audit finding.
+type AuditSeverity string
+
+const (
+	AuditSeverityHigh   AuditSeverity = "high"   // Likely causes failures or security issues, e.g. a deprecated runtime
+	AuditSeverityMedium AuditSeverity = "medium" // Likely causes cost or reliability issues, e.g. over-provisioned memory
+	AuditSeverityLow    AuditSeverity = "low"    // Best practice that is not followed, e.g. tracing disabled
+)
+
+// AuditSubject is what audit rules are evaluated against.
+type AuditSubject struct {
+	Configuration       *BaseStatisticsReturn         // Snapshot of the configuration, environment variables are redacted
+	MemoryUsage         *MemoryUsagePercentilesReturn // Memory usage in the time range, nil if there were no invocations
+	AsyncEventsReceived float64                       // Events received for asynchronous invocation in the time range
+	Critical            bool                          // Whether the function was marked as critical in the AuditOptions
+	Now                 time.Time                     // Time of the audit, e.g. to compare deprecation dates with
+}
+
+// AuditRule is a check of a function. Check returns a message per violation of the rule,
+// none if the function passes it. Custom rules can be added to the rules of DefaultAuditRules.
+type AuditRule struct {
+	ID          string
+	Description string
+	Severity    AuditSeverity
+	Check       func(subject AuditSubject) []string
+}
+
+// AuditSettings overrides the data the default audit rules compare against. Zero values keep the defaults,
+// so the settings can be kept in a JSON file, e.g. to add a runtime deprecation announced after the release.
+type AuditSettings struct {
+	RuntimeDeprecations      map[string]string `json:"runtimeDeprecations,omitempty"`      // Deprecation dates as YYYY-MM-DD by runtime, replacing the built-in ones
+	DeprecationWarningDays   int               `json:"deprecationWarningDays,omitempty"`   // Days before the deprecation from which on a runtime is reported, 180 if zero
+	OverProvisionedUsageRate float64           `json:"overProvisionedUsageRate,omitempty"` // Max memory usage rate below which memory is over-provisioned, 0.2 if zero
+}
+
+// AuditOptions configures an audit.
+type AuditOptions struct {
+	Rules    []AuditRule   // Rules to evaluate, the default rules with the Settings if nil
+	Settings AuditSettings // Data of the default rules, only used if Rules is nil
+	Critical bool          // Marks the function as critical, e.g. such that it requires reserved concurrency
+}
+
+// AuditFinding is a violation of an audit rule.
+type AuditFinding struct {
+	RuleID   string        `json:"ruleId"`
+	Severity AuditSeverity `json:"severity"`
+	Message  string        `json:"message"`
+}
+
+// AuditReportReturn is the return of AuditFunction.
+// Findings are ordered by descending severity and then by the order of the rules.
+type AuditReportReturn struct {
+	Findings        []AuditFinding        `json:"findings"`
+	FindingCounts   map[AuditSeverity]int `json:"findingCounts"`
+	HighestSeverity AuditSeverity         `json:"highestSeverity,omitempty"` // Empty if there are no findings
+	PassedRules     []string              `json:"passedRules"`
+	FunctionName    string                `json:"functionName"`
+	Qualifier       string                `json:"qualifier"`
+	StartTime       time.Time             `json:"startTime"`
+	EndTime         time.Time             `json:"endTime"`
+}
+
+// CanaryVerdict is the outcome of a canary analysis.
+type CanaryVerdict string
+
+const (
+	CanaryVerdictProceed      CanaryVerdict = "PROCEED"      // All checks passed, traffic can be shifted to the canary
+	CanaryVerdictRollback     CanaryVerdict = "ROLLBACK"     // At least one check failed
+	CanaryVerdictInconclusive CanaryVerdict = "INCONCLUSIVE" // No check failed, but at least one lacked invocations to decide
+)
+
+// CanaryCheckStatus is the outcome of a single check of a canary analysis.
+type CanaryCheckStatus string
+
+const (
+	CanaryCheckPassed       CanaryCheckStatus = "pass"
+	CanaryCheckFailed       CanaryCheckStatus = "fail"
+	CanaryCheckInconclusive CanaryCheckStatus = "inconclusive"
+)
+
+// CanaryThresholds are the largest degradations of the canary compared to the baseline that still pass.
+// The zero value tolerates no degradation at all, DefaultCanaryThresholds returns common defaults.
+type CanaryThresholds struct {
+	MaxErrorRateIncrease   float64 // Largest absolute increase of the error rate, e.g. 0.01 for one percentage point
+	MaxP99DurationIncrease float64 // Largest relative increase of the p99 duration, e.g. 0.2 for 20%
+	MaxTimeoutRateIncrease float64 // Largest absolute increase of the timeout rate
+	MinInvocations         int     // Invocations both versions need for a check to be decided
+}
+
+// CanaryVersionStatistics holds the statistics of one version in a canary analysis.
+type CanaryVersionStatistics struct {
+	Version             string   `json:"version"`
+	Invocations         int      `json:"invocations"` // From the Invocations metric, through the alias if one is given
+	ErrorRate           float64  `json:"errorRate"`
+	ReportedInvocations int      `json:"reportedInvocations"`     // Invocations with a REPORT line in the logs, the sample of the p99 duration and timeout rate
+	P99DurationMs       *float64 `json:"p99DurationMs,omitempty"` // nil with fewer than 100 invocations
+	TimedOutInvocations int      `json:"timedOutInvocations"`
+	TimeoutRate         float64  `json:"timeoutRate"`
+}
+
+// CanaryCheck is the comparison of one statistic of the canary with the baseline.
+type CanaryCheck struct {
+	Metric     string            `json:"metric"` // errorRate, p99DurationMs or timeoutRate
+	Source     string            `json:"source"` // Metrics or log lines the values are calculated from, with the sample sizes
+	Baseline   float64           `json:"baseline"`
+	Canary     float64           `json:"canary"`
+	Difference float64           `json:"difference"` // Absolute for rates, relative for the p99 duration
+	Threshold  float64           `json:"threshold"`
+	Status     CanaryCheckStatus `json:"status"`
+	Reason     string            `json:"reason"`
+}
+
+// CanaryAnalysisReturn is the return of AnalyzeCanary.
+type CanaryAnalysisReturn struct {
+	Verdict      CanaryVerdict           `json:"verdict"`
+	Checks       []CanaryCheck           `json:"checks"`
+	Baseline     CanaryVersionStatistics `json:"baseline"`
+	Canary       CanaryVersionStatistics `json:"canary"`
+	CanaryWeight *float64                `json:"canaryWeight,omitempty"` // Share of the alias traffic routed to the canary, nil if not read from the alias
+	Warnings     []string                `json:"warnings"`
+	FunctionName string                  `json:"functionName"`
+	Alias        string                  `json:"alias,omitempty"`
+	StartTime    time.Time               `json:"startTime"`
+	EndTime      time.Time               `json:"endTime"`
+}
+
+// CodeDeployHookEvent is the event CodeDeploy invokes BeforeAllowTraffic and AfterAllowTraffic hooks with.
+type CodeDeployHookEvent struct {
+	DeploymentID                  string `json:"DeploymentId"`
+	LifecycleEventHookExecutionID string `json:"LifecycleEventHookExecutionId"`
+}
+
+// CanaryHookConfig configures a CodeDeploy hook that analyzes a canary.
+type CanaryHookConfig struct {
+	FunctionName          string
+	Alias                 string           // (Optional) Alias the traffic is shifted on, read from the deployment if empty
+	BaselineVersion       string           // (Optional) Version the traffic is shifted from, read from the deployment if empty
+	CanaryVersion         string           // (Optional) Version the traffic is shifted to, read from the deployment if empty
+	Window                time.Duration    // Length of the window before the hook that is analyzed
+	Thresholds            CanaryThresholds // Thresholds of the checks
+	ProceedOnInconclusive bool             // Report success instead of failure if the analysis is inconclusive
+}
+
+// SLOIndicator is the kind of invocations an SLO counts as good.
+type SLOIndicator string
+
+const (
+	SLOIndicatorSuccess   SLOIndicator = "success"   // Invocations without an error, from the Errors metric
+	SLOIndicatorNoTimeout SLOIndicator = "noTimeout" // Invocations that did not time out, from the REPORT lines
+	SLOIndicatorLatency   SLOIndicator = "latency"   // Invocations at most LatencyThresholdMs long, from the REPORT lines
+)
+
+// BurnRateAlert is a multi-window burn rate alert condition. It fires if the error budget burns faster than
+// the threshold over both the long and the short window, the short window making the alert reset quickly.
+type BurnRateAlert struct {
+	Name        string        `json:"name"`
+	LongWindow  time.Duration `json:"longWindow"`
+	ShortWindow time.Duration `json:"shortWindow"`
+	Threshold   float64       `json:"threshold"` // Burn rate above which the alert fires, 1 consumes the budget exactly within the SLO window
+}
+
+// SLOSpec declares a service level objective of a function.
+type SLOSpec struct {
+	Name               string          // Name to identify the SLO in the report
+	Indicator          SLOIndicator    // Kind of invocations counted as good
+	Objective          float64         // Share of good invocations, e.g. 0.999
+	LatencyThresholdMs float64         // Longest duration of a good invocation, only for the latency indicator
+	Window             time.Duration   // (Optional) Rolling compliance window, 30 days if zero
+	Alerts             []BurnRateAlert // (Optional) Burn rate alerts, DefaultBurnRateAlerts if nil
+}
+
+// BurnRateAlertStatus is the evaluation of a burn rate alert.
+type BurnRateAlertStatus struct {
+	BurnRateAlert
+	LongBurnRate  *float64 `json:"longBurnRate,omitempty"`  // nil without invocations in the long window
+	ShortBurnRate *float64 `json:"shortBurnRate,omitempty"` // nil without invocations in the short window
+	Firing        bool     `json:"firing"`
+}
+
+// SLOStatus is the evaluation of one SLO over its rolling compliance window.
+type SLOStatus struct {
+	Name                 string                `json:"name"`
+	Indicator            SLOIndicator          `json:"indicator"`
+	Objective            float64               `json:"objective"`
+	Window               time.Duration         `json:"window"`
+	GoodInvocations      float64               `json:"goodInvocations"`
+	TotalInvocations     float64               `json:"totalInvocations"`
+	Attainment           *float64              `json:"attainment,omitempty"` // nil without invocations in the window
+	Met                  bool                  `json:"met"`
+	ErrorBudget          float64               `json:"errorBudget"`          // Bad invocations the objective allows in the window
+	ErrorBudgetRemaining float64               `json:"errorBudgetRemaining"` // Share of the error budget left, negative if exceeded
+	Alerts               []BurnRateAlertStatus `json:"alerts"`
+}
+
+// SLOReportReturn is the return of EvaluateSLOs.
+type SLOReportReturn struct {
+	SLOs         []SLOStatus `json:"slos"`
+	FiringAlerts int         `json:"firingAlerts"`
+	FunctionName string      `json:"functionName"`
+	Qualifier    string      `json:"qualifier"`
+	StartTime    time.Time   `json:"startTime"` // Start of the longest compliance window
+	EndTime      time.Time   `json:"endTime"`
+}
+
+// AnomalyMethod is the method anomalies are detected with.
+type AnomalyMethod string
+
+const (
+	AnomalyMethodSeasonalMAD AnomalyMethod = "seasonal-mad" // Robust z-score against the median and MAD of the same time in previous seasons
+	AnomalyMethodEWMA        AnomalyMethod = "ewma"         // EWMA control chart with limits from a warmup period
+)
+
+// AnomalyDirection is the direction of deviations that are anomalous.
+type AnomalyDirection string
+
+const (
+	AnomalyDirectionUp   AnomalyDirection = "up"
+	AnomalyDirectionDown AnomalyDirection = "down"
+	AnomalyDirectionBoth AnomalyDirection = "both"
+)
+
+// AnomalySeverity is the severity of an anomaly relative to the threshold.
+type AnomalySeverity string
+
+const (
+	AnomalySeverityHigh   AnomalySeverity = "high"   // Score at least twice the threshold
+	AnomalySeverityMedium AnomalySeverity = "medium" // Score at least 1.5 times the threshold
+	AnomalySeverityLow    AnomalySeverity = "low"
+)
+
+// AnomalyOptions configures the anomaly detection. Zero values select the defaults.
+type AnomalyOptions struct {
+	Method       AnomalyMethod    // Detection method, seasonal-mad by default
+	Direction    AnomalyDirection // Deviations that are anomalous, up by default
+	Threshold    float64          // Score from which a point is anomalous, 3.5 for seasonal-mad and 3 for ewma by default
+	MinDeviation float64          // Smallest absolute deviation from the expected value that is anomalous, in the unit of the series
+	SeasonLength time.Duration    // Length of a season for seasonal-mad, one week by default
+	Seasons      int              // Previous seasons the baseline is built from for seasonal-mad, 4 by default
+	Smoothing    float64          // Weight of the newest point in the EWMA, between 0 and 1, 0.3 by default
+	WarmupPoints int              // Points the EWMA control limits are estimated from, 24 by default
+	BucketSize   time.Duration    // Width of the buckets the series are fetched in, one hour by default; only for GetAnomalies
+}
+
+// Anomaly is a run of consecutive anomalous points of a series.
+type Anomaly struct {
+	Metric    string           `json:"metric"`
+	Start     time.Time        `json:"start"`
+	End       time.Time        `json:"end"` // End of the last anomalous bucket
+	Severity  AnomalySeverity  `json:"severity"`
+	Direction AnomalyDirection `json:"direction"`
+	Points    int              `json:"points"`
+	Value     float64          `json:"value"`    // Value of the point with the highest score
+	Expected  float64          `json:"expected"` // Expected value at the point with the highest score
+	Score     float64          `json:"score"`    // Highest absolute score of the points
+}
+
+// AnomalyDetectionReturn is the return of DetectAnomalies.
+type AnomalyDetectionReturn struct {
+	Metric          string        `json:"metric"`
+	Method          AnomalyMethod `json:"method"`
+	Anomalies       []Anomaly     `json:"anomalies"`
+	EvaluatedPoints int           `json:"evaluatedPoints"`
+	SkippedPoints   int           `json:"skippedPoints"` // Points without enough history to be evaluated
+}
+
+// AnomalyReportReturn is the return of GetAnomalies.
+type AnomalyReportReturn struct {
+	Anomalies    []Anomaly                `json:"anomalies"` // Anomalies of all metrics, ordered by start
+	Detections   []AnomalyDetectionReturn `json:"detections"`
+	Series       map[string][]MetricPoint `json:"series"`
+	Warnings     []string                 `json:"warnings"`
+	FunctionName string                   `json:"functionName"`
+	Qualifier    string                   `json:"qualifier"`
+	StartTime    time.Time                `json:"startTime"`
+	EndTime      time.Time                `json:"endTime"`
+}
+
+// ChangePointOptions configures the change point detection. Zero values select the defaults.
+type ChangePointOptions struct {
+	Penalty           float64       // Multiplier of the BIC penalty per change point, higher values find fewer changes; 1 by default
+	MinSegmentPoints  int           // Fewest points between two change points, 3 by default
+	MinRelativeChange float64       // Smallest relative change of the mean that is reported, e.g. 0.1 for 10%
+	AttributionWindow time.Duration // Largest distance of a deployment to be attributed a change, two buckets by default
+	BucketSize        time.Duration // Width of the buckets the series are fetched in, one hour by default; only for GetChangePoints
+}
+
+// ChangePoint is a point in time from which on the mean of a series shifted.
+type ChangePoint struct {
+	Metric             string           `json:"metric"`
+	Time               time.Time        `json:"time"` // Start of the first bucket with the new mean
+	MeanBefore         float64          `json:"meanBefore"`
+	MeanAfter          float64          `json:"meanAfter"`
+	RelativeChange     *float64         `json:"relativeChange,omitempty"` // nil if the mean before is 0
+	Magnitude          float64          `json:"magnitude"`                // Change of the mean in standard deviations of the noise
+	Direction          AnomalyDirection `json:"direction"`
+	NearestDeployment  *Deployment      `json:"nearestDeployment,omitempty"`
+	DeploymentDistance time.Duration    `json:"deploymentDistance,omitempty"` // Time of the change minus the time of the nearest deployment
+	Attributed         bool             `json:"attributed"`                   // The nearest deployment is within the attribution window
+}
+
+// ChangePointDetectionReturn is the return of DetectChangePoints.
+type ChangePointDetectionReturn struct {
+	Metric       string        `json:"metric"`
+	ChangePoints []ChangePoint `json:"changePoints"`
+	NoiseStdDev  float64       `json:"noiseStdDev"` // Robust estimate of the standard deviation within segments
+}
+
+// ChangePointReportReturn is the return of GetChangePoints.
+type ChangePointReportReturn struct {
+	ChangePoints []ChangePoint                `json:"changePoints"` // Change points of all metrics, ordered by time
+	Detections   []ChangePointDetectionReturn `json:"detections"`
+	Deployments  []Deployment                 `json:"deployments"`
+	Series       map[string][]MetricPoint     `json:"series"`
+	Warnings     []string                     `json:"warnings"`
+	FunctionName string                       `json:"functionName"`
+	Qualifier    string                       `json:"qualifier"`
+	StartTime    time.Time                    `json:"startTime"`
+	EndTime      time.Time                    `json:"endTime"`
+}
+
+// ForecastModel is the model a series is forecast with.
+type ForecastModel string
+
+const (
+	ForecastModelLinear      ForecastModel = "linear"       // Least squares trend line
+	ForecastModelHoltWinters ForecastModel = "holt-winters" // Additive Holt-Winters with level, trend and seasonality
+)
+
+// ForecastOptions configures the forecast of a series. Zero values select the defaults.
+type ForecastOptions struct {
+	Model             ForecastModel // Forecast model, linear by default
+	Horizon           time.Duration // How far to forecast beyond the last point, 30 days by default
+	Confidence        float64       // Confidence of the prediction intervals, between 0 and 1, 0.95 by default
+	SeasonLength      time.Duration // Length of a season for holt-winters, one week by default
+	SmoothingLevel    float64       // Smoothing of the level for holt-winters, between 0 and 1, 0.3 by default
+	SmoothingTrend    float64       // Smoothing of the trend for holt-winters, between 0 and 1, 0.05 by default
+	SmoothingSeasonal float64       // Smoothing of the seasonality for holt-winters, between 0 and 1, 0.1 by default
+}
+
+// ForecastPoint is a forecast value of a series with its prediction interval.
+type ForecastPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+	Lower     float64   `json:"lower"`
+	Upper     float64   `json:"upper"`
+}
+
+// SeriesForecast is the return of ForecastSeries.
+type SeriesForecast struct {
+	Metric         string          `json:"metric"`
+	Model          ForecastModel   `json:"model"` // Model used, linear if holt-winters has less than two seasons of history
+	Forecast       []ForecastPoint `json:"forecast"`
+	Trend          float64         `json:"trend"`          // Growth per point at the end of the history, per day for ForecastCapacity
+	ResidualStdDev float64         `json:"residualStdDev"` // Standard deviation of the one step ahead errors on the history
+}
+
+// ConcurrencyLimitForecast is when a forecast peak concurrency reaches a concurrency limit.
+type ConcurrencyLimitForecast struct {
+	Limit           string     `json:"limit"` // reserved, unreserved or account
+	Concurrency     int32      `json:"concurrency"`
+	PeakConcurrency float64    `json:"peakConcurrency"`      // Highest daily peak concurrency of the history
+	ExpectedAt      *time.Time `json:"expectedAt,omitempty"` // First day the forecast reaches the limit, nil if not within the horizon
+	EarliestAt      *time.Time `json:"earliestAt,omitempty"` // First day the upper bound of the forecast reaches the limit
+	Utilization     float64    `json:"utilization"`          // PeakConcurrency relative to the limit
+}
+
+// CostForecast is the forecast cost of a function in a calendar month.
+type CostForecast struct {
+	Month        time.Time `json:"month"` // First day of the month
+	Invocations  float64   `json:"invocations"`
+	GBSeconds    float64   `json:"gbSeconds"`
+	Cost         float64   `json:"cost"` // USD at list prices
+	CostLower    float64   `json:"costLower"`
+	CostUpper    float64   `json:"costUpper"`
+	Architecture string    `json:"architecture"`
+	MemorySizeMB float64   `json:"memorySizeMb"`
+}
+
+// CapacityForecastReturn is the return of ForecastCapacity.
+type CapacityForecastReturn struct {
+	Forecasts     []SeriesForecast           `json:"forecasts"`
+	Limits        []ConcurrencyLimitForecast `json:"limits"`
+	NextMonthCost CostForecast               `json:"nextMonthCost"`
+	Series        map[string][]MetricPoint   `json:"series"` // Daily history of the forecast metrics
+	Warnings      []string                   `json:"warnings"`
+	FunctionName  string                     `json:"functionName"`
+	Qualifier     string                     `json:"qualifier"`
+	StartTime     time.Time                  `json:"startTime"`
+	EndTime       time.Time                  `json:"endTime"` // End of the last full day of the history
+}
+
+// Offsets of common period-over-period comparisons.
+const (
+	ComparePreviousDay  = 24 * time.Hour
+	ComparePreviousWeek = 7 * 24 * time.Hour
+)
+
+// ComparisonMetric is a metric that can be compared between two periods.
+type ComparisonMetric string
+
+const (
+	ComparisonMetricInvocations         ComparisonMetric = "invocations"
+	ComparisonMetricErrorRate           ComparisonMetric = "errorRate"
+	ComparisonMetricThrottleRate        ComparisonMetric = "throttleRate"
+	ComparisonMetricTimeoutRate         ComparisonMetric = "timeoutRate"
+	ComparisonMetricColdStartRate       ComparisonMetric = "coldStartRate"
+	ComparisonMetricMeanDuration        ComparisonMetric = "meanDurationMs"
+	ComparisonMetricMedianDuration      ComparisonMetric = "medianDurationMs"
+	ComparisonMetricP95Duration         ComparisonMetric = "p95DurationMs"
+	ComparisonMetricP99Duration         ComparisonMetric = "p99DurationMs"
+	ComparisonMetricMeanMemoryUsageRate ComparisonMetric = "meanMemoryUsageRate"
+	ComparisonMetricP95MemoryUsageRate  ComparisonMetric = "p95MemoryUsageRate"
+)
+
+// PeriodComparisonOptions configures a period-over-period comparison. Zero values select the defaults.
+type PeriodComparisonOptions struct {
+	Offset            time.Duration      // How far the previous period is shifted back, ComparePreviousWeek by default
+	Metrics           []ComparisonMetric // Metrics to compare, all by default
+	SignificanceLevel float64            // Largest p-value of a significant change, between 0 and 1, 0.05 by default
+	MinRelativeChange float64            // Smallest relative change that is significant, e.g. 0.1 for 10%, 0.1 by default
+}
+
+// PeriodValue is the value of a metric in one period.
+type PeriodValue struct {
+	Value      float64  `json:"value"`
+	SampleSize float64  `json:"sampleSize"`         // Invocations the value is calculated from
+	StdError   *float64 `json:"stdError,omitempty"` // Standard error of the value, nil if unknown, e.g. for percentiles
+}
+
+// MetricComparison is the change of a metric between the previous and the current period.
+type MetricComparison struct {
+	Metric        ComparisonMetric `json:"metric"`
+	Current       PeriodValue      `json:"current"`
+	Previous      PeriodValue      `json:"previous"`
+	AbsoluteDelta float64          `json:"absoluteDelta"`           // Current minus previous value
+	RelativeDelta *float64         `json:"relativeDelta,omitempty"` // nil if the previous value is 0
+	PValue        *float64         `json:"pValue,omitempty"`        // Two-sided z-test, nil if the standard error of a period is unknown
+	Significant   bool             `json:"significant"`
+}
+
+// PeriodComparisonReturn is the return of ComparePeriods.
+type PeriodComparisonReturn struct {
+	Comparisons       []MetricComparison `json:"comparisons"`
+	Warnings          []string           `json:"warnings"`
+	Offset            time.Duration      `json:"offset"`
+	FunctionName      string             `json:"functionName"`
+	Qualifier         string             `json:"qualifier"`
+	StartTime         time.Time          `json:"startTime"`
+	EndTime           time.Time          `json:"endTime"`
+	PreviousStartTime time.Time          `json:"previousStartTime"`
+	PreviousEndTime   time.Time          `json:"previousEndTime"`
 }
 
 // ErrorRateReturn is the return of GetErrorRate.
@@ -112,6 +1149,34 @@ type ErrorRateReturn struct {
 	ErrorRate    float64   `json:"errorRate"`
 }
 
+// ErrorClassStatistics holds the number of invocations of one error class and their
+// share of all invocations.
+type ErrorClassStatistics struct {
+	Count int     `json:"count"`
+	Rate  float64 `json:"rate"`
+}
+
+// ErrorClassificationReturn is the return of GetErrorClassification.
+// Every invocation is assigned to at most one class derived from logs, the
+// most severe one. FunctionErrors is taken from the CloudWatch Errors metric and
+// therefore overlaps with the other classes.
+type ErrorClassificationReturn struct {
+	Invocations       int                  `json:"invocations"`
+	FunctionErrors    ErrorClassStatistics `json:"functionErrors"`    // All invocations the Lambda service counted as failed
+	HandledErrors     ErrorClassStatistics `json:"handledErrors"`     // Function errors without a crash, timeout or unhandled exception in the logs
+	UnhandledErrors   ErrorClassStatistics `json:"unhandledErrors"`   // Exceptions that escaped the handler
+	RuntimeExitErrors ErrorClassStatistics `json:"runtimeExitErrors"` // Runtime.ExitError crashes of the runtime process
+	OutOfMemoryErrors ErrorClassStatistics `json:"outOfMemoryErrors"` // Runtime.OutOfMemory
+	InitFailures      ErrorClassStatistics `json:"initFailures"`      // INIT_REPORT or platform.initReport with status error or timeout, rate is per invocation
+	SandboxTimeouts   ErrorClassStatistics `json:"sandboxTimeouts"`   // Invocations that exceeded the configured timeout
+	LoggedOnlyErrors  ErrorClassStatistics `json:"loggedOnlyErrors"`  // Invocations that logged an error but succeeded
+	Warnings          []string             `json:"warnings"`
+	FunctionName      string               `json:"functionName"`
+	Qualifier         string               `json:"qualifier"`
+	StartTime         time.Time            `json:"startTime"`
+	EndTime           time.Time            `json:"endTime"`
+}
+
 // ErrorType represents a categorized error encountered by an AWS Lambda function.
 type ErrorType struct {
 	ErrorCategory string `json:"errorCategory"` // ErrorCategory is a semantic extraction what follows after [ERROR] in a log.
@@ -128,6 +1193,37 @@ type ErrorTypesReturn struct {
 	EndTime      time.Time   `json:"endTime"`
 }
 
+// ErrorCategoryChange describes how a single error category changed between a baseline
+// and a target query. Rates are normalized by the invocations of the respective query.
+type ErrorCategoryChange struct {
+	ErrorCategory      string   `json:"errorCategory"`
+	BaselineCount      int      `json:"baselineCount"`
+	TargetCount        int      `json:"targetCount"`
+	BaselineRate       float64  `json:"baselineRate"`                 // Errors of this category per baseline invocation
+	TargetRate         float64  `json:"targetRate"`                   // Errors of this category per target invocation
+	RateChange         float64  `json:"rateChange"`                   // TargetRate - BaselineRate
+	RelativeRateChange *float64 `json:"relativeRateChange,omitempty"` // RateChange / BaselineRate, nil if the category is new
+}
+
+// ErrorCategoryDiffReturn is the return of CompareErrorCategories.
+// Categories whose rate did not change are not part of any slice.
+type ErrorCategoryDiffReturn struct {
+	New                 []ErrorCategoryChange `json:"new"`          // Categories only present in the target
+	Resolved            []ErrorCategoryChange `json:"resolved"`     // Categories only present in the baseline
+	Increased           []ErrorCategoryChange `json:"increased"`    // Categories present in both with a higher target rate
+	Decreased           []ErrorCategoryChange `json:"decreased"`    // Categories present in both with a lower target rate
+	HasNewErrors        bool                  `json:"hasNewErrors"` // True if at least one category is new, e.g. to fail a post-deploy gate
+	FunctionName        string                `json:"functionName"`
+	BaselineQualifier   string                `json:"baselineQualifier"`
+	BaselineStartTime   time.Time             `json:"baselineStartTime"`
+	BaselineEndTime     time.Time             `json:"baselineEndTime"`
+	BaselineInvocations int                   `json:"baselineInvocations"`
+	TargetQualifier     string                `json:"targetQualifier"`
+	TargetStartTime     time.Time             `json:"targetStartTime"`
+	TargetEndTime       time.Time             `json:"targetEndTime"`
+	TargetInvocations   int                   `json:"targetInvocations"`
+}
+
 // DurationStatisticsReturn holds various statistics on the duration of invocations.
 // P95Duration, P99Duration and Conf95Duration can be nil if not enough values are present in
 // the specified inteval, to calculate them robustly.
@@ -145,6 +1241,23 @@ type DurationStatisticsReturn struct {
 	EndTime        time.Time `json:"endTime"`
 }
 
+// EndToEndLatencyReturn is the return of GetEndToEndLatencyStatistics.
+// The latency of an invocation is its duration plus the init or restore duration on cold starts,
+// all values are in milliseconds. Statistics are nil if there are no invocations of the kind.
+type EndToEndLatencyReturn struct {
+	All                       *SummaryStatistics `json:"all,omitempty"`
+	Warm                      *SummaryStatistics `json:"warm,omitempty"`
+	Cold                      *SummaryStatistics `json:"cold,omitempty"`
+	ColdStartShareOfP99Tail   *float64           `json:"coldStartShareOfP99Tail,omitempty"`   // Share of invocations at or above the p99 latency that were cold starts
+	InitTimeShareOfP99Tail    *float64           `json:"initTimeShareOfP99Tail,omitempty"`    // Share of the latency of these invocations spent in init or restore
+	P99IncreaseFromColdStarts *float64           `json:"p99IncreaseFromColdStarts,omitempty"` // p99 of all invocations minus the p99 of their durations without init
+	Warnings                  []string           `json:"warnings"`
+	FunctionName              string             `json:"functionName"`
+	Qualifier                 string             `json:"qualifier"`
+	StartTime                 time.Time          `json:"startTime"`
+	EndTime                   time.Time          `json:"endTime"`
+}
+
 // ColdStartDurationStatisticsReturn holds various statistics on the coldstart duration of invocations.
 // P95ColdStartDuration, P99ColdStartDuration and Conf95ColdStartDuration can be nil
 // if not enough values are present in the specified inteval, to calculate them robustly.